@@ -0,0 +1,22 @@
+package events
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+)
+
+type RefundStatusChangedEvent struct {
+	Timestamp     time.Time      `json:"timestamp"`
+	RefundID      string         `json:"refund_id"`
+	TransactionID string         `json:"transaction_id"`
+	Type          string         `json:"type"`
+	Amount        entities.Money `json:"amount"`
+	Currency      string         `json:"currency"`
+	Status        string         `json:"status"`
+	Message       string         `json:"message"`
+}
+
+func (e RefundStatusChangedEvent) GetEventName() string {
+	return RefundStatusChangedEventName
+}
@@ -1,5 +1,6 @@
 package events
 
 const (
-	PaymentCreatedEventName = "payment.created"
+	PaymentCreatedEventName      = "payment.created"
+	RefundStatusChangedEventName = "payment.refund.status_changed"
 )
@@ -0,0 +1,15 @@
+package messages
+
+import "time"
+
+type RefundStatusChangedMessage struct {
+	Timestamp     time.Time `json:"timestamp"`
+	RefundID      string    `json:"refund_id"`
+	TransactionID string    `json:"transaction_id"`
+	Status        string    `json:"status"`
+	Message       string    `json:"message"`
+}
+
+func (m RefundStatusChangedMessage) GetMessageName() string {
+	return RefundStatusChangedMessageName
+}
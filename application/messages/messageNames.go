@@ -1,5 +1,6 @@
 package messages
 
 const (
-	PaymentCreatedMessageName = "payment.created"
+	PaymentCreatedMessageName      = "payment.created"
+	RefundStatusChangedMessageName = "payment.refund.status_changed"
 )
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/domain/entities"
+)
+
+type AcledaCredentialRepository interface {
+	GetByMerchantCode(ctx context.Context, merchantCode string) (*entities.AcledaCredential, error)
+	Save(ctx context.Context, credential entities.AcledaCredential, actor entities.AuditActor) error
+}
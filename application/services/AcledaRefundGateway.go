@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/infrastructure/gateway/acleda"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type AcledaRefundGateway interface {
+	Refund(ctx context.Context, client *resty.Client, url string, param acleda.RefundRequestDto) (acleda.RefundResponseDTO, error)
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/infrastructure/gateway/acleda"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type AcledaSessionGateway interface {
+	OpenSessionV2(ctx context.Context, client *resty.Client, url string, param acleda.OpenSessionV2RequestDto) (acleda.OpenSessionV2ResponseDTO, error)
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/domain/entities"
+)
+
+type AcledaSessionRepository interface {
+	// RecordOpening stores session as OPENING and returns the ID of the
+	// record, which the other methods take.
+	RecordOpening(ctx context.Context, session entities.AcledaSession) (string, error)
+	// Attach stores link and marks its session ATTACHED in one transaction.
+	Attach(ctx context.Context, id string, link entities.PaymentAcledaPaymentLink) error
+	// MarkOpened keeps link in the outbox so that it can be attached later.
+	MarkOpened(ctx context.Context, id string, link entities.PaymentAcledaPaymentLink) error
+	// AttachReopened makes next the current session of link and marks it
+	// ATTACHED in one transaction. If link has changed since it was read,
+	// next is kept as a prior session and a PaymentLinkConflictError is
+	// returned.
+	AttachReopened(ctx context.Context, id string, link entities.PaymentAcledaPaymentLink, next entities.PaymentLinkSession) (*entities.PaymentAcledaPaymentLink, error)
+	// MarkReopened keeps next in the outbox so that it can be recorded
+	// against its link later.
+	MarkReopened(ctx context.Context, id string, next entities.PaymentLinkSession) error
+	MarkFailed(ctx context.Context, id, reason string) error
+	MarkOrphaned(ctx context.Context, id, reason string) error
+	// AttachStored attaches a session to its existing link or to the link kept
+	// by MarkOpened, or records the session kept by MarkReopened. It reports
+	// false when there is nothing to attach.
+	AttachStored(ctx context.Context, id string) (bool, error)
+	ListUnattached(ctx context.Context, createdBefore time.Time, limit int) ([]entities.AcledaSession, error)
+}
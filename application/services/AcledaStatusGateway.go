@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/infrastructure/gateway/acleda"
+)
+
+type AcledaStatusGateway interface {
+	GetPaymentStatus(ctx context.Context, req acleda.PaymentStatusRequest) (*acleda.PaymentStatusResponse, error)
+}
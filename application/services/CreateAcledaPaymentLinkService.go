@@ -3,25 +3,37 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"strconv"
+	"math"
+	"net/url"
 	"time"
 
 	"payment-airpay/domain/entities"
 	"payment-airpay/infrastructure/configuration"
-	"payment-airpay/infrastructure/database/repositories"
 	"payment-airpay/infrastructure/gateway/acleda"
-	"payment-airpay/infrastructure/service"
+	"payment-airpay/infrastructure/logger"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+// sessionRefreshMargin re-opens an Acleda session this long before the bank
+// expires it, so the customer is never sent to a session that lapses while
+// they fill in the bank's form.
+const sessionRefreshMargin = 2 * time.Minute
+
 type CreateAcledaPaymentLinkService struct {
-	gateway *acleda.AcledaGateway
-	service *service.PaymentAcleda
-	repo    *repositories.PaymentAcledaRepositoryYugabyteDB
-	Client  *resty.Client
+	gateway    AcledaSessionGateway
+	repo       PaymentLinkRepository
+	sessions   AcledaSessionRepository
+	currencies CurrencyRepository
+	accounts   AcledaCredentialRepository
+	signer     PaymentPageSigner
+	cfg        *configuration.Config
+	Client     *resty.Client
+	log        *zap.Logger
 }
 
 type CreateAcledaPaymentLinkInput struct {
@@ -48,17 +60,39 @@ type CreateAcledaPaymentLinkOutput struct {
 	CreatedAt      string `json:"created_at"`
 }
 
+// AcledaPaymentStatusOutput is what a merchant polls for: the state of a
+// payment link without its session or bank payloads.
+type AcledaPaymentStatusOutput struct {
+	TransactionID string `json:"transaction_id"`
+	InvoiceID     string `json:"invoice_id"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	ExpiresAt     string `json:"expires_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
 func NewCreateAcledaPaymentLinkService(
-	gateway *acleda.AcledaGateway,
-	service *service.PaymentAcleda,
-	repo *repositories.PaymentAcledaRepositoryYugabyteDB,
+	gateway AcledaSessionGateway,
+	repo PaymentLinkRepository,
+	sessions AcledaSessionRepository,
+	currencies CurrencyRepository,
+	accounts AcledaCredentialRepository,
+	signer PaymentPageSigner,
 	client *resty.Client,
+	cfg *configuration.Config,
+	log *zap.Logger,
 ) *CreateAcledaPaymentLinkService {
 	return &CreateAcledaPaymentLinkService{
-		gateway: gateway,
-		service: service,
-		repo:    repo,
-		Client:  client,
+		gateway:    gateway,
+		repo:       repo,
+		sessions:   sessions,
+		currencies: currencies,
+		accounts:   accounts,
+		signer:     signer,
+		cfg:        cfg,
+		Client:     client,
+		log:        logger.Component(log, "payment-link"),
 	}
 }
 
@@ -72,6 +106,12 @@ func (s *CreateAcledaPaymentLinkService) Execute(ctx context.Context, in CreateA
 		return nil, fmt.Errorf("currency is required")
 	}
 
+	amount, err := validatePaymentAmount(ctx, s.currencies, incoming.Merchant, in.Amount, in.Currency)
+	if err != nil {
+		return nil, err
+	}
+	currency := amount.Currency
+
 	if in.ReturnURL == "" {
 		return nil, fmt.Errorf("return url is required")
 
@@ -82,19 +122,37 @@ func (s *CreateAcledaPaymentLinkService) Execute(ctx context.Context, in CreateA
 
 	}
 
-	// Generate transaction ID
-	transactionID := fmt.Sprintf("ACL-%d", time.Now().Unix())
+	// Open the session under the merchant's own Acleda account
+	account, err := resolveAcledaCredentials(ctx, s.accounts, s.cfg, incoming.Merchant)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionID := newTransactionID()
+	logger.SetTransactionID(ctx, transactionID)
+	log := logger.For(ctx, s.log)
+
+	// Record the session before the bank can open it, so that it is never
+	// lost if the payment link cannot be stored afterwards.
+	outboxID, err := s.sessions.RecordOpening(ctx, entities.AcledaSession{
+		TransactionID:    transactionID,
+		MerchantID:       incoming.Merchant,
+		AcledaMerchantID: account.AcledaMerchantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session: %w", err)
+	}
 
 	// Step 1: Open Session with Acleda
-	sessionResp, err := s.gateway.OpenSessionV2(ctx, s.Client, configuration.AppConfig.ACLEDAOPENSESSIONV2URL, acleda.OpenSessionV2RequestDto{
-		LoginID:    configuration.AppConfig.AcledaLogin,
-		Password:   configuration.AppConfig.AcledaRemotePassword,
-		MerchantID: configuration.AppConfig.AcledaMerchantID,
-		Signature:  configuration.AppConfig.AcledaSecret,
+	sessionResp, err := s.gateway.OpenSessionV2(ctx, s.Client, s.cfg.ACLEDAOPENSESSIONV2URL, acleda.OpenSessionV2RequestDto{
+		LoginID:    account.LoginID,
+		Password:   account.Password,
+		MerchantID: account.AcledaMerchantID,
+		Signature:  account.Secret,
 		XPayTransaction: acleda.XPayTransactionDTO{
 			TxID:             transactionID,
-			PurchaseAmount:   in.Amount,
-			PurchaseCurrency: in.Currency,
+			PurchaseAmount:   amount.String(),
+			PurchaseCurrency: currency,
 			PurchaseDate:     time.Now().Format(time.DateOnly),
 			PurchaseDesc:     in.Description,
 			InvoiceID:        transactionID,
@@ -104,58 +162,82 @@ func (s *CreateAcledaPaymentLinkService) Execute(ctx context.Context, in CreateA
 		},
 	})
 
-	go SaveAPICall(context.Background(), &sessionResp, incoming.Merchant, err, "acleda", incoming.Path, in.CustomerPhone, incoming.Webtype, incoming.TransactionID)
+	SaveAPICallAsync(ctx, &sessionResp, incoming.Merchant, err, "acleda", incoming.Path, in.CustomerPhone, incoming.Webtype, incoming.TransactionID)
 
+	if errors.Is(err, acleda.ErrRejected) {
+		if err := s.sessions.MarkFailed(ctx, outboxID, sessionResp.Result.ErrorDetails); err != nil {
+			log.Warn("Failed to record refused session", zap.Error(err))
+		}
+		return nil, fmt.Errorf("session failed: %w", err)
+	}
+	// Any other error leaves the session OPENING: the bank may still have
+	// opened it, and the session reconciler flags it.
 	if err != nil {
 		return nil, fmt.Errorf("failed to open session: %w", err)
 	}
 
-	if sessionResp.Result.ErrorDetails != "SUCCESS" {
-		return nil, fmt.Errorf("session failed: %s", sessionResp.Result.ErrorDetails)
+	purchaseAmount, err := entities.MoneyFromDecimal(sessionResp.Result.XTran.PurchaseAmount.String(), currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid purchase amount from Acleda: %w", err)
+	}
+	feeAmount, err := entities.MoneyFromDecimal(sessionResp.Result.XTran.FeeAmount.String(), currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee amount from Acleda: %w", err)
 	}
 
 	paymentLinkEntity := entities.PaymentAcledaPaymentLink{
-		ID:             transactionID,
-		TransactionID:  transactionID,
-		SessionID:      sessionResp.Result.SessionID,
-		PaymentTokenID: sessionResp.Result.XTran.PaymentTokenID,
-		Description:    in.Description,
-		Amount:         parseFloat(in.Amount),
-		Currency:       in.Currency,
-		InvoiceID:      transactionID,
-		Status:         "PENDING",
-		ExpiryTime:     in.ExpiredTime,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-		PurchaseAmount: sessionResp.Result.XTran.PurchaseAmount,
-		PurchaseDate:   sessionResp.Result.XTran.PurchaseDate,
-		Quantity:       sessionResp.Result.XTran.Quantity,
-		ConfirmDate:    sessionResp.Result.XTran.ConfirmDate,
-		PurchaseType:   sessionResp.Result.XTran.PurchaseType,
-		SaveToken:      sessionResp.Result.XTran.SaveToken,
-		FeeAmount:      sessionResp.Result.XTran.FeeAmount,
-		TxDirection:    sessionResp.Result.TxDirection,
-		ReturnURL:      in.ReturnURL,
-		ErrorURL:       in.CallbackURL,
-		RequestJSON:    toJSON(sessionResp),
-		ResponseJSON:   toJSON(sessionResp),
-	}
-
-	err = s.repo.Create(ctx, paymentLinkEntity)
+		ID:               transactionID,
+		TransactionID:    transactionID,
+		MerchantID:       incoming.Merchant,
+		SessionID:        sessionResp.Result.SessionID,
+		PaymentTokenID:   sessionResp.Result.XTran.PaymentTokenID,
+		Description:      in.Description,
+		Amount:           amount,
+		Currency:         currency,
+		InvoiceID:        transactionID,
+		Status:           entities.PaymentLinkStatusPending,
+		ExpiryTime:       in.ExpiredTime,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+		PurchaseAmount:   purchaseAmount,
+		PurchaseDate:     sessionResp.Result.XTran.PurchaseDate,
+		Quantity:         sessionResp.Result.XTran.Quantity,
+		ConfirmDate:      sessionResp.Result.XTran.ConfirmDate,
+		PurchaseType:     sessionResp.Result.XTran.PurchaseType,
+		SaveToken:        sessionResp.Result.XTran.SaveToken,
+		FeeAmount:        feeAmount,
+		TxDirection:      sessionResp.Result.TxDirection,
+		ReturnURL:        in.ReturnURL,
+		ErrorURL:         in.CallbackURL,
+		AcledaMerchantID: account.AcledaMerchantID,
+		RequestJSON:      toJSON(sessionResp),
+		ResponseJSON:     toJSON(sessionResp),
+	}
+	paymentLinkEntity.SessionOpenedAt = paymentLinkEntity.CreatedAt
+	paymentLinkEntity.SessionExpiryTime = sessionExpiryTime(sessionResp.Result.XTran.ExpiryTime, in.ExpiredTime)
+
+	err = s.sessions.Attach(ctx, outboxID, paymentLinkEntity)
 	if err != nil {
-		log.Printf("Failed to save payment link to database: %v", err)
+		log.Error("Failed to save payment link", zap.Error(err))
+		// Keep the link in the outbox for the session reconciler to store.
+		if err := s.sessions.MarkOpened(ctx, outboxID, paymentLinkEntity); err != nil {
+			log.Error("Failed to record opened session", zap.Error(err))
+		}
 		return nil, fmt.Errorf("failed to save payment link: %w", err)
 	}
 
 	// Step 3: Save to payments table (using existing logic)
 	err = s.saveToPaymentsTable(ctx, in, transactionID)
 	if err != nil {
-		log.Printf("Failed to save to payments table: %v", err)
+		log.Warn("Failed to save to payments table", zap.Error(err))
 		// Continue even if payments table save fails
 	}
 
-	// Step 4: Generate payment URL
-	paymentURL := fmt.Sprintf("%s/payment-page/acleda/%s?sid=%s&ptid=%s", configuration.AppConfig.AcledaBaseURL, transactionID, sessionResp.Result.SessionID, sessionResp.Result.XTran.PaymentTokenID)
+	// Step 4: Generate payment URL. The token is the only thing in the URL
+	// besides the transaction; the page reads the session from the link.
+	expiresAt := paymentLinkEntity.ExpiresAt()
+	token := s.signer.Sign(transactionID, expiresAt)
+	paymentURL := fmt.Sprintf("%s/payment-page/acleda/%s?token=%s", s.cfg.AcledaBaseURL, url.PathEscape(transactionID), url.QueryEscape(token))
 
 	// Step 5: Return response
 	out := &CreateAcledaPaymentLinkOutput{
@@ -163,14 +245,14 @@ func (s *CreateAcledaPaymentLinkService) Execute(ctx context.Context, in CreateA
 		PaymentURL:     paymentURL,
 		SessionID:      sessionResp.Result.SessionID,
 		PaymentTokenID: sessionResp.Result.XTran.PaymentTokenID,
-		Amount:         in.Amount,
-		Currency:       in.Currency,
-		Status:         "PENDING",
-		ExpiresAt:      time.Now().Add(60 * time.Minute).Format(time.RFC3339),
-		CreatedAt:      time.Now().Format(time.RFC3339),
+		Amount:         amount.String(),
+		Currency:       currency,
+		Status:         entities.PaymentLinkStatusPending,
+		ExpiresAt:      expiresAt.Format(time.RFC3339),
+		CreatedAt:      paymentLinkEntity.CreatedAt.Format(time.RFC3339),
 	}
 
-	log.Printf("Successfully created Acleda payment link: %s", transactionID)
+	log.Info("Acleda payment link created", zap.Stringer("amount", amount))
 	return out, nil
 }
 
@@ -178,21 +260,138 @@ func (s *CreateAcledaPaymentLinkService) GetByTransactionID(ctx context.Context,
 	return s.repo.GetByTransactionID(ctx, transactionID)
 }
 
-func (s *CreateAcledaPaymentLinkService) saveToPaymentsTable(ctx context.Context, in CreateAcledaPaymentLinkInput, transactionID string) error {
-	// This would use the existing payment repository to save to payments table
-	// For now, return nil as placeholder
-	log.Printf("Saving to payments table: %s", transactionID)
-	return nil
+// GetStatus returns the status of a merchant's payment link. Links of other
+// merchants are reported as ErrPaymentLinkNotFound.
+func (s *CreateAcledaPaymentLinkService) GetStatus(ctx context.Context, merchantID, transactionID string) (*AcledaPaymentStatusOutput, error) {
+	link, err := s.repo.GetByTransactionID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil || link.MerchantID != merchantID {
+		return nil, ErrPaymentLinkNotFound
+	}
+
+	return &AcledaPaymentStatusOutput{
+		TransactionID: link.TransactionID,
+		InvoiceID:     link.InvoiceID,
+		Amount:        link.Amount.String(),
+		Currency:      link.Currency,
+		Status:        link.Status,
+		ExpiresAt:     link.ExpiresAt().Format(time.RFC3339),
+		UpdatedAt:     link.UpdatedAt.Format(time.RFC3339),
+	}, nil
 }
 
-func parseFloat(s string) float64 {
-	if s == "" {
-		return 0
+// RefreshSession re-opens the Acleda session of a pending link whose session
+// has expired, or is about to, while the link itself is still payable. The
+// new session is for the same invoice and lasts until the link expires; the
+// old one is kept for reconciliation. A link that needs no new session is
+// returned as is.
+func (s *CreateAcledaPaymentLinkService) RefreshSession(ctx context.Context, link *entities.PaymentAcledaPaymentLink, incoming entities.Incoming) (*entities.PaymentAcledaPaymentLink, error) {
+	now := time.Now()
+	linkExpiresAt := link.ExpiresAt()
+	if link.Status != entities.PaymentLinkStatusPending || !now.Before(linkExpiresAt) {
+		return link, nil
 	}
-	if f, err := strconv.ParseFloat(s, 64); err == nil {
-		return f
+	if now.Add(sessionRefreshMargin).Before(link.SessionExpiresAt()) {
+		return link, nil
+	}
+
+	log := logger.For(ctx, s.log).With(zap.String("previous_session_id", link.SessionID))
+
+	account, err := resolveAcledaCredentials(ctx, s.accounts, s.cfg, link.MerchantID)
+	if err != nil {
+		log.Error("Failed to resolve Acleda credentials", zap.Error(err))
+		return nil, err
 	}
-	return 0
+
+	// Record the new session before the bank can open it, like Execute.
+	outboxID, err := s.sessions.RecordOpening(ctx, entities.AcledaSession{
+		TransactionID:     link.TransactionID,
+		ReplacesSessionID: link.SessionID,
+		MerchantID:        link.MerchantID,
+		AcledaMerchantID:  account.AcledaMerchantID,
+	})
+	if err != nil {
+		log.Error("Failed to record Acleda session", zap.Error(err))
+		return nil, fmt.Errorf("failed to record session: %w", err)
+	}
+
+	minutes := int(math.Ceil(linkExpiresAt.Sub(now).Minutes()))
+	sessionResp, err := s.gateway.OpenSessionV2(ctx, s.Client, s.cfg.ACLEDAOPENSESSIONV2URL, acleda.OpenSessionV2RequestDto{
+		LoginID:    account.LoginID,
+		Password:   account.Password,
+		MerchantID: account.AcledaMerchantID,
+		Signature:  account.Secret,
+		XPayTransaction: acleda.XPayTransactionDTO{
+			TxID:             link.TransactionID,
+			PurchaseAmount:   link.Amount.String(),
+			PurchaseCurrency: link.Currency,
+			PurchaseDate:     now.Format(time.DateOnly),
+			PurchaseDesc:     link.Description,
+			InvoiceID:        link.InvoiceID,
+			Item:             "1",
+			Quantity:         "1",
+			ExpiryTime:       minutes,
+		},
+	})
+
+	SaveAPICallAsync(ctx, &sessionResp, link.MerchantID, err, "acleda", incoming.Path, "", incoming.Webtype, link.TransactionID)
+
+	if errors.Is(err, acleda.ErrRejected) {
+		log.Error("Acleda refused to re-open session", zap.String("error_details", sessionResp.Result.ErrorDetails))
+		if err := s.sessions.MarkFailed(ctx, outboxID, sessionResp.Result.ErrorDetails); err != nil {
+			log.Warn("Failed to record refused session", zap.Error(err))
+		}
+		return nil, fmt.Errorf("session refused: %w", err)
+	}
+	if err != nil {
+		log.Error("Failed to re-open Acleda session", zap.Error(err))
+		return nil, fmt.Errorf("failed to re-open session: %w", err)
+	}
+
+	next := entities.PaymentLinkSession{
+		TransactionID:    link.TransactionID,
+		SessionID:        sessionResp.Result.SessionID,
+		PaymentTokenID:   sessionResp.Result.XTran.PaymentTokenID,
+		AcledaMerchantID: account.AcledaMerchantID,
+		ExpiryTime:       sessionExpiryTime(sessionResp.Result.XTran.ExpiryTime, minutes),
+		OpenedAt:         now,
+	}
+
+	updated, err := s.sessions.AttachReopened(ctx, outboxID, *link, next)
+	if errors.Is(err, entities.ErrPaymentLinkConflict) {
+		// Another request replaced the session, or the link was paid, first.
+		// Ours was kept as a prior session of the link.
+		return s.repo.GetByTransactionID(ctx, link.TransactionID)
+	}
+	if err != nil {
+		log.Error("Failed to store re-opened Acleda session", zap.String("session_id", next.SessionID), zap.Error(err))
+		// Keep the session in the outbox for the session reconciler to record.
+		if err := s.sessions.MarkReopened(ctx, outboxID, next); err != nil {
+			log.Error("Failed to record opened session", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	log.Info("Re-opened Acleda session", zap.String("session_id", next.SessionID), zap.Int("expiry_minutes", next.ExpiryTime))
+	return updated, nil
+}
+
+// sessionExpiryTime is the session lifetime in minutes the bank reported,
+// or the one requested when it reported none.
+func sessionExpiryTime(reported, requested int) int {
+	if reported > 0 {
+		return reported
+	}
+	return requested
+}
+
+func (s *CreateAcledaPaymentLinkService) saveToPaymentsTable(ctx context.Context, in CreateAcledaPaymentLinkInput, transactionID string) error {
+	// This would use the existing payment repository to save to payments table
+	// For now, return nil as placeholder
+	logger.For(ctx, s.log).Debug("Saving to payments table")
+	return nil
 }
 
 func toJSON(v interface{}) string {
@@ -201,3 +400,10 @@ func toJSON(v interface{}) string {
 	}
 	return ""
 }
+
+// newTransactionID returns a unique payment link ID. It doubles as the
+// session outbox key, so it must not repeat even for links created in the
+// same instant.
+func newTransactionID() string {
+	return "ACL-" + uuid.NewString()
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/gateway/acleda/acledatest"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// memorySessions keeps the session outbox in memory. Only the methods
+// RefreshSession calls before the bank answers are implemented.
+type memorySessions struct {
+	AcledaSessionRepository
+	rows map[string]entities.AcledaSession
+}
+
+func (m *memorySessions) RecordOpening(_ context.Context, session entities.AcledaSession) (string, error) {
+	id := session.TransactionID + "-" + session.ReplacesSessionID
+	session.ID = id
+	session.Status = entities.AcledaSessionOpening
+	m.rows[id] = session
+	return id, nil
+}
+
+func (m *memorySessions) MarkFailed(_ context.Context, id, reason string) error {
+	row := m.rows[id]
+	row.Status = entities.AcledaSessionFailed
+	row.LastError = reason
+	m.rows[id] = row
+	return nil
+}
+
+type noCredentials struct{ AcledaCredentialRepository }
+
+func (noCredentials) GetByMerchantCode(context.Context, string) (*entities.AcledaCredential, error) {
+	return nil, entities.ErrAcledaCredentialNotFound
+}
+
+func newRefreshTest(t *testing.T, fake *acledatest.Server) (*CreateAcledaPaymentLinkService, *memorySessions) {
+	t.Helper()
+	cfg := &configuration.Config{
+		ACLEDAOPENSESSIONV2URL: fake.OpenSessionV2URL(),
+		AcledaMerchantID:       "M-1",
+		AcledaTimeout:          5000,
+	}
+	sessions := &memorySessions{rows: make(map[string]entities.AcledaSession)}
+	svc := NewCreateAcledaPaymentLinkService(acleda.NewAcledaGateway(cfg), nil, sessions, nil, noCredentials{}, nil, resty.New(), cfg, zap.NewNop())
+	return svc, sessions
+}
+
+// expiredSessionLink is a pending link whose session the bank has already
+// expired, so RefreshSession re-opens it.
+func expiredSessionLink() *entities.PaymentAcledaPaymentLink {
+	now := time.Now()
+	return &entities.PaymentAcledaPaymentLink{
+		ID:                "ACL-1",
+		TransactionID:     "ACL-1",
+		MerchantID:        "MERCHANT",
+		SessionID:         "SID-OLD",
+		Amount:            entities.NewMoney(1000, "USD"),
+		Currency:          "USD",
+		InvoiceID:         "ACL-1",
+		Status:            entities.PaymentLinkStatusPending,
+		ExpiryTime:        60,
+		SessionExpiryTime: 10,
+		SessionOpenedAt:   now.Add(-15 * time.Minute),
+		CreatedAt:         now.Add(-15 * time.Minute),
+	}
+}
+
+func TestRefreshSessionMarksRefusedSessionFailed(t *testing.T) {
+	fake := acledatest.NewServer()
+	defer fake.Close()
+	fake.ErrorDetails = "MERCHANT_DISABLED"
+	svc, sessions := newRefreshTest(t, fake)
+
+	_, err := svc.RefreshSession(context.Background(), expiredSessionLink(), entities.Incoming{})
+	if !errors.Is(err, acleda.ErrRejected) {
+		t.Fatalf("RefreshSession error = %v; want acleda.ErrRejected", err)
+	}
+
+	row := sessions.rows["ACL-1-SID-OLD"]
+	if row.Status != entities.AcledaSessionFailed {
+		t.Fatalf("outbox status = %q; want %q", row.Status, entities.AcledaSessionFailed)
+	}
+	if row.LastError != "MERCHANT_DISABLED" {
+		t.Fatalf("outbox last error = %q; want %q", row.LastError, "MERCHANT_DISABLED")
+	}
+}
+
+func TestRefreshSessionKeepsUnansweredSessionOpening(t *testing.T) {
+	fake := acledatest.NewServer()
+	svc, sessions := newRefreshTest(t, fake)
+	fake.Close()
+
+	_, err := svc.RefreshSession(context.Background(), expiredSessionLink(), entities.Incoming{})
+	if err == nil || errors.Is(err, acleda.ErrRejected) {
+		t.Fatalf("RefreshSession error = %v; want a transport error", err)
+	}
+
+	if row := sessions.rows["ACL-1-SID-OLD"]; row.Status != entities.AcledaSessionOpening {
+		t.Fatalf("outbox status = %q; want %q", row.Status, entities.AcledaSessionOpening)
+	}
+}
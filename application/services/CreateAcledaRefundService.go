@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"payment-airpay/application/events"
+	"payment-airpay/application/messages"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/logger"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaymentLinkNotFound  = errors.New("payment link not found")
+	ErrPaymentNotRefundable = entities.ErrPaymentNotRefundable
+	ErrInvalidRefundAmount  = errors.New("invalid refund amount")
+	ErrInvalidRefundType    = errors.New("refund type must be REFUND or VOID")
+	ErrRefundNotFound       = errors.New("refund not found")
+	ErrInvalidResolution    = errors.New("resolution status must be SUCCEEDED or FAILED")
+)
+
+// maxConflictAttempts bounds how often a payment link update is re-evaluated
+// after losing to a concurrent update.
+const maxConflictAttempts = 3
+
+// pendingRefundGrace hides refunds from the pending list while their bank
+// call may still be running.
+const pendingRefundGrace = 5 * time.Minute
+
+const pendingRefundLimit = 100
+
+type CreateAcledaRefundService struct {
+	gateway   AcledaRefundGateway
+	links     PaymentLinkRepository
+	refunds   RefundRepository
+	accounts  AcledaCredentialRepository
+	queue     EventQueue
+	publisher Publisher
+	cfg       *configuration.Config
+	Client    *resty.Client
+	log       *zap.Logger
+}
+
+type CreateAcledaRefundInput struct {
+	// Amount is optional; an empty amount refunds everything not yet refunded.
+	Amount string `json:"amount"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// ResolveAcledaRefundInput settles a PENDING refund from the bank's records.
+type ResolveAcledaRefundInput struct {
+	Status        string `json:"status"`
+	BankReference string `json:"bank_reference"`
+	Note          string `json:"note"`
+}
+
+type CreateAcledaRefundOutput struct {
+	RefundID      string         `json:"refund_id"`
+	TransactionID string         `json:"transaction_id"`
+	Type          string         `json:"type"`
+	Amount        entities.Money `json:"amount"`
+	Currency      string         `json:"currency"`
+	Status        string         `json:"status"`
+	BankReference string         `json:"bank_reference,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+}
+
+func NewCreateAcledaRefundService(
+	gateway AcledaRefundGateway,
+	links PaymentLinkRepository,
+	refunds RefundRepository,
+	accounts AcledaCredentialRepository,
+	queue EventQueue,
+	publisher Publisher,
+	client *resty.Client,
+	cfg *configuration.Config,
+	log *zap.Logger,
+) *CreateAcledaRefundService {
+	return &CreateAcledaRefundService{
+		gateway:   gateway,
+		links:     links,
+		refunds:   refunds,
+		accounts:  accounts,
+		queue:     queue,
+		publisher: publisher,
+		cfg:       cfg,
+		Client:    client,
+		log:       logger.Component(log, "refund"),
+	}
+}
+
+func (s *CreateAcledaRefundService) Execute(ctx context.Context, transactionID string, in CreateAcledaRefundInput, incoming entities.Incoming) (*CreateAcledaRefundOutput, error) {
+	refundType := strings.ToUpper(strings.TrimSpace(in.Type))
+	if refundType == "" {
+		refundType = entities.RefundTypeRefund
+	}
+	if refundType != entities.RefundTypeRefund && refundType != entities.RefundTypeVoid {
+		return nil, ErrInvalidRefundType
+	}
+
+	link, err := s.links.GetByTransactionID(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to load payment link: %w", err)
+	}
+	if link == nil || link.MerchantID != incoming.Merchant {
+		return nil, ErrPaymentLinkNotFound
+	}
+	if link.Status != entities.PaymentLinkStatusPaid {
+		return nil, fmt.Errorf("%w: status is %s", ErrPaymentNotRefundable, link.Status)
+	}
+
+	captured := capturedAmount(*link)
+
+	refunded, err := s.refunds.SumSucceeded(ctx, link.ID, link.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum refunds: %w", err)
+	}
+
+	amount, err := captured.Sub(refunded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute refundable amount: %w", err)
+	}
+	if strings.TrimSpace(in.Amount) != "" {
+		amount, err = entities.ParseMoney(in.Amount, link.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRefundAmount, err)
+		}
+	}
+	if amount.Minor <= 0 {
+		return nil, fmt.Errorf("%w: nothing left to refund", ErrInvalidRefundAmount)
+	}
+	if refundType == entities.RefundTypeVoid && (!refunded.IsZero() || amount.Minor != captured.Minor) {
+		return nil, fmt.Errorf("%w: a void must reverse the full captured amount", ErrInvalidRefundAmount)
+	}
+
+	now := time.Now()
+	refund := entities.PaymentAcledaRefund{
+		ID:            newRefundID(),
+		PaymentLinkID: link.ID,
+		TransactionID: link.TransactionID,
+		Type:          refundType,
+		Amount:        amount,
+		Currency:      link.Currency,
+		Reason:        in.Reason,
+		Status:        entities.RefundStatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// Refunds go through the account the payment was taken on
+	account, err := resolveAcledaCredentials(ctx, s.accounts, s.cfg, link.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve before calling the bank so concurrent refunds cannot overdraw.
+	if err := s.refunds.Reserve(ctx, refund, captured); err != nil {
+		return nil, err
+	}
+	s.emit(ctx, refund, "refund requested")
+
+	url := s.cfg.AcledaRefundURL
+	if refundType == entities.RefundTypeVoid {
+		url = s.cfg.AcledaVoidURL
+	}
+
+	request := acleda.RefundRequestDto{
+		LoginID:    account.LoginID,
+		Password:   account.Password,
+		MerchantID: account.AcledaMerchantID,
+		Signature:  account.Secret,
+		RefundTransaction: acleda.RefundTransactionDTO{
+			TxID:           link.TransactionID,
+			RefundTxID:     refund.ID,
+			SessionID:      link.SessionID,
+			PaymentTokenID: link.PaymentTokenID,
+			RefundAmount:   amount.String(),
+			Currency:       link.Currency,
+			Reason:         in.Reason,
+		},
+	}
+
+	log := logger.For(ctx, s.log).With(zap.String("refund_id", refund.ID))
+	resp, err := s.gateway.Refund(ctx, s.Client, url, request)
+
+	SaveAPICallAsync(ctx, &resp, incoming.Merchant, err, "acleda", incoming.Path, "", incoming.Webtype, incoming.TransactionID)
+
+	refund.RequestJSON = resp.RequestAPICallResult.RequestBody
+	refund.ResponseJSON = resp.RequestAPICallResult.ResponseBody
+	refund.UpdatedAt = time.Now()
+
+	if err != nil && !errors.Is(err, acleda.ErrRejected) {
+		// The bank may have reversed the payment before its answer was lost,
+		// so the refund stays PENDING and keeps its amount reserved until it
+		// is resolved from the bank's records.
+		refund.ErrorDetails = err.Error()
+		if updateErr := s.refunds.UpdateResult(ctx, refund); updateErr != nil {
+			log.Error("Failed to record pending refund", zap.Error(updateErr))
+		}
+		log.Warn("Refund outcome unknown; kept pending", zap.Error(err))
+		return refundOutput(refund), nil
+	}
+
+	if err != nil {
+		refund.Status = entities.RefundStatusFailed
+		refund.ErrorDetails = err.Error()
+		if updateErr := s.refunds.UpdateResult(ctx, refund); updateErr != nil {
+			log.Error("Failed to record failed refund", zap.Error(updateErr))
+		}
+		s.emit(ctx, refund, "refund failed")
+		return nil, fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	refund.Status = entities.RefundStatusSucceeded
+	refund.BankReference = resp.Result.BankReference
+	if err := s.refunds.UpdateResult(ctx, refund); err != nil {
+		return nil, fmt.Errorf("refund %s succeeded at the bank but could not be recorded: %w", refund.ID, err)
+	}
+	s.emit(ctx, refund, "refund succeeded")
+	s.markIfFullyRefunded(ctx, *link, refund, incoming.Merchant)
+
+	log.Info("Refund succeeded", zap.Stringer("amount", refund.Amount))
+	return refundOutput(refund), nil
+}
+
+// Resolve settles a refund whose bank outcome was unknown, as SUCCEEDED or
+// FAILED, after checking it with Acleda. A failed refund releases its amount.
+func (s *CreateAcledaRefundService) Resolve(ctx context.Context, refundID string, in ResolveAcledaRefundInput, actor string) (*CreateAcledaRefundOutput, error) {
+	status := strings.ToUpper(strings.TrimSpace(in.Status))
+	if status != entities.RefundStatusSucceeded && status != entities.RefundStatusFailed {
+		return nil, ErrInvalidResolution
+	}
+
+	refund, err := s.refunds.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refund: %w", err)
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+	if refund.Status != entities.RefundStatusPending {
+		return nil, entities.ErrRefundNotPending
+	}
+
+	refund.Status = status
+	refund.BankReference = in.BankReference
+	refund.ErrorDetails = strings.TrimSpace("resolved by " + actor + ": " + in.Note)
+	refund.UpdatedAt = time.Now()
+	if err := s.refunds.Resolve(ctx, *refund); err != nil {
+		return nil, err
+	}
+
+	log := logger.For(ctx, s.log).With(zap.String("refund_id", refund.ID), zap.String("actor", actor))
+	log.Info("Resolved pending refund", zap.String("status", status))
+	if status == entities.RefundStatusFailed {
+		s.emit(ctx, *refund, "refund failed")
+		return refundOutput(*refund), nil
+	}
+
+	s.emit(ctx, *refund, "refund succeeded")
+	link, err := s.links.GetByTransactionID(ctx, refund.TransactionID)
+	if err != nil || link == nil {
+		log.Error("Failed to load payment link of resolved refund", zap.Error(err))
+		return refundOutput(*refund), nil
+	}
+	s.markIfFullyRefunded(ctx, *link, *refund, actor)
+	return refundOutput(*refund), nil
+}
+
+// ListPending returns refunds whose bank outcome is still unknown.
+func (s *CreateAcledaRefundService) ListPending(ctx context.Context) ([]entities.PaymentAcledaRefund, error) {
+	return s.refunds.ListPending(ctx, time.Now().Add(-pendingRefundGrace), pendingRefundLimit)
+}
+
+// markIfFullyRefunded moves the link to REFUNDED once its succeeded refunds
+// cover the captured amount.
+func (s *CreateAcledaRefundService) markIfFullyRefunded(ctx context.Context, link entities.PaymentAcledaPaymentLink, refund entities.PaymentAcledaRefund, actor string) {
+	log := logger.For(ctx, s.log).With(zap.String("refund_id", refund.ID))
+	total, err := s.refunds.SumSucceeded(ctx, link.ID, link.Currency)
+	if err != nil {
+		log.Error("Failed to sum refunds", zap.Error(err))
+		return
+	}
+	if total.Minor < capturedAmount(link).Minor {
+		return
+	}
+	err = s.markRefunded(ctx, entities.PaymentStatusChange{
+		TransactionID: link.TransactionID,
+		ToStatus:      entities.PaymentLinkStatusRefunded,
+		Source:        entities.StatusSourceRefund,
+		Actor:         actor,
+		Evidence:      refund.ResponseJSON,
+	})
+	if err != nil {
+		log.Error("Failed to mark payment as refunded", zap.Error(err))
+	}
+}
+
+// capturedAmount is what the bank captured for a link, falling back to the
+// requested amount for links stored without one.
+func capturedAmount(link entities.PaymentAcledaPaymentLink) entities.Money {
+	if link.PurchaseAmount.Minor > 0 {
+		return link.PurchaseAmount
+	}
+	return link.Amount
+}
+
+func refundOutput(refund entities.PaymentAcledaRefund) *CreateAcledaRefundOutput {
+	return &CreateAcledaRefundOutput{
+		RefundID:      refund.ID,
+		TransactionID: refund.TransactionID,
+		Type:          refund.Type,
+		Amount:        refund.Amount,
+		Currency:      refund.Currency,
+		Status:        refund.Status,
+		BankReference: refund.BankReference,
+		CreatedAt:     refund.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// markRefunded applies change, re-evaluating it against the current link
+// when a concurrent update wins the race.
+func (s *CreateAcledaRefundService) markRefunded(ctx context.Context, change entities.PaymentStatusChange) error {
+	var err error
+	for attempt := 0; attempt < maxConflictAttempts; attempt++ {
+		_, err = s.links.TransitionStatus(ctx, change)
+		if !errors.Is(err, entities.ErrPaymentLinkConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *CreateAcledaRefundService) ListByTransactionID(ctx context.Context, transactionID string) ([]entities.PaymentAcledaRefund, error) {
+	return s.refunds.ListByTransactionID(ctx, transactionID)
+}
+
+// emit reports a refund status change through the event queue and publisher.
+// Failures are logged rather than returned; the refund ledger is the source of truth.
+func (s *CreateAcledaRefundService) emit(ctx context.Context, refund entities.PaymentAcledaRefund, message string) {
+	log := logger.For(ctx, s.log).With(zap.String("refund_id", refund.ID))
+	now := time.Now()
+	if s.queue != nil {
+		if err := s.queue.Enqueue(ctx, events.RefundStatusChangedEvent{
+			Timestamp:     now,
+			RefundID:      refund.ID,
+			TransactionID: refund.TransactionID,
+			Type:          refund.Type,
+			Amount:        refund.Amount,
+			Currency:      refund.Currency,
+			Status:        refund.Status,
+			Message:       message,
+		}); err != nil {
+			log.Error("Failed to enqueue refund event", zap.Error(err))
+		}
+	}
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, messages.RefundStatusChangedMessage{
+			Timestamp:     now,
+			RefundID:      refund.ID,
+			TransactionID: refund.TransactionID,
+			Status:        refund.Status,
+			Message:       message,
+		}); err != nil {
+			log.Error("Failed to publish refund message", zap.Error(err))
+		}
+	}
+}
+
+// newRefundID returns a unique refund ID. It is also sent to Acleda as the
+// refund transaction, so two refunds started together must never share it.
+func newRefundID() string {
+	return "RFD-" + uuid.NewString()
+}
@@ -3,21 +3,24 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
-	"strconv"
 	"time"
 
 	"payment-airpay/domain/entities"
 	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
 )
 
 type CreateAcledaStagingPaymentService struct {
 	gateway *acleda.AcledaGateway
+	log     *zap.Logger
 }
 
-func NewCreateAcledaStagingPaymentService(gateway *acleda.AcledaGateway) *CreateAcledaStagingPaymentService {
+func NewCreateAcledaStagingPaymentService(gateway *acleda.AcledaGateway, log *zap.Logger) *CreateAcledaStagingPaymentService {
 	return &CreateAcledaStagingPaymentService{
 		gateway: gateway,
+		log:     logger.Component(log, "staging-payment"),
 	}
 }
 
@@ -40,29 +43,37 @@ type CreateAcledaStagingPaymentInput struct {
 }
 
 type CreateAcledaStagingPaymentOutput struct {
-	TransactionID string  `json:"transaction_id"`
-	PaymentMethod string  `json:"payment_method"`
-	Provider      string  `json:"provider"`
-	Bank          string  `json:"bank"`
-	PaymentLink   string  `json:"payment_link"`
-	PaymentCode   string  `json:"payment_code"`
-	Name          string  `json:"name"`
-	Email         string  `json:"email"`
-	Amount        float64 `json:"amount"`
-	Currency      string  `json:"currency"`
-	Status        string  `json:"status"`
-	ExpiresAt     string  `json:"expires_at"`
+	TransactionID string         `json:"transaction_id"`
+	PaymentMethod string         `json:"payment_method"`
+	Provider      string         `json:"provider"`
+	Bank          string         `json:"bank"`
+	PaymentLink   string         `json:"payment_link"`
+	PaymentCode   string         `json:"payment_code"`
+	Name          string         `json:"name"`
+	Email         string         `json:"email"`
+	Amount        entities.Money `json:"amount"`
+	Currency      string         `json:"currency"`
+	Status        string         `json:"status"`
+	ExpiresAt     string         `json:"expires_at"`
 }
 
 func (s *CreateAcledaStagingPaymentService) Execute(ctx context.Context, in *CreateAcledaStagingPaymentInput) (*CreateAcledaStagingPaymentOutput, error) {
-	log.Printf("Creating Acleda staging payment for amount: %s, msisdn: %s", in.Amount, in.Msisdn)
+	log := logger.For(ctx, s.log)
+	log.Debug("Creating Acleda staging payment", zap.String("amount", in.Amount), zap.String("currency", in.Currency))
+
+	amount, err := entities.ParseMoney(in.Amount, in.Currency)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate transaction ID
 	transactionID := fmt.Sprintf("LINKIT%d", time.Now().Unix())
+	logger.SetTransactionID(ctx, transactionID)
+	log = logger.For(ctx, s.log)
 
 	// Call Acleda staging gateway
 	resp, err := s.gateway.CreateStagingPayment(ctx, &acleda.StagingPaymentRequest{
-		Amount:            in.Amount,
+		Amount:            amount.String(),
 		Msisdn:            in.Msisdn,
 		Country:           in.Country,
 		Description:       in.Description,
@@ -84,12 +95,6 @@ func (s *CreateAcledaStagingPaymentService) Execute(ctx context.Context, in *Cre
 		return nil, fmt.Errorf("failed to create staging payment: %w", err)
 	}
 
-	// Parse amount
-	amount, err := strconv.ParseFloat(in.Amount, 64)
-	if err != nil {
-		return nil, fmt.Errorf("invalid amount format: %w", err)
-	}
-
 	// Calculate expiry time (30 minutes from now)
 	expiresAt := time.Now().Add(30 * time.Minute).Format("2006-01-02 03:04:05.000000000 -0700")
 
@@ -109,7 +114,7 @@ func (s *CreateAcledaStagingPaymentService) Execute(ctx context.Context, in *Cre
 		ExpiresAt:     expiresAt,
 	}
 
-	log.Printf("Successfully created Acleda staging payment: %s", transactionID)
+	log.Info("Acleda staging payment created")
 	return out, nil
 }
 
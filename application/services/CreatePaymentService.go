@@ -3,37 +3,41 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
 )
 
 type CreatePaymentService struct {
 	Gateway PaymentGateway
 	TxSvc   TransactionService
+	log     *zap.Logger
 }
 
-func NewCreatePaymentService(g PaymentGateway, t TransactionService) *CreatePaymentService {
-	return &CreatePaymentService{Gateway: g, TxSvc: t}
+func NewCreatePaymentService(g PaymentGateway, t TransactionService, log *zap.Logger) *CreatePaymentService {
+	return &CreatePaymentService{Gateway: g, TxSvc: t, log: logger.Component(log, "create-payment")}
 }
 
 func (s *CreatePaymentService) Execute(ctx context.Context, payload map[string]interface{}) (entities.Payment, error) {
-	log.Printf("[CreatePaymentService.Execute] Starting payment creation, channel_code: %v", payload["channel_code"])
+	log := logger.For(ctx, s.log)
+	log.Debug("Starting payment creation", zap.Any("channel_code", payload["channel_code"]))
 
 	res, err := s.Gateway.Create(ctx, payload)
 	if err != nil {
-		log.Printf("[CreatePaymentService.Execute] ERROR creating payment via gateway: %v", err)
+		log.Error("Failed to create payment via gateway", zap.Error(err))
 		return entities.Payment{}, err
 	}
 
-	log.Printf("[CreatePaymentService.Execute] Payment created successfully, payment_request_id: %s, channel_code: %s", res.PaymentRequestID, res.ChannelCode)
-	log.Printf("[CreatePaymentService.Execute] Calling TxSvc.Save to persist to database...")
+	log = log.With(zap.String("payment_request_id", res.PaymentRequestID))
+	log.Info("Payment created", zap.String("channel_code", res.ChannelCode))
 
 	if err := s.TxSvc.Save(ctx, res, payload); err != nil {
-		log.Printf("[CreatePaymentService.Execute] ERROR persisting payment to database: %v", err)
+		log.Error("Failed to persist payment", zap.Error(err))
 		return entities.Payment{}, fmt.Errorf("failed to persist payment: %w", err)
 	}
 
-	log.Printf("[CreatePaymentService.Execute] Payment persisted successfully to database")
+	log.Debug("Payment persisted")
 	return res, nil
 }
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/domain/entities"
+)
+
+type CurrencyRepository interface {
+	GetByCode(ctx context.Context, code string) (*entities.Currency, error)
+	ListMerchantCurrencies(ctx context.Context, merchantCode string) ([]string, error)
+	SetMerchantCurrencies(ctx context.Context, merchantCode string, codes []string, actor, ip string) error
+}
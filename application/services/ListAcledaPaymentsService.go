@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"payment-airpay/domain/entities"
+)
+
+const (
+	defaultPaymentListLimit = 20
+	maxPaymentListLimit     = 100
+)
+
+var ErrInvalidPaymentFilter = errors.New("invalid payment filter")
+
+type ListAcledaPaymentsService struct {
+	repo PaymentLinkRepository
+}
+
+type ListAcledaPaymentsInput struct {
+	Status    string `query:"status"`
+	Currency  string `query:"currency"`
+	InvoiceID string `query:"invoice_id"`
+	DateFrom  string `query:"date_from"`
+	DateTo    string `query:"date_to"`
+	AmountMin string `query:"amount_min"`
+	AmountMax string `query:"amount_max"`
+	SortBy    string `query:"sort_by"`
+	SortOrder string `query:"sort_order"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
+
+type ListAcledaPaymentsOutput struct {
+	Items      []entities.PaymentAcledaPaymentLink
+	Page       int
+	Limit      int
+	TotalRows  int
+	TotalPages int
+}
+
+func NewListAcledaPaymentsService(repo PaymentLinkRepository) *ListAcledaPaymentsService {
+	return &ListAcledaPaymentsService{repo: repo}
+}
+
+// Execute lists the payment links owned by merchantCode that match in.
+func (s *ListAcledaPaymentsService) Execute(ctx context.Context, merchantCode string, in ListAcledaPaymentsInput) (*ListAcledaPaymentsOutput, error) {
+	if merchantCode == "" {
+		return nil, fmt.Errorf("%w: merchant is required", ErrInvalidPaymentFilter)
+	}
+
+	filter := entities.PaymentLinkFilter{
+		MerchantID: merchantCode,
+		Status:     strings.ToUpper(strings.TrimSpace(in.Status)),
+		Currency:   strings.ToUpper(strings.TrimSpace(in.Currency)),
+		InvoiceID:  strings.TrimSpace(in.InvoiceID),
+		SortBy:     strings.ToLower(strings.TrimSpace(in.SortBy)),
+		SortOrder:  strings.ToLower(strings.TrimSpace(in.SortOrder)),
+		Page:       in.Page,
+		Limit:      in.Limit,
+	}
+
+	if filter.SortBy != "" && filter.SortBy != "created_at" && filter.SortBy != "updated_at" && filter.SortBy != "amount" && filter.SortBy != "status" {
+		return nil, fmt.Errorf("%w: sort_by must be one of created_at, updated_at, amount, status", ErrInvalidPaymentFilter)
+	}
+	if filter.SortOrder != "" && filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return nil, fmt.Errorf("%w: sort_order must be asc or desc", ErrInvalidPaymentFilter)
+	}
+
+	filter.Page, filter.Limit = normalizePage(filter.Page, filter.Limit)
+
+	var err error
+	if filter.CreatedFrom, err = parseFilterDate(in.DateFrom, false); err != nil {
+		return nil, fmt.Errorf("%w: date_from: %v", ErrInvalidPaymentFilter, err)
+	}
+	if filter.CreatedTo, err = parseFilterDate(in.DateTo, true); err != nil {
+		return nil, fmt.Errorf("%w: date_to: %v", ErrInvalidPaymentFilter, err)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: date_from must be before date_to", ErrInvalidPaymentFilter)
+	}
+
+	if filter.AmountMin, err = parseFilterAmount(in.AmountMin); err != nil {
+		return nil, fmt.Errorf("%w: amount_min: %v", ErrInvalidPaymentFilter, err)
+	}
+	if filter.AmountMax, err = parseFilterAmount(in.AmountMax); err != nil {
+		return nil, fmt.Errorf("%w: amount_max: %v", ErrInvalidPaymentFilter, err)
+	}
+	if filter.AmountMin != nil && filter.AmountMax != nil && *filter.AmountMin > *filter.AmountMax {
+		return nil, fmt.Errorf("%w: amount_min must not exceed amount_max", ErrInvalidPaymentFilter)
+	}
+
+	items, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	return &ListAcledaPaymentsOutput{
+		Items:      items,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalRows:  int(total),
+		TotalPages: totalPages(total, filter.Limit),
+	}, nil
+}
+
+// normalizePage applies the default and maximum page size.
+func normalizePage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPaymentListLimit
+	}
+	if limit > maxPaymentListLimit {
+		limit = maxPaymentListLimit
+	}
+	return page, limit
+}
+
+func totalPages(total int64, limit int) int {
+	return int((total + int64(limit) - 1) / int64(limit))
+}
+
+// parseFilterDate accepts RFC 3339 timestamps or plain dates. A plain date used
+// as an upper bound covers the whole day.
+func parseFilterDate(value string, upperBound bool) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", value)
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func parseFilterAmount(value string) (*int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := entities.ParseDecimal(value, entities.StorageScale)
+	if err != nil {
+		return nil, fmt.Errorf("expected a non-negative decimal, got %q", value)
+	}
+	return &n, nil
+}
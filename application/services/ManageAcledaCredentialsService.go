@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/configuration"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidAcledaCredential = errors.New("invalid acleda credentials")
+
+type ManageAcledaCredentialsService struct {
+	repo AcledaCredentialRepository
+}
+
+type SetAcledaCredentialsInput struct {
+	AcledaMerchantID string `json:"acleda_merchant_id"`
+	LoginID          string `json:"login_id"`
+	Password         string `json:"password"`
+	Secret           string `json:"secret"`
+}
+
+// AcledaCredentialsOutput never carries the password or secret; it only
+// reports whether they are set.
+type AcledaCredentialsOutput struct {
+	MerchantCode     string `json:"merchant_code"`
+	AcledaMerchantID string `json:"acleda_merchant_id"`
+	LoginID          string `json:"login_id"`
+	PasswordSet      bool   `json:"password_set"`
+	SecretSet        bool   `json:"secret_set"`
+	UpdatedDate      *int64 `json:"updated_date,omitempty"`
+	UpdatedUser      string `json:"updated_user,omitempty"`
+}
+
+func NewManageAcledaCredentialsService(repo AcledaCredentialRepository) *ManageAcledaCredentialsService {
+	return &ManageAcledaCredentialsService{repo: repo}
+}
+
+func (s *ManageAcledaCredentialsService) Get(ctx context.Context, merchantCode string) (*AcledaCredentialsOutput, error) {
+	credential, err := s.repo.GetByMerchantCode(ctx, merchantCode)
+	if err != nil {
+		return nil, err
+	}
+	return &AcledaCredentialsOutput{
+		MerchantCode:     merchantCode,
+		AcledaMerchantID: credential.AcledaMerchantID,
+		LoginID:          credential.LoginID,
+		PasswordSet:      credential.Password != "",
+		SecretSet:        credential.Secret != "",
+		UpdatedDate:      credential.UpdatedDate,
+		UpdatedUser:      credential.UpdatedUser,
+	}, nil
+}
+
+// Set creates or replaces the merchant's Acleda credentials. All four
+// values are required; a partial update would leave a broken profile.
+func (s *ManageAcledaCredentialsService) Set(ctx context.Context, merchantCode string, in SetAcledaCredentialsInput, actor entities.AuditActor) (*AcledaCredentialsOutput, error) {
+	credential := entities.AcledaCredential{
+		MerchantCode:     merchantCode,
+		AcledaMerchantID: strings.TrimSpace(in.AcledaMerchantID),
+		LoginID:          strings.TrimSpace(in.LoginID),
+		Password:         in.Password,
+		Secret:           in.Secret,
+	}
+	switch {
+	case credential.AcledaMerchantID == "":
+		return nil, fmt.Errorf("%w: acleda_merchant_id is required", ErrInvalidAcledaCredential)
+	case credential.LoginID == "":
+		return nil, fmt.Errorf("%w: login_id is required", ErrInvalidAcledaCredential)
+	case credential.Password == "":
+		return nil, fmt.Errorf("%w: password is required", ErrInvalidAcledaCredential)
+	case credential.Secret == "":
+		return nil, fmt.Errorf("%w: secret is required", ErrInvalidAcledaCredential)
+	}
+
+	if err := s.repo.Save(ctx, credential, actor); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to save acleda credentials: %w", err)
+	}
+	return s.Get(ctx, merchantCode)
+}
+
+// resolveAcledaCredentials returns the merchant's own Acleda account, or the
+// account configured in .env for merchants that have not been onboarded
+// with credentials of their own.
+func resolveAcledaCredentials(ctx context.Context, repo AcledaCredentialRepository, cfg *configuration.Config, merchantCode string) (*entities.AcledaCredential, error) {
+	credential, err := repo.GetByMerchantCode(ctx, merchantCode)
+	if err == nil {
+		return credential, nil
+	}
+	if !errors.Is(err, entities.ErrAcledaCredentialNotFound) {
+		return nil, fmt.Errorf("failed to load acleda credentials: %w", err)
+	}
+	return &entities.AcledaCredential{
+		MerchantCode:     merchantCode,
+		AcledaMerchantID: cfg.AcledaMerchantID,
+		LoginID:          cfg.AcledaLogin,
+		Password:         cfg.AcledaRemotePassword,
+		Secret:           cfg.AcledaSecret,
+	}, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type ManageMasterDataService struct {
+	repo MasterDataRepository
+}
+
+type ListMasterDataInput struct {
+	Code   string `query:"code"`
+	Name   string `query:"name"`
+	Status string `query:"status"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+}
+
+type ListMasterDataOutput struct {
+	Items      []entities.MasterData `json:"items"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalRows  int64                 `json:"total_rows"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+// MasterDataInput is the body of create and update requests. The currency
+// fields only apply to currencies; on update, omitted fields keep their value.
+type MasterDataInput struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	NumericCode *string `json:"numeric_code"`
+	MinorUnit   *int    `json:"minor_unit"`
+	MinAmount   *string `json:"min_amount"`
+	MaxAmount   *string `json:"max_amount"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+func NewManageMasterDataService(repo MasterDataRepository) *ManageMasterDataService {
+	return &ManageMasterDataService{repo: repo}
+}
+
+func (s *ManageMasterDataService) List(ctx context.Context, kind string, in ListMasterDataInput) (*ListMasterDataOutput, error) {
+	status := strings.ToUpper(strings.TrimSpace(in.Status))
+	switch status {
+	case "", entities.DataStatusActive, entities.DataStatusDeleted, "ALL":
+	default:
+		return nil, fmt.Errorf("%w: status must be ACTIVE, DELETED or ALL", entities.ErrInvalidMasterData)
+	}
+
+	page, limit := normalizePage(in.Page, in.Limit)
+	items, total, err := s.repo.List(ctx, entities.MasterDataFilter{
+		Kind:       kind,
+		Code:       normalizeMasterDataCode(kind, in.Code),
+		Name:       strings.TrimSpace(in.Name),
+		DataStatus: status,
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListMasterDataOutput{
+		Items:      items,
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages(total, limit),
+	}, nil
+}
+
+func (s *ManageMasterDataService) Get(ctx context.Context, kind, id string) (*entities.MasterData, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entities.ErrMasterDataNotFound
+	}
+	return s.repo.Get(ctx, kind, id)
+}
+
+func (s *ManageMasterDataService) Create(ctx context.Context, kind string, in MasterDataInput, actor entities.AuditActor) (*entities.MasterData, error) {
+	record := entities.MasterData{
+		Kind: kind,
+		Code: normalizeMasterDataCode(kind, in.Code),
+		Name: strings.TrimSpace(in.Name),
+	}
+	if err := validateMasterDataCode(kind, record.Code); err != nil {
+		return nil, err
+	}
+
+	if kind == entities.MasterDataCurrencies {
+		iso, _ := entities.LookupISOCurrency(record.Code)
+		if record.Name == "" {
+			record.Name = iso.Name
+		}
+		record.Currency = &entities.CurrencyAttributes{
+			NumericCode: iso.Numeric,
+			MinorUnit:   iso.MinorUnit,
+		}
+		if err := applyCurrencyInput(record.Currency, record.Code, in); err != nil {
+			return nil, err
+		}
+	}
+	if record.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", entities.ErrInvalidMasterData)
+	}
+
+	return s.repo.Create(ctx, record, actor)
+}
+
+func (s *ManageMasterDataService) Update(ctx context.Context, kind, id string, in MasterDataInput, actor entities.AuditActor) (*entities.MasterData, error) {
+	current, err := s.Get(ctx, kind, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.DataStatus == entities.DataStatusDeleted {
+		return nil, entities.ErrMasterDataNotFound
+	}
+	if code := normalizeMasterDataCode(kind, in.Code); code != "" && code != current.Code {
+		return nil, fmt.Errorf("%w: code cannot be changed", entities.ErrInvalidMasterData)
+	}
+
+	record := *current
+	if name := strings.TrimSpace(in.Name); name != "" {
+		record.Name = name
+	}
+	if kind == entities.MasterDataCurrencies && record.Currency != nil {
+		attrs := *record.Currency
+		if err := applyCurrencyInput(&attrs, record.Code, in); err != nil {
+			return nil, err
+		}
+		record.Currency = &attrs
+	}
+
+	return s.repo.Update(ctx, record, actor)
+}
+
+func (s *ManageMasterDataService) Delete(ctx context.Context, kind, id string, actor entities.AuditActor) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return entities.ErrMasterDataNotFound
+	}
+	return s.repo.Delete(ctx, kind, id, actor)
+}
+
+func normalizeMasterDataCode(kind, code string) string {
+	code = strings.TrimSpace(code)
+	switch kind {
+	case entities.MasterDataCountries, entities.MasterDataCurrencies, entities.MasterDataPaymentMethods:
+		return strings.ToUpper(code)
+	}
+	return code
+}
+
+func validateMasterDataCode(kind, code string) error {
+	switch {
+	case code == "":
+		return fmt.Errorf("%w: code is required", entities.ErrInvalidMasterData)
+	case kind == entities.MasterDataCountries && !countryCodePattern.MatchString(code):
+		return fmt.Errorf("%w: country code must be ISO 3166-1 alpha-2", entities.ErrInvalidMasterData)
+	case kind == entities.MasterDataCurrencies:
+		if _, ok := entities.LookupISOCurrency(code); !ok {
+			return fmt.Errorf("%w: %q is not an ISO 4217 currency", entities.ErrInvalidMasterData, code)
+		}
+	}
+	return nil
+}
+
+// applyCurrencyInput merges the currency fields of in into attrs and checks
+// that the result is consistent.
+func applyCurrencyInput(attrs *entities.CurrencyAttributes, code string, in MasterDataInput) error {
+	if in.NumericCode != nil {
+		attrs.NumericCode = strings.TrimSpace(*in.NumericCode)
+	}
+	if in.MinorUnit != nil {
+		attrs.MinorUnit = *in.MinorUnit
+	}
+	if in.MinAmount != nil {
+		attrs.MinAmount = optionalDecimal(*in.MinAmount)
+	}
+	if in.MaxAmount != nil {
+		attrs.MaxAmount = optionalDecimal(*in.MaxAmount)
+	}
+	if in.Enabled != nil {
+		attrs.Enabled = *in.Enabled
+	}
+
+	// Money always carries the currency's full exponent, so the catalogue
+	// can only narrow the precision accepted at the API.
+	exponent, err := entities.CurrencyExponent(code)
+	if err != nil {
+		return fmt.Errorf("%w: %v", entities.ErrInvalidMasterData, err)
+	}
+	if attrs.MinorUnit < 0 || attrs.MinorUnit > exponent {
+		return fmt.Errorf("%w: minor_unit for %s must be between 0 and %d", entities.ErrInvalidMasterData, code, exponent)
+	}
+
+	var minAmount, maxAmount entities.Money
+	if attrs.MinAmount != nil {
+		if minAmount, err = entities.ParseMoney(*attrs.MinAmount, code); err != nil {
+			return fmt.Errorf("%w: min_amount: %v", entities.ErrInvalidMasterData, err)
+		}
+	}
+	if attrs.MaxAmount != nil {
+		if maxAmount, err = entities.ParseMoney(*attrs.MaxAmount, code); err != nil {
+			return fmt.Errorf("%w: max_amount: %v", entities.ErrInvalidMasterData, err)
+		}
+	}
+	if attrs.MinAmount != nil && attrs.MaxAmount != nil && minAmount.Minor > maxAmount.Minor {
+		return fmt.Errorf("%w: min_amount must not exceed max_amount", entities.ErrInvalidMasterData)
+	}
+	return nil
+}
+
+// optionalDecimal treats an empty string as "no bound".
+func optionalDecimal(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/domain/entities"
+)
+
+type MasterDataRepository interface {
+	List(ctx context.Context, filter entities.MasterDataFilter) ([]entities.MasterData, int64, error)
+	Get(ctx context.Context, kind, id string) (*entities.MasterData, error)
+	Create(ctx context.Context, record entities.MasterData, actor entities.AuditActor) (*entities.MasterData, error)
+	Update(ctx context.Context, record entities.MasterData, actor entities.AuditActor) (*entities.MasterData, error)
+	Delete(ctx context.Context, kind, id string, actor entities.AuditActor) error
+}
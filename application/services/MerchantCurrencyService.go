@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"payment-airpay/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+var ErrMerchantNotFound = errors.New("merchant not found")
+
+type MerchantCurrencyService struct {
+	repo CurrencyRepository
+}
+
+type SetMerchantCurrenciesInput struct {
+	Currencies []string `json:"currencies"`
+}
+
+type MerchantCurrenciesOutput struct {
+	MerchantCode string   `json:"merchant_code"`
+	Currencies   []string `json:"currencies"`
+	// Restricted is false when no currencies are configured and the merchant
+	// may use every enabled currency.
+	Restricted bool `json:"restricted"`
+}
+
+func NewMerchantCurrencyService(repo CurrencyRepository) *MerchantCurrencyService {
+	return &MerchantCurrencyService{repo: repo}
+}
+
+func (s *MerchantCurrencyService) ListAllowed(ctx context.Context, merchantCode string) (*MerchantCurrenciesOutput, error) {
+	codes, err := s.repo.ListMerchantCurrencies(ctx, merchantCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merchant currencies: %w", err)
+	}
+	return &MerchantCurrenciesOutput{
+		MerchantCode: merchantCode,
+		Currencies:   codes,
+		Restricted:   len(codes) > 0,
+	}, nil
+}
+
+// SetAllowed replaces the merchant's allowed currencies. Every code must be
+// an enabled catalogue currency; an empty list lifts the restriction.
+func (s *MerchantCurrencyService) SetAllowed(ctx context.Context, merchantCode string, in SetMerchantCurrenciesInput, actor, ip string) (*MerchantCurrenciesOutput, error) {
+	codes := make([]string, 0, len(in.Currencies))
+	for _, code := range in.Currencies {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || slices.Contains(codes, code) {
+			continue
+		}
+		if _, err := enabledCurrency(ctx, s.repo, code); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	if err := s.repo.SetMerchantCurrencies(ctx, merchantCode, codes, actor, ip); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to save merchant currencies: %w", err)
+	}
+	return s.ListAllowed(ctx, merchantCode)
+}
+
+// ValidateAmount checks a payment amount against the currency catalogue and
+// the merchant's allowed currencies and returns it as exact Money.
+func (s *MerchantCurrencyService) ValidateAmount(ctx context.Context, merchantCode, amount, code string) (entities.Money, error) {
+	return validatePaymentAmount(ctx, s.repo, merchantCode, amount, code)
+}
+
+func enabledCurrency(ctx context.Context, repo CurrencyRepository, code string) (*entities.Currency, error) {
+	currency, err := repo.GetByCode(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %q is not an ISO 4217 currency", entities.ErrUnsupportedCurrency, code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currency %s: %w", code, err)
+	}
+	if !currency.Enabled {
+		return nil, fmt.Errorf("%w: %s is not enabled", entities.ErrUnsupportedCurrency, code)
+	}
+	return currency, nil
+}
+
+func validatePaymentAmount(ctx context.Context, repo CurrencyRepository, merchantCode, amount, code string) (entities.Money, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	currency, err := enabledCurrency(ctx, repo, code)
+	if err != nil {
+		return entities.Money{}, err
+	}
+
+	allowed, err := repo.ListMerchantCurrencies(ctx, merchantCode)
+	if err != nil {
+		return entities.Money{}, fmt.Errorf("failed to list merchant currencies: %w", err)
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, code) {
+		return entities.Money{}, fmt.Errorf("%w: %s (allowed: %s)", entities.ErrCurrencyNotAllowed, code, strings.Join(allowed, ", "))
+	}
+
+	money, err := entities.ParseMoney(amount, code)
+	if err != nil {
+		return entities.Money{}, err
+	}
+
+	// The catalogue's minor unit can only narrow the precision Money allows.
+	exponent, _ := entities.CurrencyExponent(code)
+	if currency.MinorUnit < exponent {
+		step := int64(math.Pow10(exponent - currency.MinorUnit))
+		if money.Minor%step != 0 {
+			return entities.Money{}, fmt.Errorf("%w: %s accepts at most %d decimal places", entities.ErrInvalidAmount, code, currency.MinorUnit)
+		}
+	}
+
+	if !currency.MinAmount.IsZero() && money.Minor < currency.MinAmount.Minor {
+		return entities.Money{}, fmt.Errorf("%w: minimum is %s %s", entities.ErrAmountOutOfRange, currency.MinAmount, code)
+	}
+	if !currency.MaxAmount.IsZero() && money.Minor > currency.MaxAmount.Minor {
+		return entities.Money{}, fmt.Errorf("%w: maximum is %s %s", entities.ErrAmountOutOfRange, currency.MaxAmount, code)
+	}
+	return money, nil
+}
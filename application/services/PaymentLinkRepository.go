@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/domain/entities"
+)
+
+type PaymentLinkRepository interface {
+	Create(ctx context.Context, paymentLink entities.PaymentAcledaPaymentLink) error
+	GetByTransactionID(ctx context.Context, transactionID string) (*entities.PaymentAcledaPaymentLink, error)
+	TransitionStatus(ctx context.Context, change entities.PaymentStatusChange) (*entities.PaymentStatusChange, error)
+	ListStatusHistory(ctx context.Context, transactionID string) ([]entities.PaymentStatusChange, error)
+	List(ctx context.Context, filter entities.PaymentLinkFilter) ([]entities.PaymentAcledaPaymentLink, int64, error)
+	// ListPending returns pending links whose expiry is before now when
+	// expired is set, or that are still payable otherwise.
+	ListPending(ctx context.Context, now time.Time, expired bool, limit int) ([]entities.PaymentAcledaPaymentLink, error)
+}
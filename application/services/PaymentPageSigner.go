@@ -0,0 +1,8 @@
+package services
+
+import "time"
+
+// PaymentPageSigner signs the token that authorises a payment page URL.
+type PaymentPageSigner interface {
+	Sign(subject string, expiresAt time.Time) string
+}
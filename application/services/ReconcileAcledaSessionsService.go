@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/infrastructure/logger"
+	"payment-airpay/infrastructure/metrics"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// sessionAttachGrace leaves sessions alone while their request may still
+	// be running: the bank call plus storing the link.
+	sessionAttachGrace = 5 * time.Minute
+
+	// sessionAttachDeadline is how long a stored link is retried before its
+	// session is flagged instead.
+	sessionAttachDeadline = time.Hour
+
+	sessionReconcileBatch = 100
+)
+
+// ReconcileAcledaSessionsService finds Acleda sessions that were opened, or
+// may have been opened, without a payment link being stored. It attaches
+// the ones it can and flags the rest as ORPHANED for manual cancellation at
+// the bank; the session API has no cancel call.
+type ReconcileAcledaSessionsService struct {
+	sessions AcledaSessionRepository
+	log      *zap.Logger
+}
+
+type ReconcileAcledaSessionsOutput struct {
+	Attached int
+	Orphaned int
+}
+
+func NewReconcileAcledaSessionsService(sessions AcledaSessionRepository, log *zap.Logger) *ReconcileAcledaSessionsService {
+	return &ReconcileAcledaSessionsService{sessions: sessions, log: logger.Component(log, "session-reconciliation")}
+}
+
+// Execute reconciles one batch of unattached sessions.
+func (s *ReconcileAcledaSessionsService) Execute(ctx context.Context) (*ReconcileAcledaSessionsOutput, error) {
+	now := time.Now()
+	sessions, err := s.sessions.ListUnattached(ctx, now.Add(-sessionAttachGrace), sessionReconcileBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ReconcileAcledaSessionsOutput{}
+	for _, session := range sessions {
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		log := logger.For(ctx, s.log).With(
+			zap.String("transaction_id", session.TransactionID),
+			zap.String("merchant", session.MerchantID),
+			zap.String("status", session.Status),
+		)
+		if session.ReplacesSessionID != "" {
+			log = log.With(zap.String("previous_session_id", session.ReplacesSessionID))
+		}
+
+		attached, err := s.sessions.AttachStored(ctx, session.ID)
+		switch {
+		case err == nil && attached:
+			log.Info("Attached Acleda session to its payment link")
+			out.Attached++
+			continue
+		case err != nil && now.Sub(session.CreatedAt) < sessionAttachDeadline:
+			log.Warn("Failed to attach Acleda session, will retry", zap.Error(err))
+			continue
+		}
+
+		reason := "bank outcome unknown: no payment link was stored"
+		if err != nil {
+			reason = "payment link could not be stored: " + err.Error()
+		}
+		if err := s.sessions.MarkOrphaned(ctx, session.ID, reason); err != nil {
+			log.Error("Failed to flag orphaned Acleda session", zap.Error(err))
+			continue
+		}
+		metrics.IncOrphanedAcledaSession()
+		log.Error("Orphaned Acleda session; cancel it with Acleda",
+			zap.String("acleda_merchant_id", session.AcledaMerchantID),
+			zap.String("session_id", session.SessionID),
+			zap.String("reason", reason),
+		)
+		out.Orphaned++
+	}
+	return out, nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	ReconciliationSourceUpload    = "upload"
+	ReconciliationSourceDirectory = "directory"
+)
+
+// settlementStatusMap translates Acleda statement statuses into our link statuses.
+var settlementStatusMap = map[string]string{
+	"":          entities.PaymentLinkStatusPaid,
+	"SUCCESS":   entities.PaymentLinkStatusPaid,
+	"PAID":      entities.PaymentLinkStatusPaid,
+	"SETTLED":   entities.PaymentLinkStatusPaid,
+	"APPROVED":  entities.PaymentLinkStatusPaid,
+	"COMPLETED": entities.PaymentLinkStatusPaid,
+	"REFUNDED":  entities.PaymentLinkStatusRefunded,
+	"REVERSED":  entities.PaymentLinkStatusRefunded,
+	"VOID":      entities.PaymentLinkStatusCancelled,
+	"VOIDED":    entities.PaymentLinkStatusCancelled,
+	"CANCELLED": entities.PaymentLinkStatusCancelled,
+	"FAILED":    entities.PaymentLinkStatusFailed,
+	"DECLINED":  entities.PaymentLinkStatusFailed,
+	"REJECTED":  entities.PaymentLinkStatusFailed,
+}
+
+type ReconcileAcledaSettlementService struct {
+	repo ReconciliationRepository
+	log  *zap.Logger
+}
+
+type ReconcileAcledaSettlementInput struct {
+	FileName string
+	Source   string
+	Actor    string
+	Content  io.Reader
+}
+
+type ListReconciliationItemsOutput struct {
+	Items      []entities.ReconciliationItem
+	Page       int
+	Limit      int
+	TotalRows  int
+	TotalPages int
+}
+
+type ListReconciliationReportsOutput struct {
+	Items      []entities.ReconciliationReport
+	Page       int
+	Limit      int
+	TotalRows  int
+	TotalPages int
+}
+
+func NewReconcileAcledaSettlementService(repo ReconciliationRepository, log *zap.Logger) *ReconcileAcledaSettlementService {
+	return &ReconcileAcledaSettlementService{repo: repo, log: logger.Component(log, "reconciliation")}
+}
+
+// Execute parses a settlement file, matches each line against our payment
+// links and stores the resulting report.
+func (s *ReconcileAcledaSettlementService) Execute(ctx context.Context, in ReconcileAcledaSettlementInput) (*entities.ReconciliationReport, error) {
+	content, err := io.ReadAll(in.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settlement file: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	exists, err := s.repo.ExistsByChecksum(ctx, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check settlement file: %w", err)
+	}
+	if exists {
+		return nil, entities.ErrSettlementAlreadyIngested
+	}
+
+	lines, err := acleda.ParseSettlementFile(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := entities.ReconciliationReport{
+		ID:           uuid.New().String(),
+		FileName:     in.FileName,
+		FileChecksum: checksum,
+		Source:       in.Source,
+		TotalLines:   len(lines),
+		CreatedBy:    in.Actor,
+		CreatedAt:    now,
+	}
+
+	totals := make(map[string]*entities.ReconciliationDailyTotal)
+	items := make([]entities.ReconciliationItem, 0, len(lines))
+
+	for _, line := range lines {
+		item, err := s.reconcileLine(ctx, line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.LineNo, err)
+		}
+		item.ReportID = report.ID
+		item.CreatedAt = now
+		items = append(items, item)
+
+		settledAt := line.SettledAt
+		if settledAt.IsZero() {
+			settledAt = now
+		}
+		day := settledAt.Format(time.DateOnly)
+		key := day + "|" + item.Currency
+		total, ok := totals[key]
+		if !ok {
+			total = &entities.ReconciliationDailyTotal{SettlementDate: day, Currency: item.Currency}
+			totals[key] = total
+		}
+		if err := addTotals(total, item); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.LineNo, err)
+		}
+
+		switch item.MatchStatus {
+		case entities.ReconciliationMatched:
+			report.MatchedCount++
+			total.MatchedCount++
+		case entities.ReconciliationMismatched:
+			report.MismatchedCount++
+			total.MismatchedCount++
+		default:
+			report.UnmatchedCount++
+			total.UnmatchedCount++
+		}
+	}
+
+	for _, total := range totals {
+		report.DailyTotals = append(report.DailyTotals, *total)
+	}
+	sort.Slice(report.DailyTotals, func(i, j int) bool {
+		a, b := report.DailyTotals[i], report.DailyTotals[j]
+		if a.SettlementDate != b.SettlementDate {
+			return a.SettlementDate < b.SettlementDate
+		}
+		return a.Currency < b.Currency
+	})
+
+	if err := s.repo.Save(ctx, report, items); err != nil {
+		return nil, fmt.Errorf("failed to save reconciliation report: %w", err)
+	}
+
+	logger.For(ctx, s.log).Info("Reconciled settlement file",
+		zap.String("file", in.FileName),
+		zap.Int("matched", report.MatchedCount),
+		zap.Int("mismatched", report.MismatchedCount),
+		zap.Int("unmatched", report.UnmatchedCount))
+	return &report, nil
+}
+
+func (s *ReconcileAcledaSettlementService) reconcileLine(ctx context.Context, line entities.SettlementLine) (entities.ReconciliationItem, error) {
+	item := entities.ReconciliationItem{
+		ID:            uuid.New().String(),
+		LineNo:        line.LineNo,
+		InvoiceID:     line.InvoiceID,
+		SessionID:     line.SessionID,
+		TransactionID: line.TransactionID,
+		BankStatus:    line.Status,
+		BankAmountRaw: line.Amount,
+		BankFeeRaw:    line.FeeAmount,
+		Currency:      line.Currency,
+		SettledAt:     line.SettledAt,
+		MatchStatus:   entities.ReconciliationUnmatched,
+	}
+
+	link, err := s.repo.FindPaymentLink(ctx, line.InvoiceID, line.TransactionID, line.SessionID)
+	if err != nil {
+		return item, err
+	}
+	if item.Currency == "" && link != nil {
+		item.Currency = link.Currency
+	}
+
+	// Amounts are converted in the settlement currency, so a bank amount
+	// with more decimals than the currency allows is flagged, not rounded. A
+	// line whose amounts cannot be converted keeps them as raw text.
+	var problems []string
+	amountsOK := false
+	item.BankAmount = entities.NewMoney(0, item.Currency)
+	item.BankFeeAmount = entities.NewMoney(0, item.Currency)
+	if item.Currency == "" {
+		problems = append(problems, "currency: missing")
+	} else {
+		bankAmount, amountErr := entities.MoneyFromDecimal(line.Amount, item.Currency)
+		if amountErr != nil {
+			problems = append(problems, fmt.Sprintf("amount: %v", amountErr))
+		} else {
+			item.BankAmount = bankAmount
+		}
+		bankFee, feeErr := entities.MoneyFromDecimal(line.FeeAmount, item.Currency)
+		if feeErr != nil {
+			problems = append(problems, fmt.Sprintf("fee: %v", feeErr))
+		} else {
+			item.BankFeeAmount = bankFee
+		}
+		amountsOK = amountErr == nil && feeErr == nil
+	}
+	item.RecordedAmount = entities.NewMoney(0, item.Currency)
+	item.RecordedFee = entities.NewMoney(0, item.Currency)
+	if link == nil {
+		item.Mismatches = strings.Join(problems, "; ")
+		return item, nil
+	}
+
+	item.PaymentLinkID = link.ID
+	item.RecordedStatus = link.Status
+
+	mismatches := problems
+	if !strings.EqualFold(item.Currency, link.Currency) {
+		mismatches = append(mismatches, fmt.Sprintf("currency: bank %s, recorded %s", item.Currency, link.Currency))
+	} else {
+		item.RecordedAmount = link.PurchaseAmount
+		if item.RecordedAmount.Minor <= 0 {
+			item.RecordedAmount = link.Amount
+		}
+		item.RecordedFee = link.FeeAmount
+		// Reversal lines carry negative amounts. The recorded side takes the
+		// same sign, so that a matched reversal also nets out of the daily
+		// totals.
+		if item.BankAmount.Minor < 0 {
+			item.RecordedAmount.Minor = -item.RecordedAmount.Minor
+		}
+		if item.BankFeeAmount.Minor < 0 {
+			item.RecordedFee.Minor = -item.RecordedFee.Minor
+		}
+		if amountsOK && item.BankAmount.Minor != item.RecordedAmount.Minor {
+			mismatches = append(mismatches, fmt.Sprintf("amount: bank %s, recorded %s", item.BankAmount, item.RecordedAmount))
+		}
+		if amountsOK && item.BankFeeAmount.Minor != item.RecordedFee.Minor {
+			mismatches = append(mismatches, fmt.Sprintf("fee: bank %s, recorded %s", item.BankFeeAmount, item.RecordedFee))
+		}
+	}
+	expected, known := settlementStatusMap[line.Status]
+	if !known {
+		expected = line.Status
+	}
+	if expected != link.Status {
+		mismatches = append(mismatches, fmt.Sprintf("status: bank %s, recorded %s", expected, link.Status))
+	}
+
+	if len(mismatches) == 0 {
+		item.MatchStatus = entities.ReconciliationMatched
+	} else {
+		item.MatchStatus = entities.ReconciliationMismatched
+		item.Mismatches = strings.Join(mismatches, "; ")
+	}
+	return item, nil
+}
+
+func (s *ReconcileAcledaSettlementService) ListReports(ctx context.Context, page, limit int) (*ListReconciliationReportsOutput, error) {
+	page, limit = normalizePage(page, limit)
+	reports, total, err := s.repo.ListReports(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ListReconciliationReportsOutput{
+		Items:      reports,
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  int(total),
+		TotalPages: totalPages(total, limit),
+	}, nil
+}
+
+func (s *ReconcileAcledaSettlementService) GetReport(ctx context.Context, id string) (*entities.ReconciliationReport, error) {
+	return s.repo.GetReport(ctx, id)
+}
+
+// ListExceptions returns mismatched and unmatched lines, optionally for one report.
+func (s *ReconcileAcledaSettlementService) ListExceptions(ctx context.Context, reportID, matchStatus string, page, limit int) (*ListReconciliationItemsOutput, error) {
+	statuses := []string{entities.ReconciliationUnmatched, entities.ReconciliationMismatched}
+	switch strings.ToUpper(matchStatus) {
+	case "":
+	case entities.ReconciliationUnmatched, entities.ReconciliationMismatched:
+		statuses = []string{strings.ToUpper(matchStatus)}
+	default:
+		return nil, fmt.Errorf("%w: match_status must be UNMATCHED or MISMATCHED", ErrInvalidPaymentFilter)
+	}
+
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.repo.ListItems(ctx, reportID, statuses, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ListReconciliationItemsOutput{
+		Items:      items,
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  int(total),
+		TotalPages: totalPages(total, limit),
+	}, nil
+}
+
+// addTotals adds an item's bank and recorded amounts to its daily total.
+func addTotals(total *entities.ReconciliationDailyTotal, item entities.ReconciliationItem) error {
+	var err error
+	if total.BankAmount, err = total.BankAmount.Add(item.BankAmount); err != nil {
+		return err
+	}
+	if total.BankFeeAmount, err = total.BankFeeAmount.Add(item.BankFeeAmount); err != nil {
+		return err
+	}
+	if total.RecordedAmount, err = total.RecordedAmount.Add(item.RecordedAmount); err != nil {
+		return err
+	}
+	total.RecordedFee, err = total.RecordedFee.Add(item.RecordedFee)
+	return err
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"payment-airpay/domain/entities"
+
+	"go.uber.org/zap"
+)
+
+// memoryReconciliations finds links by invoice ID and keeps the last report
+// saved.
+type memoryReconciliations struct {
+	ReconciliationRepository
+	links  map[string]*entities.PaymentAcledaPaymentLink
+	report entities.ReconciliationReport
+	items  []entities.ReconciliationItem
+}
+
+func (m *memoryReconciliations) ExistsByChecksum(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (m *memoryReconciliations) FindPaymentLink(_ context.Context, invoiceID, _, _ string) (*entities.PaymentAcledaPaymentLink, error) {
+	return m.links[invoiceID], nil
+}
+
+func (m *memoryReconciliations) Save(_ context.Context, report entities.ReconciliationReport, items []entities.ReconciliationItem) error {
+	m.report, m.items = report, items
+	return nil
+}
+
+func newReconcileTest() (*ReconcileAcledaSettlementService, *memoryReconciliations) {
+	usd := func(minor int64) entities.Money { return entities.NewMoney(minor, "USD") }
+	repo := &memoryReconciliations{links: map[string]*entities.PaymentAcledaPaymentLink{
+		"ACL-PAID":        {ID: "ACL-PAID", Currency: "USD", Amount: usd(1050), PurchaseAmount: usd(1050), FeeAmount: usd(30), Status: entities.PaymentLinkStatusPaid},
+		"ACL-REFUNDED":    {ID: "ACL-REFUNDED", Currency: "USD", Amount: usd(1050), PurchaseAmount: usd(1050), FeeAmount: usd(30), Status: entities.PaymentLinkStatusRefunded},
+		"ACL-NO-PURCHASE": {ID: "ACL-NO-PURCHASE", Currency: "USD", Amount: usd(1050), Status: entities.PaymentLinkStatusPaid},
+	}}
+	return NewReconcileAcledaSettlementService(repo, zap.NewNop()), repo
+}
+
+func TestReconcileLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       entities.SettlementLine
+		match      string
+		recorded   string
+		mismatches []string
+	}{
+		{
+			name:     "paid",
+			line:     entities.SettlementLine{InvoiceID: "ACL-PAID", Amount: "10.50", FeeAmount: "0.30", Currency: "USD", Status: "SUCCESS"},
+			match:    entities.ReconciliationMatched,
+			recorded: "10.50",
+		},
+		{
+			name:     "link amount when the bank sent no purchase amount",
+			line:     entities.SettlementLine{InvoiceID: "ACL-NO-PURCHASE", Amount: "10.50", Currency: "USD"},
+			match:    entities.ReconciliationMatched,
+			recorded: "10.50",
+		},
+		{
+			name:     "reversal",
+			line:     entities.SettlementLine{InvoiceID: "ACL-REFUNDED", Amount: "-10.50", FeeAmount: "-0.30", Currency: "USD", Status: "REVERSED"},
+			match:    entities.ReconciliationMatched,
+			recorded: "-10.50",
+		},
+		{
+			name:       "partial reversal",
+			line:       entities.SettlementLine{InvoiceID: "ACL-REFUNDED", Amount: "-5.00", FeeAmount: "-0.30", Currency: "USD", Status: "REVERSED"},
+			match:      entities.ReconciliationMismatched,
+			recorded:   "-10.50",
+			mismatches: []string{"amount: bank -5.00, recorded -10.50"},
+		},
+		{
+			name:       "amount and status",
+			line:       entities.SettlementLine{InvoiceID: "ACL-PAID", Amount: "10.00", FeeAmount: "0.30", Currency: "USD", Status: "DECLINED"},
+			match:      entities.ReconciliationMismatched,
+			recorded:   "10.50",
+			mismatches: []string{"amount: bank 10.00, recorded 10.50", "status: bank FAILED, recorded PAID"},
+		},
+		{
+			name:       "too many decimals",
+			line:       entities.SettlementLine{InvoiceID: "ACL-PAID", Amount: "10.505", FeeAmount: "0.30", Currency: "USD", Status: "SUCCESS"},
+			match:      entities.ReconciliationMismatched,
+			recorded:   "10.50",
+			mismatches: []string{"amount: invalid amount"},
+		},
+		{
+			name:       "currency",
+			line:       entities.SettlementLine{InvoiceID: "ACL-PAID", Amount: "40000", Currency: "KHR", Status: "SUCCESS"},
+			match:      entities.ReconciliationMismatched,
+			recorded:   "0",
+			mismatches: []string{"currency: bank KHR, recorded USD"},
+		},
+		{
+			name:     "currency taken from the link",
+			line:     entities.SettlementLine{InvoiceID: "ACL-PAID", Amount: "10.50", FeeAmount: "0.30", Status: "SUCCESS"},
+			match:    entities.ReconciliationMatched,
+			recorded: "10.50",
+		},
+		{
+			name:     "unknown link",
+			line:     entities.SettlementLine{InvoiceID: "ACL-UNKNOWN", Amount: "10.50", Currency: "USD"},
+			match:    entities.ReconciliationUnmatched,
+			recorded: "0.00",
+		},
+		{
+			name:       "unknown link without currency",
+			line:       entities.SettlementLine{InvoiceID: "ACL-UNKNOWN", Amount: "10.50"},
+			match:      entities.ReconciliationUnmatched,
+			recorded:   "0.00",
+			mismatches: []string{"currency: missing"},
+		},
+	}
+
+	svc, _ := newReconcileTest()
+	for _, tt := range tests {
+		item, err := svc.reconcileLine(context.Background(), tt.line)
+		if err != nil {
+			t.Fatalf("%s: reconcileLine: %v", tt.name, err)
+		}
+		if item.MatchStatus != tt.match {
+			t.Errorf("%s: match status = %s (%s); want %s", tt.name, item.MatchStatus, item.Mismatches, tt.match)
+		}
+		if got := item.RecordedAmount.String(); got != tt.recorded {
+			t.Errorf("%s: recorded amount = %s; want %s", tt.name, got, tt.recorded)
+		}
+		if item.BankAmountRaw != tt.line.Amount {
+			t.Errorf("%s: bank amount raw = %q; want %q", tt.name, item.BankAmountRaw, tt.line.Amount)
+		}
+		for _, want := range tt.mismatches {
+			if !strings.Contains(item.Mismatches, want) {
+				t.Errorf("%s: mismatches = %q; want it to contain %q", tt.name, item.Mismatches, want)
+			}
+		}
+		if len(tt.mismatches) == 0 && item.Mismatches != "" {
+			t.Errorf("%s: mismatches = %q; want none", tt.name, item.Mismatches)
+		}
+	}
+}
+
+func TestReconcileReversalNetsOutOfDailyTotals(t *testing.T) {
+	svc, repo := newReconcileTest()
+	file := "invoice_id,amount,fee,currency,status,settlement_date\n" +
+		"ACL-REFUNDED,10.50,0.30,USD,REFUNDED,2026-02-24\n" +
+		"ACL-REFUNDED,(10.50),(0.30),USD,REVERSED,2026-02-24\n"
+
+	report, err := svc.Execute(context.Background(), ReconcileAcledaSettlementInput{
+		FileName: "settlement.csv",
+		Content:  strings.NewReader(file),
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if report.MatchedCount != 2 {
+		t.Fatalf("matched = %d; want 2 (items: %+v)", report.MatchedCount, repo.items)
+	}
+	if len(report.DailyTotals) != 1 {
+		t.Fatalf("got %d daily totals; want 1", len(report.DailyTotals))
+	}
+
+	total := report.DailyTotals[0]
+	if !total.BankAmount.IsZero() || !total.RecordedAmount.IsZero() {
+		t.Errorf("amounts: bank %s, recorded %s; want both 0.00", total.BankAmount, total.RecordedAmount)
+	}
+	if !total.BankFeeAmount.IsZero() || !total.RecordedFee.IsZero() {
+		t.Errorf("fees: bank %s, recorded %s; want both 0.00", total.BankFeeAmount, total.RecordedFee)
+	}
+}
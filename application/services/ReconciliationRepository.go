@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"payment-airpay/domain/entities"
+)
+
+type ReconciliationRepository interface {
+	ExistsByChecksum(ctx context.Context, checksum string) (bool, error)
+	FindPaymentLink(ctx context.Context, invoiceID, transactionID, sessionID string) (*entities.PaymentAcledaPaymentLink, error)
+	Save(ctx context.Context, report entities.ReconciliationReport, items []entities.ReconciliationItem) error
+	ListReports(ctx context.Context, page, limit int) ([]entities.ReconciliationReport, int64, error)
+	GetReport(ctx context.Context, id string) (*entities.ReconciliationReport, error)
+	ListItems(ctx context.Context, reportID string, matchStatuses []string, page, limit int) ([]entities.ReconciliationItem, int64, error)
+}
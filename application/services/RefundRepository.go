@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/domain/entities"
+)
+
+type RefundRepository interface {
+	Reserve(ctx context.Context, refund entities.PaymentAcledaRefund, capturedAmount entities.Money) error
+	UpdateResult(ctx context.Context, refund entities.PaymentAcledaRefund) error
+	SumSucceeded(ctx context.Context, paymentLinkID, currency string) (entities.Money, error)
+	ListByTransactionID(ctx context.Context, transactionID string) ([]entities.PaymentAcledaRefund, error)
+	Resolve(ctx context.Context, refund entities.PaymentAcledaRefund) error
+	GetByID(ctx context.Context, id string) (*entities.PaymentAcledaRefund, error)
+	ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]entities.PaymentAcledaRefund, error)
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// statusPollBatch bounds how many links one pass polls, and how many it
+// sweeps.
+const statusPollBatch = 100
+
+var (
+	ErrInvalidPaymentStatus = errors.New("invalid payment status")
+	// ErrStatusAPINotConfigured means ACLEDA_API_URL is unset, so the bank
+	// cannot be asked for a payment's status.
+	ErrStatusAPINotConfigured = errors.New("acleda status api is not configured")
+)
+
+// UpdateAcledaPaymentStatusService makes every payment link status change
+// other than refunds, each through TransitionStatus with its source: the
+// customer returning from the bank (callback), the status poller (poll),
+// the expiry sweeper (sweeper) or an operator (admin). A callback is not
+// trusted on its own; it triggers a status query and the bank's answer
+// decides.
+type UpdateAcledaPaymentStatusService struct {
+	gateway AcledaStatusGateway
+	links   PaymentLinkRepository
+	cfg     *configuration.Config
+	log     *zap.Logger
+}
+
+type UpdateAcledaPaymentStatusOutput struct {
+	Polled  int
+	Changed int
+	Expired int
+}
+
+type SetPaymentStatusInput struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+func NewUpdateAcledaPaymentStatusService(gateway AcledaStatusGateway, links PaymentLinkRepository, cfg *configuration.Config, log *zap.Logger) *UpdateAcledaPaymentStatusService {
+	return &UpdateAcledaPaymentStatusService{
+		gateway: gateway,
+		links:   links,
+		cfg:     cfg,
+		log:     logger.Component(log, "payment-status"),
+	}
+}
+
+// Execute runs one pass of the sweeper and the poller: pending links past
+// their expiry are checked with the bank one last time and expired, then
+// payable ones are polled.
+func (s *UpdateAcledaPaymentStatusService) Execute(ctx context.Context) (*UpdateAcledaPaymentStatusOutput, error) {
+	out := &UpdateAcledaPaymentStatusOutput{}
+	now := time.Now()
+
+	due, err := s.links.ListPending(ctx, now, true, statusPollBatch)
+	if err != nil {
+		return out, fmt.Errorf("failed to list expired links: %w", err)
+	}
+	for _, link := range due {
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		s.sweep(ctx, link, out)
+	}
+
+	if !s.bankStatusEnabled() {
+		return out, nil
+	}
+	payable, err := s.links.ListPending(ctx, now, false, statusPollBatch)
+	if err != nil {
+		return out, fmt.Errorf("failed to list pending links: %w", err)
+	}
+	for _, link := range payable {
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		out.Polled++
+		changed, err := s.sync(ctx, link, entities.StatusSourcePoll, nil)
+		if err != nil {
+			logger.For(ctx, s.log).Warn("Failed to poll payment status",
+				zap.String("transaction_id", link.TransactionID), zap.Error(err))
+			continue
+		}
+		if changed {
+			out.Changed++
+		}
+	}
+	return out, nil
+}
+
+// sweep expires link unless the bank reports a final status for it first.
+// A payment the bank confirms later still moves the link to PAID.
+func (s *UpdateAcledaPaymentStatusService) sweep(ctx context.Context, link entities.PaymentAcledaPaymentLink, out *UpdateAcledaPaymentStatusOutput) {
+	log := logger.For(ctx, s.log).With(zap.String("transaction_id", link.TransactionID))
+
+	if s.bankStatusEnabled() {
+		out.Polled++
+		changed, err := s.sync(ctx, link, entities.StatusSourcePoll, nil)
+		if err != nil {
+			log.Warn("Failed to poll payment status before expiry", zap.Error(err))
+		}
+		if changed {
+			out.Changed++
+			return
+		}
+	}
+
+	_, err := s.links.TransitionStatus(ctx, entities.PaymentStatusChange{
+		TransactionID:   link.TransactionID,
+		ToStatus:        entities.PaymentLinkStatusExpired,
+		Source:          entities.StatusSourceSweeper,
+		Evidence:        toJSON(map[string]string{"expires_at": link.ExpiresAt().Format(time.RFC3339)}),
+		ExpectedVersion: link.Version,
+	})
+	switch {
+	case errors.Is(err, entities.ErrPaymentLinkConflict):
+		// Changed meanwhile; the next pass sees the new state.
+	case err != nil:
+		log.Error("Failed to expire payment link", zap.Error(err))
+	default:
+		out.Expired++
+	}
+}
+
+// HandleCallback records the customer's return from the bank's payment page
+// and settles the link with the status the bank reports. callback is kept
+// as evidence. The link is returned even when the bank cannot be reached;
+// the poller settles it later.
+func (s *UpdateAcledaPaymentStatusService) HandleCallback(ctx context.Context, transactionID string, callback map[string]string) (*entities.PaymentAcledaPaymentLink, error) {
+	link, err := s.getLink(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := s.sync(ctx, *link, entities.StatusSourceCallback, callback)
+	if err != nil {
+		logger.For(ctx, s.log).Warn("Failed to confirm payment status after callback", zap.Error(err))
+		return link, err
+	}
+	if changed {
+		return s.links.GetByTransactionID(ctx, transactionID)
+	}
+	return link, nil
+}
+
+// SetStatus moves a link to the status an operator decided on, for example
+// after checking a payment with the bank by hand. REFUNDED is left to
+// refunds, which keep the ledger in step.
+func (s *UpdateAcledaPaymentStatusService) SetStatus(ctx context.Context, transactionID string, in SetPaymentStatusInput, actor string) (*entities.PaymentStatusChange, error) {
+	status, err := entities.ParsePaymentLinkStatus(in.Status)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentStatus, err)
+	}
+	if status == entities.PaymentLinkStatusRefunded {
+		return nil, fmt.Errorf("%w: REFUNDED is set by refunds", ErrInvalidPaymentStatus)
+	}
+	if _, err := s.getLink(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	return s.links.TransitionStatus(ctx, entities.PaymentStatusChange{
+		TransactionID: transactionID,
+		ToStatus:      status,
+		Source:        entities.StatusSourceAdmin,
+		Actor:         actor,
+		Evidence:      toJSON(map[string]string{"note": in.Note}),
+	})
+}
+
+// ListHistory returns the status changes of a link, oldest first. When
+// merchantID is set, links of other merchants are reported as
+// ErrPaymentLinkNotFound, and the actor and evidence, which hold operator
+// names, notes and raw bank data, are left out.
+func (s *UpdateAcledaPaymentStatusService) ListHistory(ctx context.Context, merchantID, transactionID string) ([]entities.PaymentStatusChange, error) {
+	link, err := s.getLink(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if merchantID == "" {
+		return s.links.ListStatusHistory(ctx, transactionID)
+	}
+	if link.MerchantID != merchantID {
+		return nil, ErrPaymentLinkNotFound
+	}
+
+	history, err := s.links.ListStatusHistory(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history {
+		history[i].Actor = ""
+		history[i].Evidence = ""
+	}
+	return history, nil
+}
+
+// sync asks the bank for the status of link and records it with source. It
+// reports whether the link's status changed.
+func (s *UpdateAcledaPaymentStatusService) sync(ctx context.Context, link entities.PaymentAcledaPaymentLink, source string, callback map[string]string) (bool, error) {
+	if !s.bankStatusEnabled() {
+		return false, ErrStatusAPINotConfigured
+	}
+
+	resp, err := s.gateway.GetPaymentStatus(ctx, acleda.PaymentStatusRequest{TransactionID: link.TransactionID})
+	if err != nil {
+		return false, err
+	}
+	to := linkStatusFromBank(resp.PaymentStatus)
+	if to == "" {
+		return false, nil
+	}
+
+	evidence := map[string]interface{}{"bank": resp}
+	if callback != nil {
+		evidence["callback"] = callback
+	}
+	recorded, err := s.links.TransitionStatus(ctx, entities.PaymentStatusChange{
+		TransactionID: link.TransactionID,
+		ToStatus:      to,
+		Source:        source,
+		Actor:         "acleda",
+		Evidence:      toJSON(evidence),
+	})
+	if err != nil {
+		return false, err
+	}
+	if recorded != nil {
+		logger.For(ctx, s.log).Info("Payment status changed",
+			zap.String("transaction_id", link.TransactionID),
+			zap.String("from", recorded.FromStatus),
+			zap.String("to", recorded.ToStatus),
+			zap.String("source", source))
+	}
+	return recorded != nil, nil
+}
+
+func (s *UpdateAcledaPaymentStatusService) getLink(ctx context.Context, transactionID string) (*entities.PaymentAcledaPaymentLink, error) {
+	link, err := s.links.GetByTransactionID(ctx, transactionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payment link: %w", err)
+	}
+	if link == nil {
+		return nil, ErrPaymentLinkNotFound
+	}
+	return link, nil
+}
+
+func (s *UpdateAcledaPaymentStatusService) bankStatusEnabled() bool {
+	return s.gateway != nil && s.cfg != nil && s.cfg.AcledaAPIURL != ""
+}
+
+// linkStatusFromBank maps the payment_status of the bank's status API to a
+// payment link status. It returns "" while the payment is still open.
+func linkStatusFromBank(status string) string {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "PAID", "SUCCESS", "SUCCEEDED", "COMPLETED", "APPROVED":
+		return entities.PaymentLinkStatusPaid
+	case "FAILED", "DECLINED", "REJECTED":
+		return entities.PaymentLinkStatusFailed
+	case "CANCELLED", "CANCELED":
+		return entities.PaymentLinkStatusCancelled
+	case "EXPIRED":
+		return entities.PaymentLinkStatusExpired
+	default:
+		return ""
+	}
+}
@@ -3,16 +3,19 @@ package services
 import (
 	"context"
 	"errors"
-	"fmt"
 	"reflect"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
 
+	"payment-airpay/infrastructure/background"
 	"payment-airpay/infrastructure/database"
 	"payment-airpay/infrastructure/database/models"
 	pkg "payment-airpay/infrastructure/gateway"
+	"payment-airpay/infrastructure/metrics"
+	"payment-airpay/infrastructure/tracing"
 )
 
 func formatErrorToString(err error) string {
@@ -56,17 +59,47 @@ func SaveAPICall(
 	}
 
 	if database.ElasticsearchClient != nil {
+		_, span := tracing.Tracer().Start(ctx, "elasticsearch.index api_call_logs")
+		done := metrics.ElasticIndexStarted("api_call_logs")
 		_, err := database.ElasticsearchClient.Index("api_call_logs").
 			Request(&data).
 			Do(context.Background())
+		done(err)
 		if err != nil {
-			fmt.Printf("Failed to save API call to Elasticsearch: %v\n", err)
+			span.RecordError(err)
+		}
+		span.End()
+		if err != nil {
+			zap.L().Warn("Failed to save API call to Elasticsearch",
+				zap.String("component", "api-call-log"),
+				zap.String("merchant", merchant),
+				zap.String("transaction_id", transactionId),
+				zap.Error(err))
 		}
 	} else {
-		fmt.Println("ElasticsearchClient is not initialized")
+		zap.L().Debug("Elasticsearch is not initialized; API call not saved", zap.String("component", "api-call-log"))
 	}
 }
 
+// SaveAPICallAsync runs SaveAPICall in the background. The write outlives
+// the request but is waited for on shutdown.
+func SaveAPICallAsync(
+	ctx context.Context,
+	param pkg.APICall,
+	merchant string,
+	err error,
+	service string,
+	track string,
+	msisdn string,
+	webtype string,
+	transactionId string,
+) {
+	ctx = context.WithoutCancel(ctx)
+	background.Go(func() {
+		SaveAPICall(ctx, param, merchant, err, service, track, msisdn, webtype, transactionId)
+	})
+}
+
 func ValidateRequest(req interface{}) error {
 	validate := validator.New()
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/crypto"
+	"payment-airpay/infrastructure/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// runCommand runs a one-off maintenance command instead of the server.
+//
+//	rotate-keys          re-encrypt stored secrets with the active master key
+//	migrate up           apply pending schema migrations
+//	migrate down [n]     revert the last n migrations (default 1)
+//	migrate status       list migrations and whether they are applied
+func runCommand(args []string) {
+	switch args[0] {
+	case "rotate-keys":
+		rotateKeys()
+	case "migrate":
+		migrate(args[1:])
+	default:
+		zap.L().Fatal("Unknown command", zap.String("command", args[0]))
+	}
+}
+
+func rotateKeys() {
+	ring := crypto.DefaultKeyRing()
+	if ring == nil {
+		zap.L().Fatal("rotate-keys needs ENCRYPTION_MASTER_KEYS or ENCRYPTION_MASTER_KEYS_FILE")
+	}
+
+	database.InitializeYugabyteDB()
+
+	n, err := database.RotateEncryptionKeys(context.Background(), database.YugabyteDBClient, ring)
+	if err != nil {
+		zap.L().Fatal("Failed to rotate encryption keys", zap.Error(err))
+	}
+	zap.L().Info("Re-encrypted stored secrets",
+		zap.Int("count", n),
+		zap.String("key_id", ring.ActiveKeyID()),
+	)
+}
+
+func migrate(args []string) {
+	if len(args) == 0 {
+		zap.L().Fatal("Usage: migrate up|down [n]|status")
+	}
+
+	// The schema check in InitializeYugabyteDB would refuse the very
+	// database this command is meant to fix, so open it directly.
+	db, err := database.OpenYugabyteDB(configuration.AppConfig)
+	if err != nil {
+		zap.L().Fatal("Failed to open YugabyteDB", zap.Error(err))
+	}
+	database.YugabyteDBClient = db
+	defer database.CloseYugabyteDB()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateYugabyteDB(ctx, db)
+		if err != nil {
+			zap.L().Fatal("Failed to migrate YugabyteDB", zap.Error(err))
+		}
+		zap.L().Info("Schema is up to date", zap.Int("applied", len(applied)))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				zap.L().Fatal("migrate down takes a positive number of steps", zap.String("steps", args[1]))
+			}
+		}
+		reverted, err := database.MigrateDown(ctx, db, steps)
+		if err != nil {
+			zap.L().Fatal("Failed to revert migrations", zap.Error(err))
+		}
+		zap.L().Info("Reverted migrations", zap.Int("reverted", len(reverted)))
+	case "status":
+		printMigrationStatus(ctx, db)
+	default:
+		zap.L().Fatal("Unknown migrate command", zap.String("command", args[0]))
+	}
+}
+
+func printMigrationStatus(ctx context.Context, db *gorm.DB) {
+	states, err := database.MigrationStatus(ctx, db)
+	if err != nil {
+		zap.L().Fatal("Failed to read migration status", zap.Error(err))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range states {
+		status, appliedAt := "pending", ""
+		if s.Applied {
+			status = "applied"
+			appliedAt = s.AppliedAt.Local().Format(time.RFC3339)
+		}
+		switch {
+		case s.Unknown:
+			status += " (unknown to this build)"
+		case s.Modified:
+			status += " (modified since applied)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+	}
+	w.Flush()
+}
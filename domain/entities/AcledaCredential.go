@@ -0,0 +1,17 @@
+package entities
+
+import "errors"
+
+var ErrAcledaCredentialNotFound = errors.New("acleda credentials not configured for merchant")
+
+// AcledaCredential is the Acleda XPay account a merchant's payment links are
+// opened under.
+type AcledaCredential struct {
+	MerchantCode     string `json:"merchant_code"`
+	AcledaMerchantID string `json:"acleda_merchant_id"`
+	LoginID          string `json:"login_id"`
+	Password         string `json:"-"`
+	Secret           string `json:"-"`
+	UpdatedDate      *int64 `json:"updated_date,omitempty"`
+	UpdatedUser      string `json:"updated_user,omitempty"`
+}
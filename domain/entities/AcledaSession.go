@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// Lifecycle of an Acleda session in the session outbox.
+const (
+	// AcledaSessionOpening is recorded before the bank is called. A session
+	// that stays here is one whose outcome at the bank is unknown.
+	AcledaSessionOpening = "OPENING"
+	// AcledaSessionOpened means the bank opened the session but the payment
+	// link could not be stored; the outbox holds the link to store later.
+	AcledaSessionOpened = "OPENED"
+	// AcledaSessionAttached means the session has its payment link.
+	AcledaSessionAttached = "ATTACHED"
+	// AcledaSessionFailed means the bank refused to open the session.
+	AcledaSessionFailed = "FAILED"
+	// AcledaSessionOrphaned flags a session that may be open at the bank
+	// without a payment link. It needs to be cancelled with Acleda by hand.
+	AcledaSessionOrphaned = "ORPHANED"
+)
+
+// AcledaSession is the outbox record of one OpenSessionV2 call. It exists so
+// that a session the bank opened is never lost when the payment link cannot
+// be stored.
+type AcledaSession struct {
+	ID            string `json:"id"`
+	TransactionID string `json:"transaction_id"`
+	// ReplacesSessionID is the session of the link that this one re-opens;
+	// empty for the session a link was created with.
+	ReplacesSessionID string    `json:"replaces_session_id,omitempty"`
+	MerchantID        string    `json:"merchant_id"`
+	AcledaMerchantID  string    `json:"acleda_merchant_id"`
+	SessionID         string    `json:"session_id"`
+	PaymentTokenID    string    `json:"payment_token_id"`
+	Status            string    `json:"status"`
+	LastError         string    `json:"last_error"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,70 @@
+package entities
+
+import "errors"
+
+// Master data kinds, as used in the admin API path.
+const (
+	MasterDataCountries        = "countries"
+	MasterDataCurrencies       = "currencies"
+	MasterDataPaymentMethods   = "payment-methods"
+	MasterDataVAProviders      = "va-providers"
+	MasterDataEWalletProviders = "ewallet-providers"
+
+	DataStatusActive  = "ACTIVE"
+	DataStatusDeleted = "DELETED"
+)
+
+var (
+	ErrUnknownMasterDataKind = errors.New("unknown master data kind")
+	ErrMasterDataNotFound    = errors.New("master data record not found")
+	ErrMasterDataConflict    = errors.New("master data record already exists")
+	ErrInvalidMasterData     = errors.New("invalid master data")
+)
+
+// MasterData is a row of one of the master data tables. Code is the
+// table's natural key (provider_name for VA and e-wallet providers).
+type MasterData struct {
+	ID         string              `json:"id"`
+	Kind       string              `json:"kind"`
+	Code       string              `json:"code"`
+	Name       string              `json:"name"`
+	Currency   *CurrencyAttributes `json:"currency,omitempty"`
+	DataStatus string              `json:"data_status"`
+
+	CreatedDate *int64  `json:"created_date,omitempty"`
+	CreatedUser *string `json:"created_user,omitempty"`
+	CreatedIp   *string `json:"created_ip,omitempty"`
+	UpdatedDate *int64  `json:"updated_date,omitempty"`
+	UpdatedUser *string `json:"updated_user,omitempty"`
+	UpdatedIp   *string `json:"updated_ip,omitempty"`
+	DeletedDate *int64  `json:"deleted_date,omitempty"`
+	DeletedUser *string `json:"deleted_user,omitempty"`
+	DeletedIp   *string `json:"deleted_ip,omitempty"`
+}
+
+// CurrencyAttributes are the extra columns of the currencies table.
+// MinAmount and MaxAmount are plain decimals; nil means no bound.
+type CurrencyAttributes struct {
+	NumericCode string  `json:"numeric_code"`
+	MinorUnit   int     `json:"minor_unit"`
+	MinAmount   *string `json:"min_amount"`
+	MaxAmount   *string `json:"max_amount"`
+	Enabled     bool    `json:"enabled"`
+}
+
+// MasterDataFilter narrows a master data listing. DataStatus defaults to
+// ACTIVE; "ALL" includes soft-deleted rows.
+type MasterDataFilter struct {
+	Kind       string
+	Code       string
+	Name       string
+	DataStatus string
+	Page       int
+	Limit      int
+}
+
+// AuditActor identifies who made a change, for the audit columns.
+type AuditActor struct {
+	User string
+	IP   string
+}
@@ -0,0 +1,7 @@
+package entities
+
+type Merchant struct {
+	ID   string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
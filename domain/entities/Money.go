@@ -0,0 +1,239 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+)
+
+// StorageScale is the number of decimal places amounts are stored with.
+// It covers every supported currency exponent.
+const StorageScale = 4
+
+// maxWholeDigits keeps amounts inside numeric(20,4) and int64 minor units.
+const maxWholeDigits = 14
+
+// CurrencyExponent returns the number of decimal places used by currency:
+// the ISO 4217 minor unit, or Acleda's own where it differs.
+func CurrencyExponent(currency string) (int, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if defaults, ok := AcledaCurrencyDefaults[code]; ok {
+		return defaults.MinorUnit, nil
+	}
+	if iso, ok := LookupISOCurrency(code); ok {
+		return iso.MinorUnit, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+}
+
+// Money is an exact amount held in the minor units of its currency
+// (cents for USD, riel for KHR).
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// NewMoney builds a Money from an amount already expressed in minor units.
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(strings.TrimSpace(currency))}
+}
+
+// ParseMoney strictly parses a positive decimal amount such as "10.50" for
+// the given currency. Signs, exponents, separators and more decimal places
+// than the currency allows are rejected.
+func ParseMoney(amount, currency string) (Money, error) {
+	exponent, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	minor, err := ParseDecimal(amount, exponent)
+	if err != nil {
+		return Money{}, err
+	}
+	if minor <= 0 {
+		return Money{}, fmt.Errorf("%w: %q must be greater than zero", ErrInvalidAmount, amount)
+	}
+	return NewMoney(minor, currency), nil
+}
+
+// MoneyFromDecimal converts a stored or bank-reported decimal into Money.
+// Unlike ParseMoney it accepts zero and trailing zeros past the currency
+// exponent ("10.5000"), but still rejects any non-zero digit it would
+// have to round away.
+func MoneyFromDecimal(value, currency string) (Money, error) {
+	exponent, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return NewMoney(0, currency), nil
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	if whole, frac, ok := strings.Cut(value, "."); ok && len(frac) > exponent {
+		if strings.Trim(frac[exponent:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, value, exponent)
+		}
+		value = whole + "." + frac[:exponent]
+		value = strings.TrimSuffix(value, ".")
+	}
+
+	minor, err := ParseDecimal(value, exponent)
+	if err != nil {
+		return Money{}, err
+	}
+	if negative {
+		minor = -minor
+	}
+	return NewMoney(minor, currency), nil
+}
+
+// ParseDecimal parses an unsigned decimal string with at most scale
+// fractional digits and returns it scaled to an integer.
+func ParseDecimal(value string, scale int) (int64, error) {
+	value = strings.TrimSpace(value)
+	whole, frac, hasPoint := strings.Cut(value, ".")
+	if whole == "" || !isDigits(whole) || (hasPoint && (frac == "" || !isDigits(frac))) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if len(frac) > scale {
+		return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, value, scale)
+	}
+
+	if len(strings.TrimLeft(whole, "0")) > maxWholeDigits {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, value)
+	}
+
+	digits := whole + frac + strings.Repeat("0", scale-len(frac))
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, value)
+	}
+	return n, nil
+}
+
+// FormatDecimal renders an integer scaled by scale as a plain decimal string.
+func FormatDecimal(n int64, scale int) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	s := strconv.FormatInt(n, 10)
+	if scale == 0 {
+		return sign + s
+	}
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String renders the amount with exactly the currency's decimal places,
+// which is the form Acleda expects ("10.50" USD, "40000" KHR).
+func (m Money) String() string {
+	exponent, err := CurrencyExponent(m.Currency)
+	if err != nil {
+		exponent = 2
+	}
+	return FormatDecimal(m.Minor, exponent)
+}
+
+// StorageString renders the amount for a numeric(20,4) column.
+func (m Money) StorageString() string {
+	exponent, err := CurrencyExponent(m.Currency)
+	if err != nil {
+		exponent = 2
+	}
+	return FormatDecimal(m.Minor*int64(math.Pow10(StorageScale-exponent)), StorageScale)
+}
+
+// MarshalJSON emits the amount as an exact JSON number literal.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads an amount written by MarshalJSON, as a JSON number or
+// string. JSON carries no currency, so m.Currency must be set beforehand.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	parsed, err := MoneyFromDecimal(value, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.sameCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Minor+other.Minor, currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := m.sameCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Minor-other.Minor, currency), nil
+}
+
+// Cmp returns -1, 0 or 1. Amounts in different currencies are an error.
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Minor < other.Minor:
+		return -1, nil
+	case m.Minor > other.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// sameCurrency returns the currency shared by both amounts. The zero
+// Money{} has no currency and combines with any other amount.
+func (m Money) sameCurrency(other Money) (string, error) {
+	switch {
+	case m.Currency == "":
+		return other.Currency, nil
+	case other.Currency == "" || m.Currency == other.Currency:
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+}
@@ -0,0 +1,176 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value string
+		scale int
+		want  int64
+		err   bool
+	}{
+		{value: "10.50", scale: 2, want: 1050},
+		{value: "10.5", scale: 2, want: 1050},
+		{value: "10", scale: 2, want: 1000},
+		{value: "0.01", scale: 2, want: 1},
+		{value: " 7.25 ", scale: 2, want: 725},
+		{value: "40000", scale: 0, want: 40000},
+		{value: "00012.3400", scale: 4, want: 123400},
+		{value: "99999999999999.9999", scale: 4, want: 999999999999999999},
+
+		{value: "-1.00", scale: 2, err: true},
+		{value: "+1.00", scale: 2, err: true},
+		{value: "10.505", scale: 2, err: true},
+		{value: "40000.5", scale: 0, err: true},
+		{value: "1e3", scale: 2, err: true},
+		{value: "1,000.00", scale: 2, err: true},
+		{value: ".50", scale: 2, err: true},
+		{value: "10.", scale: 2, err: true},
+		{value: "", scale: 2, err: true},
+		{value: "100000000000000", scale: 4, err: true},
+		{value: "9223372036854775807", scale: 0, err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.value, tt.scale)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidAmount) {
+				t.Errorf("ParseDecimal(%q, %d) = %d, %v; want ErrInvalidAmount", tt.value, tt.scale, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDecimal(%q, %d) = %d, %v; want %d", tt.value, tt.scale, got, err, tt.want)
+		}
+	}
+}
+
+func TestMoneyFromDecimal(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+		err      error
+	}{
+		{value: "10.50", currency: "USD", want: 1050},
+		{value: "10.5000", currency: "USD", want: 1050},
+		{value: "0", currency: "USD", want: 0},
+		{value: "", currency: "USD", want: 0},
+		{value: "-25.00", currency: "USD", want: -2500},
+		{value: "40000", currency: "KHR", want: 40000},
+		{value: "40000.0000", currency: "KHR", want: 40000},
+		{value: "-100", currency: "khr", want: -100},
+
+		{value: "10.505", currency: "USD", err: ErrInvalidAmount},
+		{value: "40000.5", currency: "KHR", err: ErrInvalidAmount},
+		{value: "+10.00", currency: "USD", err: ErrInvalidAmount},
+		{value: "--10.00", currency: "USD", err: ErrInvalidAmount},
+		{value: "100000000000000.00", currency: "USD", err: ErrInvalidAmount},
+		{value: "10.00", currency: "XXX", err: ErrUnsupportedCurrency},
+	}
+	for _, tt := range tests {
+		got, err := MoneyFromDecimal(tt.value, tt.currency)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("MoneyFromDecimal(%q, %q) = %v, %v; want %v", tt.value, tt.currency, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got.Minor != tt.want {
+			t.Errorf("MoneyFromDecimal(%q, %q) = %d, %v; want %d", tt.value, tt.currency, got.Minor, err, tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money   Money
+		want    string
+		storage string
+	}{
+		{money: NewMoney(1050, "USD"), want: "10.50", storage: "10.5000"},
+		{money: NewMoney(5, "USD"), want: "0.05", storage: "0.0500"},
+		{money: NewMoney(-2500, "USD"), want: "-25.00", storage: "-25.0000"},
+		{money: NewMoney(40000, "KHR"), want: "40000", storage: "40000.0000"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q; want %q", tt.money, got, tt.want)
+		}
+		if got := tt.money.StorageString(); got != tt.storage {
+			t.Errorf("%#v.StorageString() = %q; want %q", tt.money, got, tt.storage)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	usd := func(minor int64) Money { return NewMoney(minor, "USD") }
+	tests := []struct {
+		name     string
+		a, b     Money
+		sum, dif Money
+		cmp      int
+		err      bool
+	}{
+		{name: "larger", a: usd(1000), b: usd(250), sum: usd(1250), dif: usd(750), cmp: 1},
+		{name: "smaller", a: usd(250), b: usd(1000), sum: usd(1250), dif: usd(-750), cmp: -1},
+		{name: "equal", a: usd(1000), b: usd(1000), sum: usd(2000), dif: usd(0), cmp: 0},
+		{name: "negative", a: usd(-500), b: usd(200), sum: usd(-300), dif: usd(-700), cmp: -1},
+		{name: "zero value left", a: Money{}, b: usd(100), sum: usd(100), dif: usd(-100), cmp: -1},
+		{name: "zero value right", a: usd(100), b: Money{}, sum: usd(100), dif: usd(100), cmp: 1},
+		{name: "currency mismatch", a: usd(100), b: NewMoney(100, "KHR"), err: true},
+	}
+	for _, tt := range tests {
+		sum, addErr := tt.a.Add(tt.b)
+		dif, subErr := tt.a.Sub(tt.b)
+		cmp, cmpErr := tt.a.Cmp(tt.b)
+		if tt.err {
+			for _, err := range []error{addErr, subErr, cmpErr} {
+				if !errors.Is(err, ErrCurrencyMismatch) {
+					t.Errorf("%s: error = %v; want ErrCurrencyMismatch", tt.name, err)
+				}
+			}
+			continue
+		}
+		if addErr != nil || sum != tt.sum {
+			t.Errorf("%s: Add = %v, %v; want %v", tt.name, sum, addErr, tt.sum)
+		}
+		if subErr != nil || dif != tt.dif {
+			t.Errorf("%s: Sub = %v, %v; want %v", tt.name, dif, subErr, tt.dif)
+		}
+		if cmpErr != nil || cmp != tt.cmp {
+			t.Errorf("%s: Cmp = %d, %v; want %d", tt.name, cmp, cmpErr, tt.cmp)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type body struct {
+		Amount Money `json:"amount"`
+	}
+
+	data, err := json.Marshal(body{Amount: NewMoney(1050, "USD")})
+	if err != nil || string(data) != `{"amount":10.50}` {
+		t.Fatalf("Marshal = %s, %v; want {\"amount\":10.50}", data, err)
+	}
+
+	for _, in := range []string{`{"amount":10.50}`, `{"amount":"10.50"}`} {
+		got := body{Amount: Money{Currency: "USD"}}
+		if err := json.Unmarshal([]byte(in), &got); err != nil || got.Amount != NewMoney(1050, "USD") {
+			t.Errorf("Unmarshal(%s) = %v, %v; want 10.50 USD", in, got.Amount, err)
+		}
+	}
+
+	got := body{Amount: Money{Currency: "KHR"}}
+	if err := json.Unmarshal([]byte(`{"amount":40000.5}`), &got); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Unmarshal of 40000.5 KHR error = %v; want ErrInvalidAmount", err)
+	}
+
+	got = body{}
+	if err := json.Unmarshal([]byte(`{"amount":10.50}`), &got); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Unmarshal without currency error = %v; want ErrUnsupportedCurrency", err)
+	}
+}
@@ -12,7 +12,7 @@ type Payment struct {
 	Type             string                 `json:"type"`
 	Country          string                 `json:"country"`
 	Currency         string                 `json:"currency"`
-	RequestAmount    float64                `json:"request_amount"`
+	RequestAmount    Money                  `json:"request_amount"`
 	CaptureMethod    string                 `json:"capture_method"`
 	ChannelCode      string                 `json:"channel_code"`
 	ChannelProps     map[string]interface{} `json:"channel_properties"`
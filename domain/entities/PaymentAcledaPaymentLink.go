@@ -8,16 +8,19 @@ type PaymentAcledaPaymentLink struct {
 	ID              string    `json:"id"`
 	TransactionID   string    `json:"transaction_id"`
 	MerchantID      string    `json:"merchant_id"`
+	AcledaMerchantID string   `json:"acleda_merchant_id"`
 	SessionID       string    `json:"session_id"`
 	PaymentTokenID  string    `json:"payment_token_id"`
 	Description     string    `json:"description"`
-	Amount          float64   `json:"amount"`
+	Amount          Money     `json:"amount"`
 	Currency        string    `json:"currency"`
 	InvoiceID       string    `json:"invoice_id"`
 	Status          string    `json:"status"`
 	ExpiryTime      int       `json:"expiry_time"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	// Version is incremented on every update; see PaymentLinkConflictError.
+	Version         int64     `json:"version"`
 	
 	// Foreign Keys
 	PaymentID       string    `json:"payment_id"`
@@ -27,20 +30,46 @@ type PaymentAcledaPaymentLink struct {
 	CurrencyID      string    `json:"currency_id"`
 	
 	// Additional fields from Acleda response
-	PurchaseAmount   float64   `json:"purchase_amount"`
+	PurchaseAmount   Money     `json:"purchase_amount"`
 	PurchaseDate     int64     `json:"purchase_date"`
 	Quantity         int       `json:"quantity"`
 	ConfirmDate      int64     `json:"confirm_date"`
 	PurchaseType     int       `json:"purchase_type"`
 	SaveToken        int       `json:"save_token"`
-	FeeAmount        float64   `json:"fee_amount"`
+	FeeAmount        Money     `json:"fee_amount"`
 	TxDirection      int       `json:"tx_direction"`
 	
 	// URLs
 	ReturnURL        string    `json:"return_url"`
 	ErrorURL         string    `json:"error_url"`
 	
+	// Current Acleda session: when it was opened and for how many minutes
+	// the bank keeps it. Earlier sessions are kept as PaymentLinkSession.
+	SessionOpenedAt   time.Time `json:"session_opened_at"`
+	SessionExpiryTime int       `json:"session_expiry_time"`
+
 	// Request/Response JSON for debugging
 	RequestJSON      string    `json:"request_json"`
 	ResponseJSON     string    `json:"response_json"`
 }
+
+// DefaultPaymentLinkExpiry applies to links stored without an expiry time.
+const DefaultPaymentLinkExpiry = 60 * time.Minute
+
+// ExpiresAt returns when the link stops accepting payment. ExpiryTime is in
+// minutes from creation.
+func (p PaymentAcledaPaymentLink) ExpiresAt() time.Time {
+	if p.ExpiryTime <= 0 {
+		return p.CreatedAt.Add(DefaultPaymentLinkExpiry)
+	}
+	return p.CreatedAt.Add(time.Duration(p.ExpiryTime) * time.Minute)
+}
+
+// SessionExpiresAt returns when the bank stops accepting the current
+// session. Links stored before sessions were tracked use the link expiry.
+func (p PaymentAcledaPaymentLink) SessionExpiresAt() time.Time {
+	if p.SessionOpenedAt.IsZero() || p.SessionExpiryTime <= 0 {
+		return p.ExpiresAt()
+	}
+	return p.SessionOpenedAt.Add(time.Duration(p.SessionExpiryTime) * time.Minute)
+}
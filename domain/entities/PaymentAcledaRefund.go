@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RefundTypeRefund = "REFUND"
+	RefundTypeVoid   = "VOID"
+
+	RefundStatusPending   = "PENDING"
+	RefundStatusSucceeded = "SUCCEEDED"
+	RefundStatusFailed    = "FAILED"
+)
+
+var (
+	// ErrRefundExceedsCaptured is returned when a refund would push the total
+	// refunded for a payment above the amount that was captured.
+	ErrRefundExceedsCaptured = errors.New("refund amount exceeds remaining captured amount")
+	ErrPaymentNotRefundable  = errors.New("payment is not in a refundable state")
+	ErrRefundNotPending      = errors.New("refund is not pending")
+)
+
+type PaymentAcledaRefund struct {
+	ID            string    `json:"id"`
+	PaymentLinkID string    `json:"payment_link_id"`
+	TransactionID string    `json:"transaction_id"`
+	Type          string    `json:"type"`
+	Amount        Money     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Reason        string    `json:"reason"`
+	Status        string    `json:"status"`
+	BankReference string    `json:"bank_reference"`
+	ErrorDetails  string    `json:"error_details"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Request/Response JSON for debugging; credentials are redacted, and
+	// neither is returned to merchants.
+	RequestJSON  string `json:"-"`
+	ResponseJSON string `json:"-"`
+}
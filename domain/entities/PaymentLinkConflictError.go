@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPaymentLinkConflict matches every PaymentLinkConflictError.
+var ErrPaymentLinkConflict = errors.New("payment link was changed concurrently")
+
+// PaymentLinkConflictError is returned when a payment link update was based
+// on a version that is no longer current, because a callback, poll or the
+// expiry sweeper changed the link first. The caller should re-read the link
+// and decide again whether the update still applies.
+type PaymentLinkConflictError struct {
+	TransactionID string
+	// Version is the version the update expected to replace.
+	Version int64
+}
+
+func (e *PaymentLinkConflictError) Error() string {
+	return fmt.Sprintf("%s: %s is no longer at version %d", ErrPaymentLinkConflict, e.TransactionID, e.Version)
+}
+
+func (e *PaymentLinkConflictError) Is(target error) bool {
+	return target == ErrPaymentLinkConflict
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// PaymentLinkFilter narrows a payment link search. Zero values mean "no filter".
+type PaymentLinkFilter struct {
+	MerchantID  string
+	Status      string
+	Currency    string
+	InvoiceID   string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// AmountMin and AmountMax are decimals scaled by StorageScale.
+	AmountMin *int64
+	AmountMax *int64
+
+	SortBy    string
+	SortOrder string
+	Page      int
+	Limit     int
+}
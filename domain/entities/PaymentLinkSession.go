@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// PaymentLinkSession is an Acleda session a payment link used before it was
+// replaced by a newer one. Settlement lines may still carry its session ID.
+type PaymentLinkSession struct {
+	ID               string    `json:"id"`
+	PaymentLinkID    string    `json:"payment_link_id"`
+	TransactionID    string    `json:"transaction_id"`
+	SessionID        string    `json:"session_id"`
+	PaymentTokenID   string    `json:"payment_token_id"`
+	AcledaMerchantID string    `json:"acleda_merchant_id"`
+	ExpiryTime       int       `json:"expiry_time"`
+	OpenedAt         time.Time `json:"opened_at"`
+	ReplacedAt       time.Time `json:"replaced_at"`
+}
@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	PaymentLinkStatusPending   = "PENDING"
+	PaymentLinkStatusPaid      = "PAID"
+	PaymentLinkStatusFailed    = "FAILED"
+	PaymentLinkStatusExpired   = "EXPIRED"
+	PaymentLinkStatusCancelled = "CANCELLED"
+	PaymentLinkStatusRefunded  = "REFUNDED"
+)
+
+// Sources of a payment link status change.
+const (
+	StatusSourceCallback = "callback"
+	StatusSourcePoll     = "poll"
+	StatusSourceSweeper  = "sweeper"
+	StatusSourceAdmin    = "admin"
+	StatusSourceRefund   = "refund"
+)
+
+var (
+	ErrUnknownPaymentLinkStatus  = errors.New("unknown payment link status")
+	ErrInvalidStatusTransition   = errors.New("invalid payment link status transition")
+	ErrStatusChangeSourceMissing = errors.New("status change source is required")
+)
+
+// paymentLinkTransitions lists the statuses each status may move to. The
+// bank is the source of truth for money, so a payment it confirms after the
+// link failed or expired is still recorded as PAID.
+var paymentLinkTransitions = map[string][]string{
+	PaymentLinkStatusPending:   {PaymentLinkStatusPaid, PaymentLinkStatusFailed, PaymentLinkStatusExpired, PaymentLinkStatusCancelled},
+	PaymentLinkStatusFailed:    {PaymentLinkStatusPaid},
+	PaymentLinkStatusExpired:   {PaymentLinkStatusPaid},
+	PaymentLinkStatusPaid:      {PaymentLinkStatusRefunded},
+	PaymentLinkStatusCancelled: {},
+	PaymentLinkStatusRefunded:  {},
+}
+
+// ParsePaymentLinkStatus returns the canonical form of status, accepting any
+// letter case.
+func ParsePaymentLinkStatus(status string) (string, error) {
+	canonical := strings.ToUpper(strings.TrimSpace(status))
+	if _, ok := paymentLinkTransitions[canonical]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownPaymentLinkStatus, status)
+	}
+	return canonical, nil
+}
+
+// ValidatePaymentLinkTransition reports whether a link may move from one
+// status to another. Staying in the same status is allowed; callers treat it
+// as a no-op, since callbacks and polls repeat.
+func ValidatePaymentLinkTransition(from, to string) error {
+	from, err := ParsePaymentLinkStatus(from)
+	if err != nil {
+		return err
+	}
+	to, err = ParsePaymentLinkStatus(to)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+	for _, next := range paymentLinkTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, to)
+}
+
+// PaymentStatusChange is one entry of a payment link's status history.
+// Evidence holds the raw data the change was based on, such as the callback
+// body or the bank's status response.
+type PaymentStatusChange struct {
+	ID            string    `json:"id"`
+	PaymentLinkID string    `json:"payment_link_id"`
+	TransactionID string    `json:"transaction_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	Source        string    `json:"source"`
+	Actor         string    `json:"actor"`
+	Evidence      string    `json:"evidence"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// ExpectedVersion, when set, is the link version the change was decided
+	// on. The change fails with a PaymentLinkConflictError if the link has
+	// moved on since.
+	ExpectedVersion int64 `json:"-"`
+}
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatePaymentLinkTransition(t *testing.T) {
+	statuses := []string{
+		PaymentLinkStatusPending,
+		PaymentLinkStatusPaid,
+		PaymentLinkStatusFailed,
+		PaymentLinkStatusExpired,
+		PaymentLinkStatusCancelled,
+		PaymentLinkStatusRefunded,
+	}
+	// Every move between two different statuses that is allowed. All others
+	// must be rejected.
+	allowed := map[[2]string]bool{
+		{PaymentLinkStatusPending, PaymentLinkStatusPaid}:      true,
+		{PaymentLinkStatusPending, PaymentLinkStatusFailed}:    true,
+		{PaymentLinkStatusPending, PaymentLinkStatusExpired}:   true,
+		{PaymentLinkStatusPending, PaymentLinkStatusCancelled}: true,
+		{PaymentLinkStatusFailed, PaymentLinkStatusPaid}:       true,
+		{PaymentLinkStatusExpired, PaymentLinkStatusPaid}:      true,
+		{PaymentLinkStatusPaid, PaymentLinkStatusRefunded}:     true,
+	}
+
+	if len(paymentLinkTransitions) != len(statuses) {
+		t.Fatalf("transitions cover %d statuses; the test covers %d", len(paymentLinkTransitions), len(statuses))
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			err := ValidatePaymentLinkTransition(from, to)
+			switch {
+			case from == to || allowed[[2]string{from, to}]:
+				if err != nil {
+					t.Errorf("%s -> %s: %v; want allowed", from, to, err)
+				}
+			case !errors.Is(err, ErrInvalidStatusTransition):
+				t.Errorf("%s -> %s: %v; want ErrInvalidStatusTransition", from, to, err)
+			}
+		}
+	}
+}
+
+func TestValidatePaymentLinkTransitionStatusNames(t *testing.T) {
+	if err := ValidatePaymentLinkTransition(" pending", "Paid "); err != nil {
+		t.Errorf("mixed case: %v; want allowed", err)
+	}
+	if err := ValidatePaymentLinkTransition("PENDING", "SETTLED"); !errors.Is(err, ErrUnknownPaymentLinkStatus) {
+		t.Errorf("unknown target: %v; want ErrUnknownPaymentLinkStatus", err)
+	}
+	if err := ValidatePaymentLinkTransition("", "PAID"); !errors.Is(err, ErrUnknownPaymentLinkStatus) {
+		t.Errorf("empty source: %v; want ErrUnknownPaymentLinkStatus", err)
+	}
+}
@@ -0,0 +1,78 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ReconciliationMatched    = "MATCHED"
+	ReconciliationMismatched = "MISMATCHED"
+	ReconciliationUnmatched  = "UNMATCHED"
+)
+
+// ErrSettlementAlreadyIngested is returned when the same settlement file
+// content has already been reconciled.
+var ErrSettlementAlreadyIngested = errors.New("settlement file has already been reconciled")
+
+// SettlementLine is one row of an Acleda settlement statement.
+type SettlementLine struct {
+	LineNo        int
+	InvoiceID     string
+	SessionID     string
+	TransactionID string
+	Amount        string // exact decimal text, converted once the currency is known
+	FeeAmount     string
+	Currency      string
+	Status        string
+	SettledAt     time.Time
+}
+
+type ReconciliationReport struct {
+	ID              string                     `json:"id"`
+	FileName        string                     `json:"file_name"`
+	FileChecksum    string                     `json:"file_checksum"`
+	Source          string                     `json:"source"`
+	TotalLines      int                        `json:"total_lines"`
+	MatchedCount    int                        `json:"matched_count"`
+	MismatchedCount int                        `json:"mismatched_count"`
+	UnmatchedCount  int                        `json:"unmatched_count"`
+	CreatedBy       string                     `json:"created_by"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	DailyTotals     []ReconciliationDailyTotal `json:"daily_totals,omitempty"`
+}
+
+type ReconciliationDailyTotal struct {
+	SettlementDate  string `json:"settlement_date"`
+	Currency        string `json:"currency"`
+	BankAmount      Money  `json:"bank_amount"`
+	BankFeeAmount   Money  `json:"bank_fee_amount"`
+	RecordedAmount  Money  `json:"recorded_amount"`
+	RecordedFee     Money  `json:"recorded_fee"`
+	MatchedCount    int    `json:"matched_count"`
+	MismatchedCount int    `json:"mismatched_count"`
+	UnmatchedCount  int    `json:"unmatched_count"`
+}
+
+type ReconciliationItem struct {
+	ID             string    `json:"id"`
+	ReportID       string    `json:"report_id"`
+	LineNo         int       `json:"line_no"`
+	InvoiceID      string    `json:"invoice_id"`
+	SessionID      string    `json:"session_id"`
+	TransactionID  string    `json:"transaction_id"`
+	PaymentLinkID  string    `json:"payment_link_id,omitempty"`
+	BankAmount     Money     `json:"bank_amount"`
+	BankFeeAmount  Money     `json:"bank_fee_amount"`
+	BankAmountRaw  string    `json:"bank_amount_raw"`
+	BankFeeRaw     string    `json:"bank_fee_raw,omitempty"`
+	BankStatus     string    `json:"bank_status"`
+	Currency       string    `json:"currency"`
+	SettledAt      time.Time `json:"settled_at"`
+	RecordedAmount Money     `json:"recorded_amount"`
+	RecordedFee    Money     `json:"recorded_fee"`
+	RecordedStatus string    `json:"recorded_status"`
+	MatchStatus    string    `json:"match_status"`
+	Mismatches     string    `json:"mismatches,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
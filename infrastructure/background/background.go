@@ -0,0 +1,36 @@
+// Package background tracks fire-and-forget goroutines, such as log writes to
+// Elasticsearch, so shutdown can wait for them instead of cutting them off.
+package background
+
+import (
+	"context"
+	"sync"
+)
+
+var tasks sync.WaitGroup
+
+// Go runs fn in a new goroutine that Wait will wait for.
+func Go(fn func()) {
+	tasks.Add(1)
+	go func() {
+		defer tasks.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until every goroutine started with Go has returned, or until
+// ctx is done.
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		tasks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
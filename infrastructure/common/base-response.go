@@ -3,20 +3,12 @@ package common
 import (
 	"net/http"
 
+	"payment-airpay/infrastructure/logger"
+
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
-type HttpResponse struct {
-	logger *zap.Logger
-}
-
-func NewHttpResponse(logger *zap.Logger) *HttpResponse {
-	return &HttpResponse{
-		logger: logger,
-	}
-}
-
 type MetaData struct {
 	Page      int `json:"page"`
 	TotalPage int `json:"total_pages"`
@@ -69,11 +61,26 @@ func SuccessResponse(c *fiber.Ctx, status int, message string, data interface{},
 	return c.Status(status).JSON(resp)
 }
 
-func ErrorResponse(c *fiber.Ctx, status int, message string, err error, request interface{}, trxId string) error {
+func SuccessResponseWithMeta(c *fiber.Ctx, status int, message string, data interface{}, meta MetaData, trxId string) error {
+	if message == "" {
+		message = http.StatusText(http.StatusOK)
+	}
 
-	// Logger usage would need a global logger or be passed in, or we skip logging in this static helper
-	// and rely on middleware or caller.
-	// For now, removing logger dependency from this static helper to match user request of simple call.
+	resp := BuildSuccessResponse(message, status, data, trxId)
+	resp.Meta = &meta
+
+	c.Locals("response", resp)
+
+	return c.Status(status).JSON(resp)
+}
+
+func ErrorResponse(c *fiber.Ctx, status int, message string, err error, request interface{}, trxId string) error {
+	if status >= http.StatusInternalServerError {
+		logger.For(c.UserContext(), zap.L()).Error(message,
+			zap.String("component", "http"),
+			zap.Int("status", status),
+			zap.Error(err))
+	}
 
 	resp := BuildErrorResponse(message, status, err, trxId)
 	c.Locals("response", resp)
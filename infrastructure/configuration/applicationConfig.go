@@ -1,18 +1,31 @@
 package configuration
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
 
-var AppConfig *appConfig
+	"github.com/spf13/viper"
+)
 
-type appConfig struct {
+var AppConfig *Config
+
+type Config struct {
 	ApplicationPort        int
 	ApplicationName        string
 	ServiceName            string
 	Environment            string
+	LogLevel               string // debug, info, warn or error
+	LogFormat              string // json or console; defaults to json in production
 	AcledaAPIURL           string
 	AcledaSTGURL           string
 	AcledaBaseURL          string
 	ACLEDAOPENSESSIONV2URL string
+	AcledaRefundURL        string
+	AcledaVoidURL          string
+	AcledaPaymentPageURL   string // Acleda page the hosted checkout posts to
 	AcledaUsername         string
 	AcledaPassword         string
 	AcledaAPIKey           string
@@ -31,38 +44,220 @@ type appConfig struct {
 	YugabytePassword       string
 	YugabyteDatabase       string
 	RabbitMQURI            string
+
+	// YugabyteDB connection security, pool and timeouts. Durations are in
+	// seconds except YugabyteStatementTimeout, which is in milliseconds; 0
+	// means no limit.
+	YugabyteSSLMode          string
+	YugabyteSSLRootCert      string
+	YugabyteMaxOpenConns     int
+	YugabyteMaxIdleConns     int
+	YugabyteConnMaxLifetime  int
+	YugabyteConnMaxIdleTime  int
+	YugabyteStatementTimeout int
+
+	// Reads that tolerate lag can be served by YugabyteReadHosts (replicas,
+	// tried in order) and/or by Yugabyte follower reads at most
+	// YugabyteFollowerReadStaleness old.
+	YugabyteReadHosts             string // comma separated host[:port] list
+	YugabyteFollowerReads         bool
+	YugabyteFollowerReadStaleness int // in milliseconds
+
+	AdminUsername string
+	AdminPassword string
+
+	AcledaSettlementDir          string
+	AcledaSettlementPollInterval int // in seconds
+
+	// How often the session outbox is checked for sessions without a
+	// payment link.
+	AcledaSessionReconcileInterval int // in seconds
+
+	// How often pending payment links are polled at the bank and expired
+	// ones swept.
+	AcledaStatusPollInterval int // in seconds
+
+	// Master keys for column encryption, as comma separated id:base64key
+	// entries and/or a file with one entry per line. New values are sealed
+	// with EncryptionActiveKeyID.
+	EncryptionMasterKeys     string
+	EncryptionMasterKeysFile string
+	EncryptionActiveKeyID    string
+
+	// Base64 AES-256 key that sealed Acleda credentials before the key ring
+	// existed. Only needed until rotate-keys has re-sealed them.
+	CredentialsEncryptionKey string
+
+	// HMAC keys for payment page links, as comma separated id:base64key
+	// entries. Links are signed with PaymentPageSigningKeyID.
+	PaymentPageSigningKeys  string
+	PaymentPageSigningKeyID string
+
+	// Trace exporter: none (default), stdout or otlp. OTLP is sent over HTTP
+	// to TracingEndpoint, e.g. http://localhost:4318 for a local collector.
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingSampleRatio float64
+
+	// HealthCheckAcleda adds a TCP reachability check of the Acleda host to
+	// the readiness report. It never makes the service not ready.
+	HealthCheckAcleda bool
+
+	// ShutdownTimeout bounds how long SIGTERM waits for in-flight requests,
+	// queued jobs and pending log writes, in seconds.
+	ShutdownTimeout int
+}
+
+// InitializeAppConfig loads the configuration into AppConfig. It returns
+// every problem found at once, so a broken deployment can be fixed in one go.
+func InitializeAppConfig() error {
+	cfg, err := LoadAppConfig()
+	if err != nil {
+		return err
+	}
+	AppConfig = cfg
+	return nil
+}
+
+// LoadAppConfig reads the configuration into a new Config without touching
+// the AppConfig global, so callers can build isolated configurations.
+//
+// Values are resolved in this order, later sources winning: the documented
+// defaults, .env, the profile file .env.<ENV>, the environment, and finally
+// <KEY>_FILE, which names a file holding the value (Docker and Kubernetes
+// secrets). Required keys depend on ENV; see settings.
+func LoadAppConfig() (*Config, error) {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	var errs []error
+	if err := readConfigFile(v, ".env", false); err != nil {
+		errs = append(errs, err)
+	}
+	profile := v.GetString("ENV")
+	if profile == "" {
+		profile = defaultEnvironment
+	}
+	if err := readConfigFile(v, ".env."+strings.ToLower(profile), true); err != nil {
+		errs = append(errs, err)
+	}
+
+	cfg := &Config{}
+	defs := settings(cfg)
+	declared := make(map[string]bool, len(defs))
+	for _, s := range defs {
+		declared[s.key] = true
+	}
+
+	// ENV decides what is required, so it is resolved before the others. A
+	// lookup error is reported by the loop below.
+	env, _ := lookup(v, "ENV", declared)
+	deployedEnv := isDeployed(env)
+
+	var missing []string
+	for _, s := range defs {
+		raw, err := lookup(v, s.key, declared)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if raw == "" {
+			if s.required == always || (s.required == deployed && deployedEnv) {
+				missing = append(missing, s.key)
+				continue
+			}
+			raw = s.def
+		}
+		if raw == "" {
+			continue
+		}
+		if err := s.parse(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.key, err))
+		}
+	}
+	if deployedEnv && cfg.EncryptionMasterKeys == "" && cfg.EncryptionMasterKeysFile == "" {
+		missing = append(missing, "ENCRYPTION_MASTER_KEYS or ENCRYPTION_MASTER_KEYS_FILE")
+	}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("missing required settings for environment %q: %s", cfg.Environment, strings.Join(missing, ", ")))
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// readConfigFile merges a dotenv file into v. A missing file is fine, since
+// production normally configures the service through the environment only.
+func readConfigFile(v *viper.Viper, path string, merge bool) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	v.SetConfigFile(path)
+	v.SetConfigType("env")
+	read := v.ReadInConfig
+	if merge {
+		read = v.MergeInConfig
+	}
+	if err := read(); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
+
+// lookup returns the raw value of key. <KEY>_FILE takes precedence when set,
+// unless <KEY>_FILE is a setting of its own such as ENCRYPTION_MASTER_KEYS_FILE.
+func lookup(v *viper.Viper, key string, declared map[string]bool) (string, error) {
+	fileKey := key + "_FILE"
+	if !declared[fileKey] {
+		if path := strings.TrimSpace(v.GetString(fileKey)); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", fileKey, err)
+			}
+			return strings.TrimRight(string(data), "\r\n"), nil
+		}
+	}
+	return strings.TrimSpace(v.GetString(key)), nil
+}
+
+func isDeployed(env string) bool {
+	switch strings.ToLower(env) {
+	case "stg", "staging", "prod", "production":
+		return true
+	}
+	return false
+}
+
+const redacted = "[REDACTED]"
+
+// Redacted returns the effective configuration keyed by setting name, for
+// logging at startup. Secrets are masked, and so are credentials embedded in
+// URLs. An unset secret stays empty so that it stands out.
+func (c *Config) Redacted() map[string]string {
+	defs := settings(c)
+	values := make(map[string]string, len(defs))
+	for _, s := range defs {
+		value := s.format()
+		switch {
+		case value == "":
+		case s.secret && !s.url:
+			value = redacted
+		case s.url:
+			value = redactURL(value)
+		}
+		values[s.key] = value
+	}
+	return values
 }
 
-func InitializeAppConfig() {
-	viper.SetConfigFile(".env")
-	viper.AutomaticEnv()
-	viper.ReadInConfig()
-
-	AppConfig = &appConfig{}
-	AppConfig.ApplicationPort = viper.GetInt("APP_PORT")
-	AppConfig.ApplicationName = viper.GetString("APP_NAME")
-	AppConfig.ServiceName = viper.GetString("SERVICE_NAME")
-	AppConfig.Environment = viper.GetString("ENV")
-	AppConfig.AcledaAPIURL = viper.GetString("ACLEDA_API_URL")
-	AppConfig.AcledaAPIKey = viper.GetString("ACLEDA_API_KEY")
-	AppConfig.AcledaMerchantID = viper.GetString("ACLEDA_MERCHANT_ID")
-	AppConfig.AcledaLogin = viper.GetString("ACLEDA_REMOTE_LOGIN")
-	AppConfig.AcledaRemotePassword = viper.GetString("ACLEDA_REMOTE_PASSWORD")
-	AppConfig.AcledaSecret = viper.GetString("ACLEDA_SECRET")
-	AppConfig.AcledaSTGURL = viper.GetString("ACLEDA_STG_URL")
-	AppConfig.AcledaBaseURL = viper.GetString("BASE_URL_ACLEDA")
-	AppConfig.ACLEDAOPENSESSIONV2URL = viper.GetString("ACLEDA_OPENSESSIONV2_URL")
-	AppConfig.AcledaPassword = viper.GetString("ACLEDA_PASSWORD")
-	AppConfig.AcledaUsername = viper.GetString("ACLEDA_USERNAME")
-	AppConfig.AcledaTimeout = viper.GetInt("ACLEDA_TIMEOUT")
-	AppConfig.RedisHost = viper.GetString("REDIS_HOST")
-	AppConfig.RedisPort = viper.GetInt("REDIS_PORT")
-	AppConfig.RedisPassword = viper.GetString("REDIS_PASSWORD")
-	AppConfig.RedisDatabase = viper.GetInt("REDIS_DATABASE")
-	AppConfig.YugabyteHost = viper.GetString("YUGABYTE_HOST")
-	AppConfig.YugabytePort = viper.GetInt("YUGABYTE_PORT")
-	AppConfig.YugabyteUsername = viper.GetString("YUGABYTE_USERNAME")
-	AppConfig.YugabytePassword = viper.GetString("YUGABYTE_PASSWORD")
-	AppConfig.YugabyteDatabase = viper.GetString("YUGABYTE_DATABASE")
-	AppConfig.RabbitMQURI = viper.GetString("RABBITMQ_URI")
+// redactURL masks the password of a URL, or the whole value when it does not
+// parse, since it may then hold anything.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
 }
@@ -0,0 +1,198 @@
+package configuration
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const defaultEnvironment = "development"
+
+// requirement says in which environments a setting must have a value.
+type requirement int
+
+const (
+	optional requirement = iota
+	// always must be set in every environment.
+	always
+	// deployed must be set in staging and production.
+	deployed
+)
+
+// setting describes one configuration key: where it is stored in Config, its
+// default, whether it is required, and whether its value may be printed.
+type setting struct {
+	key      string
+	def      string
+	required requirement
+	secret   bool
+	binding
+}
+
+// binding ties a setting to its Config field. parse converts and validates
+// the raw value and stores it; format returns the stored value as text.
+type binding struct {
+	parse  func(raw string) error
+	format func() string
+	url    bool
+}
+
+func stringVar(dst *string, allowed ...string) binding {
+	return binding{
+		parse: func(raw string) error {
+			if len(allowed) > 0 && !contains(allowed, strings.ToLower(raw)) {
+				return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+			}
+			*dst = raw
+			return nil
+		},
+		format: func() string { return *dst },
+	}
+}
+
+func urlVar(dst *string) binding {
+	return binding{
+		parse: func(raw string) error {
+			u, err := url.Parse(raw)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("must be an absolute URL")
+			}
+			*dst = raw
+			return nil
+		},
+		format: func() string { return *dst },
+		url:    true,
+	}
+}
+
+func intVar(dst *int, min int) binding {
+	return binding{
+		parse: func(raw string) error {
+			v, err := cast.ToIntE(raw)
+			if err != nil {
+				return fmt.Errorf("must be an integer")
+			}
+			if v < min {
+				return fmt.Errorf("must be at least %d", min)
+			}
+			*dst = v
+			return nil
+		},
+		format: func() string { return strconv.Itoa(*dst) },
+	}
+}
+
+func floatVar(dst *float64, min, max float64) binding {
+	return binding{
+		parse: func(raw string) error {
+			v, err := cast.ToFloat64E(raw)
+			if err != nil {
+				return fmt.Errorf("must be a number")
+			}
+			if v < min || v > max {
+				return fmt.Errorf("must be between %g and %g", min, max)
+			}
+			*dst = v
+			return nil
+		},
+		format: func() string { return strconv.FormatFloat(*dst, 'g', -1, 64) },
+	}
+}
+
+func boolVar(dst *bool) binding {
+	return binding{
+		parse: func(raw string) error {
+			v, err := cast.ToBoolE(raw)
+			if err != nil {
+				return fmt.Errorf("must be true or false")
+			}
+			*dst = v
+			return nil
+		},
+		format: func() string { return strconv.FormatBool(*dst) },
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// settings lists every key the service reads, bound to the fields of cfg.
+// Defaults here are the documented defaults.
+func settings(cfg *Config) []setting {
+	return []setting{
+		{key: "ENV", def: defaultEnvironment, binding: stringVar(&cfg.Environment)},
+		{key: "APP_PORT", def: "8080", binding: intVar(&cfg.ApplicationPort, 1)},
+		{key: "APP_NAME", binding: stringVar(&cfg.ApplicationName)},
+		{key: "SERVICE_NAME", def: "payment-airpay", binding: stringVar(&cfg.ServiceName)},
+		{key: "LOG_LEVEL", def: "info", binding: stringVar(&cfg.LogLevel, "debug", "info", "warn", "error")},
+		{key: "LOG_FORMAT", binding: stringVar(&cfg.LogFormat, "json", "console")},
+
+		{key: "ACLEDA_API_URL", binding: urlVar(&cfg.AcledaAPIURL)},
+		{key: "ACLEDA_STG_URL", binding: urlVar(&cfg.AcledaSTGURL)},
+		{key: "BASE_URL_ACLEDA", required: deployed, binding: urlVar(&cfg.AcledaBaseURL)},
+		{key: "ACLEDA_OPENSESSIONV2_URL", required: deployed, binding: urlVar(&cfg.ACLEDAOPENSESSIONV2URL)},
+		{key: "ACLEDA_REFUND_URL", binding: urlVar(&cfg.AcledaRefundURL)},
+		{key: "ACLEDA_VOID_URL", binding: urlVar(&cfg.AcledaVoidURL)},
+		{key: "ACLEDA_PAYMENT_PAGE_URL", def: "https://epaymentuat.acledabank.com.kh/LINKIT360SOLUTION/paymentPage.jsp", binding: urlVar(&cfg.AcledaPaymentPageURL)},
+		{key: "ACLEDA_USERNAME", binding: stringVar(&cfg.AcledaUsername)},
+		{key: "ACLEDA_PASSWORD", secret: true, binding: stringVar(&cfg.AcledaPassword)},
+		{key: "ACLEDA_API_KEY", secret: true, binding: stringVar(&cfg.AcledaAPIKey)},
+		{key: "ACLEDA_MERCHANT_ID", required: deployed, binding: stringVar(&cfg.AcledaMerchantID)},
+		{key: "ACLEDA_REMOTE_LOGIN", required: deployed, binding: stringVar(&cfg.AcledaLogin)},
+		{key: "ACLEDA_REMOTE_PASSWORD", required: deployed, secret: true, binding: stringVar(&cfg.AcledaRemotePassword)},
+		{key: "ACLEDA_SECRET", required: deployed, secret: true, binding: stringVar(&cfg.AcledaSecret)},
+		{key: "ACLEDA_TIMEOUT", def: "30000", binding: intVar(&cfg.AcledaTimeout, 1)},
+
+		{key: "REDIS_HOST", binding: stringVar(&cfg.RedisHost)},
+		{key: "REDIS_PORT", def: "6379", binding: intVar(&cfg.RedisPort, 1)},
+		{key: "REDIS_PASSWORD", secret: true, binding: stringVar(&cfg.RedisPassword)},
+		{key: "REDIS_DATABASE", def: "0", binding: intVar(&cfg.RedisDatabase, 0)},
+
+		{key: "YUGABYTE_HOST", required: always, binding: stringVar(&cfg.YugabyteHost)},
+		{key: "YUGABYTE_PORT", def: "5433", binding: intVar(&cfg.YugabytePort, 1)},
+		{key: "YUGABYTE_USERNAME", required: always, binding: stringVar(&cfg.YugabyteUsername)},
+		{key: "YUGABYTE_PASSWORD", required: deployed, secret: true, binding: stringVar(&cfg.YugabytePassword)},
+		{key: "YUGABYTE_DATABASE", required: always, binding: stringVar(&cfg.YugabyteDatabase)},
+		{key: "YUGABYTE_SSLMODE", def: "disable", binding: stringVar(&cfg.YugabyteSSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")},
+		{key: "YUGABYTE_SSLROOTCERT", binding: stringVar(&cfg.YugabyteSSLRootCert)},
+		{key: "YUGABYTE_MAX_OPEN_CONNS", def: "25", binding: intVar(&cfg.YugabyteMaxOpenConns, 1)},
+		{key: "YUGABYTE_MAX_IDLE_CONNS", def: "10", binding: intVar(&cfg.YugabyteMaxIdleConns, 0)},
+		{key: "YUGABYTE_CONN_MAX_LIFETIME", def: "1800", binding: intVar(&cfg.YugabyteConnMaxLifetime, 0)},
+		{key: "YUGABYTE_CONN_MAX_IDLE_TIME", def: "300", binding: intVar(&cfg.YugabyteConnMaxIdleTime, 0)},
+		{key: "YUGABYTE_STATEMENT_TIMEOUT", def: "30000", binding: intVar(&cfg.YugabyteStatementTimeout, 0)},
+		{key: "YUGABYTE_READ_HOSTS", binding: stringVar(&cfg.YugabyteReadHosts)},
+		{key: "YUGABYTE_FOLLOWER_READS", def: "false", binding: boolVar(&cfg.YugabyteFollowerReads)},
+		{key: "YUGABYTE_FOLLOWER_READ_STALENESS", def: "5000", binding: intVar(&cfg.YugabyteFollowerReadStaleness, 1)},
+		{key: "RABBITMQ_URI", required: always, secret: true, binding: urlVar(&cfg.RabbitMQURI)},
+
+		{key: "ADMIN_USERNAME", required: deployed, binding: stringVar(&cfg.AdminUsername)},
+		{key: "ADMIN_PASSWORD", required: deployed, secret: true, binding: stringVar(&cfg.AdminPassword)},
+
+		{key: "ACLEDA_SETTLEMENT_DIR", binding: stringVar(&cfg.AcledaSettlementDir)},
+		{key: "ACLEDA_SETTLEMENT_POLL_INTERVAL", def: "300", binding: intVar(&cfg.AcledaSettlementPollInterval, 1)},
+		{key: "ACLEDA_SESSION_RECONCILE_INTERVAL", def: "60", binding: intVar(&cfg.AcledaSessionReconcileInterval, 1)},
+		{key: "ACLEDA_STATUS_POLL_INTERVAL", def: "60", binding: intVar(&cfg.AcledaStatusPollInterval, 1)},
+
+		{key: "ENCRYPTION_MASTER_KEYS", secret: true, binding: stringVar(&cfg.EncryptionMasterKeys)},
+		{key: "ENCRYPTION_MASTER_KEYS_FILE", binding: stringVar(&cfg.EncryptionMasterKeysFile)},
+		{key: "ENCRYPTION_ACTIVE_KEY_ID", binding: stringVar(&cfg.EncryptionActiveKeyID)},
+		{key: "CREDENTIALS_ENCRYPTION_KEY", secret: true, binding: stringVar(&cfg.CredentialsEncryptionKey)},
+		{key: "PAYMENT_PAGE_SIGNING_KEYS", required: deployed, secret: true, binding: stringVar(&cfg.PaymentPageSigningKeys)},
+		{key: "PAYMENT_PAGE_SIGNING_KEY_ID", binding: stringVar(&cfg.PaymentPageSigningKeyID)},
+
+		{key: "TRACING_EXPORTER", def: "none", binding: stringVar(&cfg.TracingExporter, "none", "stdout", "otlp")},
+		{key: "OTEL_EXPORTER_OTLP_ENDPOINT", binding: urlVar(&cfg.TracingEndpoint)},
+		{key: "TRACING_SAMPLE_RATIO", def: "1", binding: floatVar(&cfg.TracingSampleRatio, 0, 1)},
+		{key: "HEALTH_CHECK_ACLEDA", def: "false", binding: boolVar(&cfg.HealthCheckAcleda)},
+		{key: "SHUTDOWN_TIMEOUT", def: "30", binding: intVar(&cfg.ShutdownTimeout, 1)},
+	}
+}
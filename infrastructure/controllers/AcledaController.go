@@ -1,32 +1,48 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"payment-airpay/application/services"
 	"payment-airpay/domain/entities"
 	"payment-airpay/infrastructure/common"
 	"payment-airpay/infrastructure/configuration"
-	"payment-airpay/infrastructure/database/repositories"
-	"payment-airpay/infrastructure/gateway/acleda"
-	"payment-airpay/infrastructure/service"
+	"payment-airpay/infrastructure/crypto"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/logger"
+	"payment-airpay/infrastructure/views"
 
-	"github.com/go-resty/resty/v2"
 	"github.com/gofiber/fiber/v2"
 )
 
 type AcledaController struct {
 	paymentLinkService *services.CreateAcledaPaymentLinkService
+	refundService      *services.CreateAcledaRefundService
+	listService        *services.ListAcledaPaymentsService
+	statusService      *services.UpdateAcledaPaymentStatusService
+	signer             *crypto.URLSigner
+	cfg                *configuration.Config
 }
 
 func NewAcledaController(
-	gateway *acleda.AcledaGateway,
-	service *service.PaymentAcleda,
-	repo *repositories.PaymentAcledaRepositoryYugabyteDB,
-	client *resty.Client,
+	paymentLinkService *services.CreateAcledaPaymentLinkService,
+	refundService *services.CreateAcledaRefundService,
+	listService *services.ListAcledaPaymentsService,
+	statusService *services.UpdateAcledaPaymentStatusService,
+	signer *crypto.URLSigner,
+	cfg *configuration.Config,
 ) *AcledaController {
 	return &AcledaController{
-		paymentLinkService: services.NewCreateAcledaPaymentLinkService(gateway, service, repo, client),
+		paymentLinkService: paymentLinkService,
+		refundService:      refundService,
+		listService:        listService,
+		statusService:      statusService,
+		signer:             signer,
+		cfg:                cfg,
 	}
 }
 
@@ -44,49 +60,199 @@ func (c *AcledaController) CreatePaymentLink(ctx *fiber.Ctx) error {
 	}
 
 	// Create payment link
-	result, err := c.paymentLinkService.Execute(ctx.Context(), req, *incoming)
+	result, err := c.paymentLinkService.Execute(ctx.UserContext(), req, *incoming)
 	if err != nil {
+		if errors.Is(err, entities.ErrInvalidAmount) || errors.Is(err, entities.ErrUnsupportedCurrency) ||
+			errors.Is(err, entities.ErrCurrencyNotAllowed) || errors.Is(err, entities.ErrAmountOutOfRange) {
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, incoming.TransactionID)
+		}
 		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create payment link", err, req, incoming.TransactionID)
 	}
 
 	return common.SuccessResponse(ctx, http.StatusOK, "Payment link created successfully", result, incoming.TransactionID)
 }
 
-// PaymentPage shows the Acleda payment page
+// checkoutZone is the time zone expiry times are shown in (Cambodia, UTC+7).
+var checkoutZone = time.FixedZone("ICT", 7*60*60)
+
+// PaymentPage shows the hosted checkout: an order summary, the time left to
+// pay and a form that posts to Acleda's payment page. It works without
+// JavaScript and is served in English or Khmer. The URL must carry the token
+// signed when the link was created; the session posted to Acleda is always
+// the stored one, re-opened first if the bank has expired it.
 func (c *AcledaController) PaymentPage(ctx *fiber.Ctx) error {
 	transactionID := ctx.Params("id")
-	sessionID := ctx.Query("sid")
-	ptID := ctx.Query("ptid")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
 
 	if transactionID == "" {
 		return common.ErrorResponse(ctx, http.StatusBadRequest, "Transaction ID is required", nil, nil, "")
 	}
 
+	// An expired token still shows the page, which then explains that the
+	// link has expired.
+	_, err := c.signer.Verify(ctx.Query("token"), transactionID, time.Now())
+	if err != nil && !errors.Is(err, crypto.ErrTokenExpired) {
+		return common.ErrorResponse(ctx, http.StatusForbidden, "Invalid payment link", err, nil, transactionID)
+	}
+	tokenExpired := err != nil
+
 	// Get payment link data
-	paymentLink, err := c.paymentLinkService.GetByTransactionID(ctx.Context(), transactionID)
-	if err != nil {
+	paymentLink, err := c.paymentLinkService.GetByTransactionID(ctx.UserContext(), transactionID)
+	if err != nil || paymentLink == nil {
 		return common.ErrorResponse(ctx, http.StatusNotFound, "Payment link not found", err, nil, transactionID)
 	}
 
-	// Render HTML template
-	return ctx.Render("payment-page-acleda", fiber.Map{
-		"sid":          sessionID,
-		"data":         paymentLink,
-		"merchant_id":  configuration.AppConfig.AcledaMerchantID,
-		"ptid":         ptID,
-		"desc":         paymentLink.Description,
-		"amount":       paymentLink.Amount,
+	lang := checkoutLanguage(ctx)
+	text := checkoutTexts[lang]
+
+	// The bank session can lapse before the link does; open a new one so the
+	// customer can still pay.
+	sessionUnavailable := false
+	if !tokenExpired {
+		incoming := ctx.Locals("incoming").(*entities.Incoming)
+		refreshed, err := c.paymentLinkService.RefreshSession(ctx.UserContext(), paymentLink, *incoming)
+		switch {
+		case err != nil:
+			sessionUnavailable = true
+		case refreshed != nil:
+			paymentLink = refreshed
+		}
+	}
+
+	acledaMerchantID := paymentLink.AcledaMerchantID
+	if acledaMerchantID == "" {
+		acledaMerchantID = c.cfg.AcledaMerchantID
+	}
+
+	data := fiber.Map{
+		"lang":         lang,
+		"t":            text,
+		"languages":    checkoutLanguageLinks(ctx, lang),
+		"static":       views.StaticPrefix,
+		"action":       c.cfg.AcledaPaymentPageURL,
+		"sid":          paymentLink.SessionID,
+		"merchant_id":  acledaMerchantID,
+		"ptid":         paymentLink.PaymentTokenID,
+		"description":  paymentLink.Description,
+		"amount":       paymentLink.Amount.String(),
 		"invoice_id":   paymentLink.InvoiceID,
-		"return_url":   paymentLink.ReturnURL,
-		"error_url":    paymentLink.ErrorURL,
+		"return_url":   c.returnURL(paymentLink.TransactionID, "success"),
+		"error_url":    c.returnURL(paymentLink.TransactionID, "error"),
 		"currency":     paymentLink.Currency,
-		"expired_time": paymentLink.ExpiryTime,
+		"expired_time": paymentLink.SessionExpiryTime,
+	}
+
+	expiresAt := paymentLink.ExpiresAt()
+	data["expires_at"] = expiresAt.UTC().Format(time.RFC3339)
+	data["expires_at_local"] = expiresAt.In(checkoutZone).Format("2006-01-02 15:04") + " (UTC+7)"
+	expired := tokenExpired || !time.Now().Before(expiresAt)
+
+	// Only a pending link that has not expired can be paid.
+	status := http.StatusOK
+	switch {
+	case paymentLink.Status == entities.PaymentLinkStatusPaid || paymentLink.Status == entities.PaymentLinkStatusRefunded:
+		data["notice"] = text.Paid
+		status = http.StatusGone
+	case paymentLink.Status == entities.PaymentLinkStatusExpired || (paymentLink.Status == entities.PaymentLinkStatusPending && expired):
+		data["notice"] = text.Expired
+		status = http.StatusGone
+	case paymentLink.Status != entities.PaymentLinkStatusPending:
+		data["notice"] = text.Unavailable
+		status = http.StatusGone
+	case sessionUnavailable:
+		data["notice"] = text.Retry
+		status = http.StatusServiceUnavailable
+		ctx.Set(fiber.HeaderRetryAfter, "30")
+	}
+
+	setCheckoutHeaders(ctx, c.cfg.AcledaPaymentPageURL)
+	return ctx.Status(status).Render("payment-page-acleda", data)
+}
+
+// setCheckoutHeaders locks the checkout down to its own assets. The only
+// place it may send data is Acleda's payment page.
+func setCheckoutHeaders(ctx *fiber.Ctx, action string) {
+	formAction := "'self'"
+	if u, err := url.Parse(action); err == nil && u.Scheme != "" && u.Host != "" {
+		formAction = u.Scheme + "://" + u.Host
+	}
+	ctx.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self'; "+
+		"form-action "+formAction+"; base-uri 'none'; frame-ancestors 'none'")
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	ctx.Set(fiber.HeaderXFrameOptions, "DENY")
+	ctx.Set(fiber.HeaderReferrerPolicy, "no-referrer")
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Vary(fiber.HeaderAcceptLanguage)
+}
+
+// ListPayments searches the authenticated merchant's payments
+func (c *AcledaController) ListPayments(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+
+	var req services.ListAcledaPaymentsInput
+	if err := ctx.QueryParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err, nil, "")
+	}
+
+	result, err := c.listService.Execute(clients.AllowStaleReads(ctx.UserContext()), incoming.Merchant, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPaymentFilter) {
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, "")
+		}
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list payments", err, req, "")
+	}
+
+	return common.SuccessResponseWithMeta(ctx, http.StatusOK, "", result.Items, common.MetaData{
+		Page:      result.Page,
+		TotalPage: result.TotalPages,
+		TotalRows: result.TotalRows,
+		Limit:     result.Limit,
+	}, "")
+}
+
+// returnURL is where Acleda sends the customer back to, so that the link is
+// settled before the customer reaches the merchant.
+func (c *AcledaController) returnURL(transactionID, result string) string {
+	return fmt.Sprintf("%s/payment-page/acleda/%s/return?result=%s", c.cfg.AcledaBaseURL, url.PathEscape(transactionID), result)
+}
+
+// PaymentReturn is where Acleda sends the customer after paying, or failing
+// to. The link is settled with the status the bank reports, then the
+// customer continues to the merchant's return or error URL.
+func (c *AcledaController) PaymentReturn(ctx *fiber.Ctx) error {
+	transactionID := ctx.Params("id")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
+
+	callback := ctx.Queries()
+	ctx.Request().PostArgs().VisitAll(func(key, value []byte) {
+		callback[string(key)] = string(value)
 	})
+
+	// A failed status query is logged by the service; the poller settles the
+	// link later, and the merchant polls for the outcome.
+	paymentLink, _ := c.statusService.HandleCallback(ctx.UserContext(), transactionID, callback)
+	if paymentLink == nil {
+		return ctx.Status(http.StatusNotFound).SendString("Payment link not found")
+	}
+
+	target := paymentLink.ReturnURL
+	switch paymentLink.Status {
+	case entities.PaymentLinkStatusPaid:
+	case entities.PaymentLinkStatusFailed, entities.PaymentLinkStatusCancelled, entities.PaymentLinkStatusExpired:
+		target = paymentLink.ErrorURL
+	default:
+		if ctx.Query("result") == "error" {
+			target = paymentLink.ErrorURL
+		}
+	}
+	return ctx.Redirect(target, http.StatusSeeOther)
 }
 
-// GetPaymentStatus retrieves payment status
+// GetPaymentStatus retrieves the status of one of the merchant's payments
 func (c *AcledaController) GetPaymentStatus(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
 	transactionID := ctx.Params("id")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
 
 	if transactionID == "" {
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
@@ -94,16 +260,86 @@ func (c *AcledaController) GetPaymentStatus(ctx *fiber.Ctx) error {
 		})
 	}
 
-	paymentLink, err := c.paymentLinkService.GetByTransactionID(ctx.Context(), transactionID)
+	// Polling tolerates a status that lags by a few seconds.
+	status, err := c.paymentLinkService.GetStatus(clients.AllowStaleReads(ctx.UserContext()), incoming.Merchant, transactionID)
 	if err != nil {
 		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
-			"error":   "Payment link not found",
-			"details": err.Error(),
+			"error": "Payment link not found",
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    paymentLink,
+		"data":    status,
 	})
 }
+
+// ListStatusHistory returns the status changes of one of the merchant's payments
+func (c *AcledaController) ListStatusHistory(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	transactionID := ctx.Params("id")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
+
+	history, err := c.statusService.ListHistory(clients.AllowStaleReads(ctx.UserContext()), incoming.Merchant, transactionID)
+	if err != nil {
+		if errors.Is(err, services.ErrPaymentLinkNotFound) {
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Payment link not found", err, nil, transactionID)
+		}
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list status history", err, nil, transactionID)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", history, transactionID)
+}
+
+// CreateRefund issues a full or partial refund, or a void, for a paid payment
+func (c *AcledaController) CreateRefund(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	incoming.Save = true
+	transactionID := ctx.Params("id")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
+	incoming.TransactionID = transactionID
+
+	var req services.CreateAcledaRefundInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, req, transactionID)
+	}
+
+	result, err := c.refundService.Execute(ctx.UserContext(), transactionID, req, *incoming)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPaymentLinkNotFound):
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Payment link not found", err, req, transactionID)
+		case errors.Is(err, services.ErrInvalidRefundType), errors.Is(err, services.ErrInvalidRefundAmount):
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, transactionID)
+		case errors.Is(err, services.ErrPaymentNotRefundable), errors.Is(err, entities.ErrRefundExceedsCaptured):
+			return common.ErrorResponse(ctx, http.StatusConflict, "Refund rejected", err, req, transactionID)
+		default:
+			return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to refund payment", err, req, transactionID)
+		}
+	}
+
+	if result.Status == entities.RefundStatusPending {
+		return common.SuccessResponse(ctx, http.StatusAccepted, "Refund submitted; the bank's answer is pending", result, transactionID)
+	}
+	return common.SuccessResponse(ctx, http.StatusOK, "Refund processed successfully", result, transactionID)
+}
+
+// ListRefunds returns the refund ledger for a payment
+func (c *AcledaController) ListRefunds(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	transactionID := ctx.Params("id")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
+	readCtx := clients.AllowStaleReads(ctx.UserContext())
+
+	paymentLink, err := c.paymentLinkService.GetByTransactionID(readCtx, transactionID)
+	if err != nil || paymentLink == nil || paymentLink.MerchantID != incoming.Merchant {
+		return common.ErrorResponse(ctx, http.StatusNotFound, "Payment link not found", services.ErrPaymentLinkNotFound, nil, transactionID)
+	}
+
+	refunds, err := c.refundService.ListByTransactionID(readCtx, transactionID)
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list refunds", err, nil, transactionID)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", refunds, transactionID)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/common"
+	"payment-airpay/infrastructure/crypto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AcledaCredentialController struct {
+	credentialService *services.ManageAcledaCredentialsService
+}
+
+func NewAcledaCredentialController(credentialService *services.ManageAcledaCredentialsService) *AcledaCredentialController {
+	return &AcledaCredentialController{
+		credentialService: credentialService,
+	}
+}
+
+// GetCredentials shows a merchant's Acleda account without its secrets
+func (c *AcledaCredentialController) GetCredentials(ctx *fiber.Ctx) error {
+	result, err := c.credentialService.Get(ctx.UserContext(), ctx.Params("code"))
+	if err != nil {
+		if errors.Is(err, entities.ErrAcledaCredentialNotFound) {
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Acleda credentials not configured", err, nil, "")
+		}
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to load Acleda credentials", err, nil, "")
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", result, "")
+}
+
+// SetCredentials creates or replaces a merchant's Acleda account. The request
+// body holds secrets, so it is neither saved with the request log nor echoed
+// back in error responses.
+func (c *AcledaCredentialController) SetCredentials(ctx *fiber.Ctx) error {
+	var req services.SetAcledaCredentialsInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, nil, "")
+	}
+
+	result, err := c.credentialService.Set(ctx.UserContext(), ctx.Params("code"), req, auditActor(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAcledaCredential):
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, nil, "")
+		case errors.Is(err, services.ErrMerchantNotFound):
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Merchant not found", err, nil, "")
+		case errors.Is(err, crypto.ErrKeyNotConfigured):
+			return common.ErrorResponse(ctx, http.StatusServiceUnavailable, "Credential encryption is not configured", err, nil, "")
+		default:
+			return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save Acleda credentials", err, nil, "")
+		}
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Acleda credentials updated", result, "")
+}
@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/logger"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -73,7 +76,15 @@ func (c *AcledaStagingController) CreateStagingPayment(ctx *fiber.Ctx) error {
 	}
 
 	// Create staging payment
-	result, err := c.stagingService.Execute(ctx.Context(), &input)
+	result, err := c.stagingService.Execute(ctx.UserContext(), &input)
+	if errors.Is(err, entities.ErrInvalidAmount) || errors.Is(err, entities.ErrUnsupportedCurrency) {
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   true,
+			"message": "Invalid amount",
+			"details": err.Error(),
+		})
+	}
 	if err != nil {
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -95,6 +106,7 @@ func (c *AcledaStagingController) CreateStagingPayment(ctx *fiber.Ctx) error {
 // GetStagingPaymentStatus retrieves staging payment status
 func (c *AcledaStagingController) GetStagingPaymentStatus(ctx *fiber.Ctx) error {
 	transactionID := ctx.Params("id")
+	logger.SetTransactionID(ctx.UserContext(), transactionID)
 
 	if transactionID == "" {
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
@@ -105,7 +117,7 @@ func (c *AcledaStagingController) GetStagingPaymentStatus(ctx *fiber.Ctx) error
 	}
 
 	// Get payment status
-	paymentLink, err := c.stagingService.GetByTransactionID(ctx.Context(), transactionID)
+	paymentLink, err := c.stagingService.GetByTransactionID(ctx.UserContext(), transactionID)
 	if err != nil {
 		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
 			"success": false,
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultCheckoutLanguage = "en"
+
+// checkoutText holds the strings of the hosted payment page in one language.
+type checkoutText struct {
+	Name        string
+	Title       string
+	Summary     string
+	Description string
+	Invoice     string
+	Amount      string
+	ExpiresAt   string
+	ExpiresIn   string
+	Expired     string
+	Pay         string
+	Redirect    string
+	Paid        string
+	Unavailable string
+	Retry       string
+}
+
+// checkoutLanguages lists the supported languages, the default first.
+var checkoutLanguages = []string{"en", "km"}
+
+var checkoutTexts = map[string]checkoutText{
+	"en": {
+		Name:        "English",
+		Title:       "Checkout",
+		Summary:     "Order summary",
+		Description: "Description",
+		Invoice:     "Invoice",
+		Amount:      "Amount",
+		ExpiresAt:   "Pay before",
+		ExpiresIn:   "Time left",
+		Expired:     "This payment link has expired.",
+		Pay:         "Pay with ACLEDA",
+		Redirect:    "You will be taken to ACLEDA Bank's secure payment page.",
+		Paid:        "This payment has already been completed.",
+		Unavailable: "This payment link is no longer available.",
+		Retry:       "Payment is temporarily unavailable. Please reload this page in a moment.",
+	},
+	"km": {
+		Name:        "ភាសាខ្មែរ",
+		Title:       "ការទូទាត់",
+		Summary:     "សេចក្តីសង្ខេបការបញ្ជាទិញ",
+		Description: "ការពិពណ៌នា",
+		Invoice:     "វិក្កយបត្រ",
+		Amount:      "ចំនួនទឹកប្រាក់",
+		ExpiresAt:   "សូមទូទាត់មុន",
+		ExpiresIn:   "ពេលវេលានៅសល់",
+		Expired:     "តំណទូទាត់នេះបានផុតកំណត់ហើយ។",
+		Pay:         "ទូទាត់តាម ACLEDA",
+		Redirect:    "អ្នកនឹងត្រូវបានបញ្ជូនទៅកាន់ទំព័រទូទាត់សុវត្ថិភាពរបស់ធនាគារ ACLEDA។",
+		Paid:        "ការទូទាត់នេះត្រូវបានបញ្ចប់រួចហើយ។",
+		Unavailable: "តំណទូទាត់នេះលែងអាចប្រើបានទៀតហើយ។",
+		Retry:       "ការទូទាត់មិនអាចប្រើបានបណ្ដោះអាសន្ន។ សូមផ្ទុកទំព័រនេះឡើងវិញបន្តិចទៀត។",
+	},
+}
+
+// checkoutLanguage picks the page language: the lang query parameter if it
+// is supported, then Accept-Language, then English.
+func checkoutLanguage(ctx *fiber.Ctx) string {
+	if lang := ctx.Query("lang"); lang != "" {
+		if _, ok := checkoutTexts[lang]; ok {
+			return lang
+		}
+	}
+	if lang := ctx.AcceptsLanguages(checkoutLanguages...); lang != "" {
+		return lang
+	}
+	return defaultCheckoutLanguage
+}
+
+type checkoutLanguageLink struct {
+	Code    string
+	Name    string
+	URL     string
+	Current bool
+}
+
+// checkoutLanguageLinks returns a link to the current page in every
+// supported language, keeping the other query parameters.
+func checkoutLanguageLinks(ctx *fiber.Ctx, current string) []checkoutLanguageLink {
+	query, _ := url.ParseQuery(string(ctx.Request().URI().QueryString()))
+
+	links := make([]checkoutLanguageLink, 0, len(checkoutLanguages))
+	for _, code := range checkoutLanguages {
+		query.Set("lang", code)
+		links = append(links, checkoutLanguageLink{
+			Code:    code,
+			Name:    checkoutTexts[code].Name,
+			URL:     ctx.Path() + "?" + query.Encode(),
+			Current: code == current,
+		})
+	}
+	return links
+}
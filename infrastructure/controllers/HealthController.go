@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+
+	"payment-airpay/infrastructure/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthController struct {
+	checker *health.Checker
+}
+
+func NewHealthController(checker *health.Checker) *HealthController {
+	return &HealthController{
+		checker: checker,
+	}
+}
+
+// Liveness reports that the process is running and serving requests
+func (c *HealthController) Liveness(ctx *fiber.Ctx) error {
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"status": health.StatusUp})
+}
+
+// Readiness checks the dependencies and answers 503 when a critical one is down
+func (c *HealthController) Readiness(ctx *fiber.Ctx) error {
+	report := c.checker.Run(ctx.UserContext())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	return ctx.Status(status).JSON(report)
+}
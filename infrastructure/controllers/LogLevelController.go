@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"net/http"
+
+	"payment-airpay/infrastructure/common"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogLevelController reads and changes the log level of the running process.
+type LogLevelController struct {
+	level zap.AtomicLevel
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type logLevelResponse struct {
+	Level string `json:"level"`
+}
+
+func NewLogLevelController(level zap.AtomicLevel) *LogLevelController {
+	return &LogLevelController{
+		level: level,
+	}
+}
+
+// GetLevel returns the current log level
+func (c *LogLevelController) GetLevel(ctx *fiber.Ctx) error {
+	return common.SuccessResponse(ctx, http.StatusOK, "", logLevelResponse{Level: c.level.String()}, "")
+}
+
+// SetLevel changes the log level without a restart
+func (c *LogLevelController) SetLevel(ctx *fiber.Ctx) error {
+	var req logLevelRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, nil, "")
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid log level", err, req, "")
+	}
+
+	previous := c.level.Level()
+	c.level.SetLevel(level)
+	zap.L().Info("Log level changed",
+		zap.String("component", "http"),
+		zap.Stringer("from", previous),
+		zap.Stringer("to", level),
+	)
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Log level updated", logLevelResponse{Level: level.String()}, "")
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/common"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MasterDataController struct {
+	masterDataService *services.ManageMasterDataService
+}
+
+func NewMasterDataController(masterDataService *services.ManageMasterDataService) *MasterDataController {
+	return &MasterDataController{
+		masterDataService: masterDataService,
+	}
+}
+
+// List lists master data records of one kind
+func (c *MasterDataController) List(ctx *fiber.Ctx) error {
+	var req services.ListMasterDataInput
+	if err := ctx.QueryParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err, nil, "")
+	}
+
+	result, err := c.masterDataService.List(ctx.UserContext(), ctx.Params("kind"), req)
+	if err != nil {
+		return masterDataError(ctx, err, nil)
+	}
+
+	return common.SuccessResponseWithMeta(ctx, http.StatusOK, "", result.Items, common.MetaData{
+		Page:      result.Page,
+		TotalPage: result.TotalPages,
+		TotalRows: int(result.TotalRows),
+		Limit:     result.Limit,
+	}, "")
+}
+
+// Get returns one master data record
+func (c *MasterDataController) Get(ctx *fiber.Ctx) error {
+	record, err := c.masterDataService.Get(ctx.UserContext(), ctx.Params("kind"), ctx.Params("id"))
+	if err != nil {
+		return masterDataError(ctx, err, nil)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", record, "")
+}
+
+// Create adds a master data record
+func (c *MasterDataController) Create(ctx *fiber.Ctx) error {
+	var req services.MasterDataInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, req, "")
+	}
+
+	record, err := c.masterDataService.Create(ctx.UserContext(), ctx.Params("kind"), req, auditActor(ctx))
+	if err != nil {
+		return masterDataError(ctx, err, req)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusCreated, "Record created", record, "")
+}
+
+// Update changes a master data record
+func (c *MasterDataController) Update(ctx *fiber.Ctx) error {
+	var req services.MasterDataInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, req, "")
+	}
+
+	record, err := c.masterDataService.Update(ctx.UserContext(), ctx.Params("kind"), ctx.Params("id"), req, auditActor(ctx))
+	if err != nil {
+		return masterDataError(ctx, err, req)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Record updated", record, "")
+}
+
+// Delete soft-deletes a master data record
+func (c *MasterDataController) Delete(ctx *fiber.Ctx) error {
+	if err := c.masterDataService.Delete(ctx.UserContext(), ctx.Params("kind"), ctx.Params("id"), auditActor(ctx)); err != nil {
+		return masterDataError(ctx, err, nil)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Record deleted", nil, "")
+}
+
+// auditActor identifies the admin making the request for the audit columns.
+func auditActor(ctx *fiber.Ctx) entities.AuditActor {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	incoming.Save = true
+	admin, _ := ctx.Locals("admin").(string)
+	return entities.AuditActor{User: admin, IP: incoming.IP}
+}
+
+func masterDataError(ctx *fiber.Ctx, err error, req interface{}) error {
+	switch {
+	case errors.Is(err, entities.ErrUnknownMasterDataKind), errors.Is(err, entities.ErrMasterDataNotFound):
+		return common.ErrorResponse(ctx, http.StatusNotFound, "Not found", err, req, "")
+	case errors.Is(err, entities.ErrMasterDataConflict):
+		return common.ErrorResponse(ctx, http.StatusConflict, "Record already exists", err, req, "")
+	case errors.Is(err, entities.ErrInvalidMasterData):
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, "")
+	default:
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to process master data", err, req, "")
+	}
+}
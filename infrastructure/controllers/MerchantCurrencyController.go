@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/common"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MerchantCurrencyController struct {
+	merchantCurrencyService *services.MerchantCurrencyService
+}
+
+func NewMerchantCurrencyController(merchantCurrencyService *services.MerchantCurrencyService) *MerchantCurrencyController {
+	return &MerchantCurrencyController{
+		merchantCurrencyService: merchantCurrencyService,
+	}
+}
+
+// GetCurrencies returns the currencies a merchant is allowed to charge in
+func (c *MerchantCurrencyController) GetCurrencies(ctx *fiber.Ctx) error {
+	result, err := c.merchantCurrencyService.ListAllowed(ctx.UserContext(), ctx.Params("code"))
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list merchant currencies", err, nil, "")
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", result, "")
+}
+
+// SetCurrencies replaces the currencies a merchant is allowed to charge in
+func (c *MerchantCurrencyController) SetCurrencies(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	incoming.Save = true
+	admin, _ := ctx.Locals("admin").(string)
+
+	var req services.SetMerchantCurrenciesInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, req, "")
+	}
+
+	result, err := c.merchantCurrencyService.SetAllowed(ctx.UserContext(), ctx.Params("code"), req, admin, incoming.IP)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMerchantNotFound):
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Merchant not found", err, req, "")
+		case errors.Is(err, entities.ErrUnsupportedCurrency):
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, "")
+		default:
+			return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save merchant currencies", err, req, "")
+		}
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Merchant currencies updated", result, "")
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/common"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PaymentAdminController serves back-office operations on payments.
+type PaymentAdminController struct {
+	refundService *services.CreateAcledaRefundService
+	statusService *services.UpdateAcledaPaymentStatusService
+}
+
+func NewPaymentAdminController(refundService *services.CreateAcledaRefundService, statusService *services.UpdateAcledaPaymentStatusService) *PaymentAdminController {
+	return &PaymentAdminController{
+		refundService: refundService,
+		statusService: statusService,
+	}
+}
+
+// ListPendingRefunds returns refunds whose bank outcome is unknown
+func (c *PaymentAdminController) ListPendingRefunds(ctx *fiber.Ctx) error {
+	refunds, err := c.refundService.ListPending(ctx.UserContext())
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list pending refunds", err, nil, "")
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", refunds, "")
+}
+
+// ResolveRefund settles a pending refund as succeeded or failed
+func (c *PaymentAdminController) ResolveRefund(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	incoming.Save = true
+	admin, _ := ctx.Locals("admin").(string)
+
+	var req services.ResolveAcledaRefundInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, req, "")
+	}
+
+	result, err := c.refundService.Resolve(ctx.UserContext(), ctx.Params("id"), req, admin)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidResolution):
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, "")
+		case errors.Is(err, services.ErrRefundNotFound):
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Refund not found", err, req, "")
+		case errors.Is(err, entities.ErrRefundNotPending):
+			return common.ErrorResponse(ctx, http.StatusConflict, "Refund already resolved", err, req, "")
+		default:
+			return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to resolve refund", err, req, "")
+		}
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Refund resolved", result, result.TransactionID)
+}
+
+// SetPaymentStatus moves a payment link to the status an operator decided on
+func (c *PaymentAdminController) SetPaymentStatus(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	incoming.Save = true
+	admin, _ := ctx.Locals("admin").(string)
+	transactionID := ctx.Params("id")
+
+	var req services.SetPaymentStatusInput
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err, req, transactionID)
+	}
+
+	change, err := c.statusService.SetStatus(ctx.UserContext(), transactionID, req, admin)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPaymentStatus):
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, req, transactionID)
+		case errors.Is(err, services.ErrPaymentLinkNotFound):
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Payment link not found", err, req, transactionID)
+		case errors.Is(err, entities.ErrInvalidStatusTransition), errors.Is(err, entities.ErrPaymentLinkConflict):
+			return common.ErrorResponse(ctx, http.StatusConflict, "Status change rejected", err, req, transactionID)
+		default:
+			return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to change payment status", err, req, transactionID)
+		}
+	}
+	if change == nil {
+		return common.SuccessResponse(ctx, http.StatusOK, "Status unchanged", nil, transactionID)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Status changed", change, transactionID)
+}
+
+// ListStatusHistory returns every status change of a payment link with its evidence
+func (c *PaymentAdminController) ListStatusHistory(ctx *fiber.Ctx) error {
+	transactionID := ctx.Params("id")
+
+	history, err := c.statusService.ListHistory(ctx.UserContext(), "", transactionID)
+	if err != nil {
+		if errors.Is(err, services.ErrPaymentLinkNotFound) {
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Payment link not found", err, nil, transactionID)
+		}
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list status history", err, nil, transactionID)
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", history, transactionID)
+}
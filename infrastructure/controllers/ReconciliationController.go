@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/common"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ReconciliationController struct {
+	reconciliationService *services.ReconcileAcledaSettlementService
+}
+
+func NewReconciliationController(reconciliationService *services.ReconcileAcledaSettlementService) *ReconciliationController {
+	return &ReconciliationController{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// UploadSettlement reconciles an uploaded Acleda settlement file
+func (c *ReconciliationController) UploadSettlement(ctx *fiber.Ctx) error {
+	incoming := ctx.Locals("incoming").(*entities.Incoming)
+	incoming.Save = true
+	admin, _ := ctx.Locals("admin").(string)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Settlement file is required", err, nil, "")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusBadRequest, "Cannot read settlement file", err, nil, "")
+	}
+	defer file.Close()
+
+	report, err := c.reconciliationService.Execute(ctx.UserContext(), services.ReconcileAcledaSettlementInput{
+		FileName: header.Filename,
+		Source:   services.ReconciliationSourceUpload,
+		Actor:    admin,
+		Content:  file,
+	})
+	if err != nil {
+		if errors.Is(err, entities.ErrSettlementAlreadyIngested) {
+			return common.ErrorResponse(ctx, http.StatusConflict, "Settlement file already reconciled", err, nil, "")
+		}
+		return common.ErrorResponse(ctx, http.StatusUnprocessableEntity, "Failed to reconcile settlement file", err, nil, "")
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "Settlement file reconciled", report, "")
+}
+
+// ListReports lists reconciliation reports, newest first
+func (c *ReconciliationController) ListReports(ctx *fiber.Ctx) error {
+	result, err := c.reconciliationService.ListReports(ctx.UserContext(), ctx.QueryInt("page"), ctx.QueryInt("limit"))
+	if err != nil {
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list reconciliation reports", err, nil, "")
+	}
+
+	return common.SuccessResponseWithMeta(ctx, http.StatusOK, "", result.Items, common.MetaData{
+		Page:      result.Page,
+		TotalPage: result.TotalPages,
+		TotalRows: result.TotalRows,
+		Limit:     result.Limit,
+	}, "")
+}
+
+// GetReport returns one reconciliation report with its per-day totals
+func (c *ReconciliationController) GetReport(ctx *fiber.Ctx) error {
+	report, err := c.reconciliationService.GetReport(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrorResponse(ctx, http.StatusNotFound, "Reconciliation report not found", err, nil, "")
+		}
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to load reconciliation report", err, nil, "")
+	}
+
+	return common.SuccessResponse(ctx, http.StatusOK, "", report, "")
+}
+
+// ListExceptions lists unmatched and mismatched settlement lines
+func (c *ReconciliationController) ListExceptions(ctx *fiber.Ctx) error {
+	result, err := c.reconciliationService.ListExceptions(
+		ctx.UserContext(),
+		ctx.Query("report_id"),
+		ctx.Query("match_status"),
+		ctx.QueryInt("page"),
+		ctx.QueryInt("limit"),
+	)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPaymentFilter) {
+			return common.ErrorResponse(ctx, http.StatusBadRequest, "Validation error", err, nil, "")
+		}
+		return common.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list unmatched items", err, nil, "")
+	}
+
+	return common.SuccessResponseWithMeta(ctx, http.StatusOK, "", result.Items, common.MetaData{
+		Page:      result.Page,
+		TotalPage: result.TotalPages,
+		TotalRows: result.TotalRows,
+		Limit:     result.Limit,
+	}, "")
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// EncryptedString is a string column that is sealed with the default key
+// ring on write and opened on read, so models can use it like a string.
+//
+// The empty string is stored as is. Values written before the column was
+// encrypted are read back as plain text until the rotate-keys command seals
+// them.
+type EncryptedString string
+
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	return DefaultKeyRing().Encrypt(string(s))
+}
+
+func (s *EncryptedString) Scan(src interface{}) error {
+	value, err := scanText(src, "EncryptedString")
+	if err != nil {
+		return err
+	}
+
+	if !IsSealed(value) {
+		*s = EncryptedString(value)
+		return nil
+	}
+	plaintext, err := DefaultKeyRing().Decrypt(value)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+func (EncryptedString) GormDataType() string {
+	return "text"
+}
+
+// EncryptedSecret is an EncryptedString for columns that have always been
+// encrypted. A value without the enc: prefix is legacy ciphertext and is
+// opened with the default legacy cipher; when none is configured it fails
+// with ErrNotSealed instead of being read as plain text.
+type EncryptedSecret string
+
+func (s EncryptedSecret) Value() (driver.Value, error) {
+	return EncryptedString(s).Value()
+}
+
+func (s *EncryptedSecret) Scan(src interface{}) error {
+	value, err := scanText(src, "EncryptedSecret")
+	if err != nil {
+		return err
+	}
+
+	var plaintext string
+	switch {
+	case value == "":
+	case IsSealed(value):
+		plaintext, err = DefaultKeyRing().Decrypt(value)
+	default:
+		plaintext, err = OpenLegacySecret(value)
+	}
+	if err != nil {
+		return err
+	}
+	*s = EncryptedSecret(plaintext)
+	return nil
+}
+
+func (EncryptedSecret) GormDataType() string {
+	return "text"
+}
+
+func scanText(src interface{}, into string) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("cannot scan %T into %s", src, into)
+	}
+}
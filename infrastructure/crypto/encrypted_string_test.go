@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// useDefaults installs ring and legacy as the defaults for the duration of
+// the test.
+func useDefaults(t *testing.T, ring *KeyRing, legacy *LegacyCipher) {
+	t.Helper()
+	prevRing, prevLegacy := DefaultKeyRing(), DefaultLegacyCipher()
+	SetDefaultKeyRing(ring)
+	SetDefaultLegacyCipher(legacy)
+	t.Cleanup(func() {
+		SetDefaultKeyRing(prevRing)
+		SetDefaultLegacyCipher(prevLegacy)
+	})
+}
+
+// legacyValue seals plaintext the way credentials were stored before the key
+// ring: base64 of nonce || AES-256-GCM ciphertext.
+func legacyValue(t *testing.T, key []byte, plaintext string) string {
+	t.Helper()
+	aead, err := newAEAD(key)
+	if err != nil {
+		t.Fatalf("newAEAD: %v", err)
+	}
+	sealed, err := seal(aead, []byte(plaintext), nil)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(sealed)
+}
+
+func mustLegacyCipher(t *testing.T, key []byte) *LegacyCipher {
+	t.Helper()
+	c, err := NewLegacyCipher(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatalf("NewLegacyCipher: %v", err)
+	}
+	return c
+}
+
+func TestEncryptedStringRoundTrip(t *testing.T) {
+	useDefaults(t, mustKeyRing(t, "", map[string][]byte{"k1": testKey(1)}), nil)
+
+	stored, err := EncryptedString("secret").Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+	if !IsSealed(stored.(string)) {
+		t.Fatalf("Value = %q; want a sealed value", stored)
+	}
+
+	var got EncryptedString
+	if err := got.Scan([]byte(stored.(string))); err != nil || got != "secret" {
+		t.Errorf("Scan = %q, %v; want secret", got, err)
+	}
+
+	if stored, err := EncryptedString("").Value(); err != nil || stored != "" {
+		t.Errorf("Value of empty string = %q, %v; want it stored as is", stored, err)
+	}
+}
+
+func TestEncryptedStringScansPlainText(t *testing.T) {
+	useDefaults(t, nil, nil)
+
+	// Written before the column was encrypted; read as is until rotate-keys
+	// seals it.
+	var got EncryptedString
+	if err := got.Scan("plain password"); err != nil || got != "plain password" {
+		t.Errorf("Scan = %q, %v; want the plain text", got, err)
+	}
+	if err := got.Scan(nil); err != nil || got != "" {
+		t.Errorf("Scan(nil) = %q, %v; want empty", got, err)
+	}
+	if err := got.Scan(42); err == nil {
+		t.Error("Scan(42) succeeded; want an error")
+	}
+}
+
+func TestEncryptedStringWithoutKeyRing(t *testing.T) {
+	useDefaults(t, nil, nil)
+
+	if _, err := EncryptedString("secret").Value(); !errors.Is(err, ErrKeyNotConfigured) {
+		t.Errorf("Value = %v; want ErrKeyNotConfigured", err)
+	}
+}
+
+// errAny marks a test case that must fail without a specific error.
+var errAny = errors.New("any error")
+
+func TestEncryptedSecretScan(t *testing.T) {
+	ring := mustKeyRing(t, "", map[string][]byte{"k1": testKey(1)})
+	legacyKey := testKey(9)
+	legacy := legacyValue(t, legacyKey, "acleda-secret")
+	sealed, err := ring.Encrypt("acleda-secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		legacy *LegacyCipher
+		src    interface{}
+		want   string
+		err    error
+	}{
+		{name: "sealed", src: sealed, want: "acleda-secret"},
+		{name: "sealed with legacy cipher", legacy: mustLegacyCipher(t, legacyKey), src: sealed, want: "acleda-secret"},
+		{name: "legacy with cipher", legacy: mustLegacyCipher(t, legacyKey), src: legacy, want: "acleda-secret"},
+		{name: "legacy as bytes", legacy: mustLegacyCipher(t, legacyKey), src: []byte(legacy), want: "acleda-secret"},
+		{name: "legacy without cipher", src: legacy, err: ErrNotSealed},
+		{name: "plain text without cipher", src: "acleda-secret", err: ErrNotSealed},
+		{name: "plain text with cipher", legacy: mustLegacyCipher(t, legacyKey), src: "acleda-secret", err: ErrMalformedValue},
+		{name: "legacy under another key", legacy: mustLegacyCipher(t, testKey(8)), src: legacy, err: errAny},
+		{name: "empty", src: "", want: ""},
+		{name: "null", src: nil, want: ""},
+	}
+	for _, tt := range tests {
+		useDefaults(t, ring, tt.legacy)
+
+		got := EncryptedSecret("unchanged")
+		err := got.Scan(tt.src)
+		switch {
+		case tt.err == errAny:
+			if err == nil {
+				t.Errorf("%s: Scan = %q; want an error", tt.name, got)
+			}
+		case tt.err != nil:
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: Scan = %q, %v; want %v", tt.name, got, err, tt.err)
+			}
+		case err != nil || got != EncryptedSecret(tt.want):
+			t.Errorf("%s: Scan = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestEncryptedSecretValueIsSealed(t *testing.T) {
+	useDefaults(t, mustKeyRing(t, "", map[string][]byte{"k1": testKey(1)}), nil)
+
+	stored, err := EncryptedSecret("acleda-secret").Value()
+	if err != nil || !IsSealed(stored.(string)) {
+		t.Fatalf("Value = %q, %v; want a sealed value", stored, err)
+	}
+	var got EncryptedSecret
+	if err := got.Scan(stored); err != nil || got != "acleda-secret" {
+		t.Errorf("Scan = %q, %v; want acleda-secret", got, err)
+	}
+}
+
+func TestNewLegacyCipher(t *testing.T) {
+	if _, err := NewLegacyCipher(""); !errors.Is(err, ErrKeyNotConfigured) {
+		t.Errorf("empty key: %v; want ErrKeyNotConfigured", err)
+	}
+	for _, key := range []string{"not base64", base64.StdEncoding.EncodeToString(testKey(1)[:16])} {
+		if _, err := NewLegacyCipher(key); err == nil {
+			t.Errorf("NewLegacyCipher(%q) succeeded; want an error", key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"errors"
+
+	"payment-airpay/infrastructure/configuration"
+
+	"go.uber.org/zap"
+)
+
+// InitializeKeyRing loads the master keys from configuration.AppConfig and
+// installs them as the default key ring. Without keys, encrypted columns
+// cannot be written and sealed values cannot be read.
+func InitializeKeyRing() {
+	log := zap.L().With(zap.String("component", "encryption"))
+	cfg := configuration.AppConfig
+	ring, err := LoadKeyRing(cfg.EncryptionActiveKeyID, cfg.EncryptionMasterKeys, cfg.EncryptionMasterKeysFile)
+	if errors.Is(err, ErrKeyNotConfigured) {
+		log.Warn("No encryption master keys configured; encrypted columns cannot be written")
+		return
+	}
+	if err != nil {
+		log.Fatal("Failed to load encryption keys", zap.Error(err))
+	}
+
+	SetDefaultKeyRing(ring)
+	log.Info("Encryption key ring loaded", zap.String("active_key", ring.ActiveKeyID()))
+}
+
+// InitializeLegacyCipher installs the cipher for Acleda credentials sealed
+// with CREDENTIALS_ENCRYPTION_KEY before the key ring existed. Without it
+// such values fail to load until rotate-keys has re-sealed them.
+func InitializeLegacyCipher() {
+	log := zap.L().With(zap.String("component", "encryption"))
+	c, err := NewLegacyCipher(configuration.AppConfig.CredentialsEncryptionKey)
+	if errors.Is(err, ErrKeyNotConfigured) {
+		return
+	}
+	if err != nil {
+		log.Fatal("Failed to load legacy credentials encryption key", zap.Error(err))
+	}
+
+	SetDefaultLegacyCipher(c)
+	log.Info("Legacy credentials encryption key loaded; run rotate-keys, then remove it")
+}
+
+// InitializeURLSigner loads the payment page signing keys from
+// configuration.AppConfig and installs them as the default URL signer.
+// Without keys it signs with a random key, so links stop working after a
+// restart; configuration requires keys outside development.
+func InitializeURLSigner() {
+	log := zap.L().With(zap.String("component", "encryption"))
+	cfg := configuration.AppConfig
+	keys, err := ParseMasterKeys(cfg.PaymentPageSigningKeys)
+	if err != nil {
+		log.Fatal("Failed to parse payment page signing keys", zap.Error(err))
+	}
+
+	var signer *URLSigner
+	if len(keys) == 0 {
+		log.Warn("No payment page signing keys configured; payment links are signed with a temporary key")
+		signer, err = NewEphemeralURLSigner()
+	} else {
+		signer, err = NewURLSigner(cfg.PaymentPageSigningKeyID, keys)
+	}
+	if err != nil {
+		log.Fatal("Failed to load payment page signing keys", zap.Error(err))
+	}
+
+	SetDefaultURLSigner(signer)
+}
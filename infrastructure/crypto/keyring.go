@@ -0,0 +1,254 @@
+// Package crypto encrypts sensitive columns before they are written to Yugabyte.
+//
+// Values are envelope encrypted: every value gets its own random data key,
+// the data key is wrapped with a master key, and the master key's ID is kept
+// next to the ciphertext so old values stay readable after a rotation.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// sealedPrefix marks an envelope encrypted value:
+// enc:v1:<key id>:<base64 wrapped data key>:<base64 nonce|ciphertext>
+const sealedPrefix = "enc:v1:"
+
+const dataKeySize = 32
+
+var (
+	ErrKeyNotConfigured = errors.New("encryption keys are not configured")
+	ErrUnknownKey       = errors.New("unknown encryption key")
+	ErrMalformedValue   = errors.New("malformed encrypted value")
+)
+
+// KeyRing holds the master keys. New values are sealed with the active key;
+// any key in the ring can open a value.
+type KeyRing struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// NewKeyRing builds a key ring from raw 32-byte master keys. An empty
+// activeID picks the alphabetically last key ID.
+func NewKeyRing(activeID string, keys map[string][]byte) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, ErrKeyNotConfigured
+	}
+
+	ring := &KeyRing{keys: make(map[string]cipher.AEAD, len(keys))}
+	ids := make([]string, 0, len(keys))
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", id, err)
+		}
+		ring.keys[id] = aead
+		ids = append(ids, id)
+	}
+
+	if activeID == "" {
+		sort.Strings(ids)
+		activeID = ids[len(ids)-1]
+	}
+	if _, ok := ring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, activeID)
+	}
+	ring.activeID = activeID
+	return ring, nil
+}
+
+// ParseMasterKeys reads "id:base64key" entries separated by commas or new
+// lines. Blank lines and lines starting with # are ignored.
+func ParseMasterKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, errors.New("master key entry must be id:base64key")
+		}
+		id = strings.TrimSpace(id)
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("master key %q is listed twice", id)
+		}
+		keys[id] = raw
+	}
+	return keys, nil
+}
+
+// LoadKeyRing combines the keys given inline with those in file (either may
+// be empty) and returns ErrKeyNotConfigured when there are none.
+func LoadKeyRing(activeID, inline, file string) (*KeyRing, error) {
+	keys, err := ParseMasterKeys(inline)
+	if err != nil {
+		return nil, err
+	}
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master key file: %w", err)
+		}
+		fromFile, err := ParseMasterKeys(string(content))
+		if err != nil {
+			return nil, err
+		}
+		for id, key := range fromFile {
+			if _, dup := keys[id]; dup {
+				return nil, fmt.Errorf("master key %q is listed twice", id)
+			}
+			keys[id] = key
+		}
+	}
+	return NewKeyRing(activeID, keys)
+}
+
+func (k *KeyRing) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Encrypt seals plaintext under a fresh data key wrapped by the active key.
+func (k *KeyRing) Encrypt(plaintext string) (string, error) {
+	if k == nil {
+		return "", ErrKeyNotConfigured
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataAEAD, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return sealedPrefix + k.activeID + ":" +
+		base64.StdEncoding.EncodeToString(wrapped) + ":" +
+		base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with whichever master key
+// sealed it.
+func (k *KeyRing) Decrypt(value string) (string, error) {
+	if k == nil {
+		return "", ErrKeyNotConfigured
+	}
+
+	id, wrapped, sealed, err := splitSealed(value)
+	if err != nil {
+		return "", err
+	}
+	master, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	dataKey, err := open(master, wrapped, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAEAD, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value was produced by Encrypt.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// KeyID returns the ID of the master key that sealed value.
+func KeyID(value string) (string, bool) {
+	id, _, _, err := splitSealed(value)
+	return id, err == nil
+}
+
+func splitSealed(value string) (id string, wrapped, sealed []byte, err error) {
+	if !IsSealed(value) {
+		return "", nil, nil, ErrMalformedValue
+	}
+	parts := strings.Split(strings.TrimPrefix(value, sealedPrefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrMalformedValue
+	}
+	if wrapped, err = base64.StdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrMalformedValue
+	}
+	if sealed, err = base64.StdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrMalformedValue
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns nonce || ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, data, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformedValue
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+var defaultKeyRing atomic.Pointer[KeyRing]
+
+// SetDefaultKeyRing installs the key ring used by EncryptedString columns.
+func SetDefaultKeyRing(ring *KeyRing) {
+	defaultKeyRing.Store(ring)
+}
+
+// DefaultKeyRing returns the installed key ring, or nil when none is set.
+func DefaultKeyRing() *KeyRing {
+	return defaultKeyRing.Load()
+}
@@ -0,0 +1,167 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func mustKeyRing(t *testing.T, activeID string, keys map[string][]byte) *KeyRing {
+	t.Helper()
+	ring, err := NewKeyRing(activeID, keys)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	return ring
+}
+
+func TestKeyRingRoundTrip(t *testing.T) {
+	ring := mustKeyRing(t, "", map[string][]byte{"k1": testKey(1)})
+
+	for _, plaintext := range []string{"secret", "", "ünïcödé : with colons"} {
+		sealed, err := ring.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q): %v", plaintext, err)
+		}
+		if !IsSealed(sealed) || strings.Contains(sealed, "secret") {
+			t.Errorf("Encrypt(%q) = %q; want an enc:v1 value without the plain text", plaintext, sealed)
+		}
+		if id, ok := KeyID(sealed); !ok || id != "k1" {
+			t.Errorf("KeyID = %q, %v; want k1", id, ok)
+		}
+		got, err := ring.Decrypt(sealed)
+		if err != nil || got != plaintext {
+			t.Errorf("Decrypt = %q, %v; want %q", got, err, plaintext)
+		}
+	}
+
+	a, _ := ring.Encrypt("secret")
+	b, _ := ring.Encrypt("secret")
+	if a == b {
+		t.Error("two encryptions of the same value are equal; want a fresh data key and nonce each time")
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	old := mustKeyRing(t, "", map[string][]byte{"2025": testKey(1)})
+	sealedOld, err := old.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// The new key is picked as active because it sorts last; the old one
+	// stays readable.
+	rotated := mustKeyRing(t, "", map[string][]byte{"2025": testKey(1), "2026": testKey(2)})
+	if rotated.ActiveKeyID() != "2026" {
+		t.Fatalf("active key = %q; want 2026", rotated.ActiveKeyID())
+	}
+	if got, err := rotated.Decrypt(sealedOld); err != nil || got != "secret" {
+		t.Fatalf("Decrypt of old value = %q, %v; want secret", got, err)
+	}
+	sealedNew, err := rotated.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if id, _ := KeyID(sealedNew); id != "2026" {
+		t.Errorf("new value sealed with %q; want 2026", id)
+	}
+
+	// An explicit active ID wins over the sort order.
+	pinned := mustKeyRing(t, "2025", map[string][]byte{"2025": testKey(1), "2026": testKey(2)})
+	if pinned.ActiveKeyID() != "2025" {
+		t.Errorf("active key = %q; want 2025", pinned.ActiveKeyID())
+	}
+
+	// Once the old key is retired, its values can no longer be opened.
+	retired := mustKeyRing(t, "", map[string][]byte{"2026": testKey(2)})
+	if _, err := retired.Decrypt(sealedOld); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt with retired key = %v; want ErrUnknownKey", err)
+	}
+	if got, err := retired.Decrypt(sealedNew); err != nil || got != "secret" {
+		t.Errorf("Decrypt of new value = %q, %v; want secret", got, err)
+	}
+}
+
+func TestKeyRingRejectsTamperedValues(t *testing.T) {
+	ring := mustKeyRing(t, "", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	sealed, err := ring.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	parts := strings.Split(strings.TrimPrefix(sealed, sealedPrefix), ":")
+
+	flip := func(encoded string) string {
+		raw, _ := base64.StdEncoding.DecodeString(encoded)
+		raw[len(raw)-1] ^= 1
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+	tests := map[string]string{
+		"ciphertext":  sealedPrefix + parts[0] + ":" + parts[1] + ":" + flip(parts[2]),
+		"wrapped key": sealedPrefix + parts[0] + ":" + flip(parts[1]) + ":" + parts[2],
+		"key id":      sealedPrefix + "k1:" + parts[1] + ":" + parts[2],
+	}
+	for name, value := range tests {
+		if got, err := ring.Decrypt(value); err == nil {
+			t.Errorf("%s changed: Decrypt = %q; want an error", name, got)
+		}
+	}
+
+	for _, value := range []string{"secret", "enc:v1:", "enc:v1:k2:!!:!!", "enc:v1:k2:" + parts[1]} {
+		if _, err := ring.Decrypt(value); !errors.Is(err, ErrMalformedValue) {
+			t.Errorf("Decrypt(%q) = %v; want ErrMalformedValue", value, err)
+		}
+	}
+}
+
+func TestNilKeyRing(t *testing.T) {
+	var ring *KeyRing
+	if _, err := ring.Encrypt("secret"); !errors.Is(err, ErrKeyNotConfigured) {
+		t.Errorf("Encrypt = %v; want ErrKeyNotConfigured", err)
+	}
+	if _, err := ring.Decrypt("enc:v1:k1:AA==:AA=="); !errors.Is(err, ErrKeyNotConfigured) {
+		t.Errorf("Decrypt = %v; want ErrKeyNotConfigured", err)
+	}
+}
+
+func TestNewKeyRingRejectsBadKeys(t *testing.T) {
+	tests := map[string]struct {
+		activeID string
+		keys     map[string][]byte
+	}{
+		"no keys":        {keys: nil},
+		"short key":      {keys: map[string][]byte{"k1": testKey(1)[:16]}},
+		"empty id":       {keys: map[string][]byte{"": testKey(1)}},
+		"id with colon":  {keys: map[string][]byte{"k:1": testKey(1)}},
+		"unknown active": {activeID: "k2", keys: map[string][]byte{"k1": testKey(1)}},
+	}
+	for name, tt := range tests {
+		if _, err := NewKeyRing(tt.activeID, tt.keys); err == nil {
+			t.Errorf("%s: NewKeyRing succeeded; want an error", name)
+		}
+	}
+}
+
+func TestParseMasterKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+
+	keys, err := ParseMasterKeys("# rotated 2026-01\nk1:" + k1 + "\n\n k2 : " + k2 + " ,")
+	if err != nil {
+		t.Fatalf("ParseMasterKeys: %v", err)
+	}
+	if len(keys) != 2 || !bytes.Equal(keys["k1"], testKey(1)) || !bytes.Equal(keys["k2"], testKey(2)) {
+		t.Errorf("ParseMasterKeys = %v; want k1 and k2", keys)
+	}
+
+	for _, spec := range []string{"k1", "k1:not base64", "k1:" + k1 + ",k1:" + k2} {
+		if _, err := ParseMasterKeys(spec); err == nil {
+			t.Errorf("ParseMasterKeys(%q) succeeded; want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNotSealed is returned for a value that should be envelope encrypted
+// but carries no enc: prefix and cannot be read any other way.
+var ErrNotSealed = errors.New("value is not sealed")
+
+// LegacyCipher opens Acleda credentials written before the key ring was
+// introduced: base64 of nonce || AES-256-GCM ciphertext under the single
+// CREDENTIALS_ENCRYPTION_KEY. It only decrypts; rotate-keys re-seals the
+// values with the key ring, after which the key can be removed.
+type LegacyCipher struct {
+	aead cipher.AEAD
+}
+
+// NewLegacyCipher builds a cipher from a base64-encoded 32-byte key. An
+// empty key returns ErrKeyNotConfigured.
+func NewLegacyCipher(key string) (*LegacyCipher, error) {
+	if key == "" {
+		return nil, ErrKeyNotConfigured
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &LegacyCipher{aead: aead}, nil
+}
+
+func (c *LegacyCipher) Decrypt(ciphertext string) (string, error) {
+	if c == nil {
+		return "", ErrKeyNotConfigured
+	}
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMalformedValue, err)
+	}
+	plaintext, err := open(c.aead, raw, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt legacy value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+var defaultLegacyCipher atomic.Pointer[LegacyCipher]
+
+// SetDefaultLegacyCipher installs the cipher used by EncryptedSecret
+// columns for values without the enc: prefix.
+func SetDefaultLegacyCipher(c *LegacyCipher) {
+	defaultLegacyCipher.Store(c)
+}
+
+// DefaultLegacyCipher returns the installed legacy cipher, or nil when none
+// is set.
+func DefaultLegacyCipher() *LegacyCipher {
+	return defaultLegacyCipher.Load()
+}
+
+// OpenLegacySecret decrypts an unprefixed EncryptedSecret value with the
+// default legacy cipher. Without one the value is unreadable and
+// ErrNotSealed is returned, so a stray plain text or foreign value is never
+// passed on as a credential.
+func OpenLegacySecret(value string) (string, error) {
+	c := DefaultLegacyCipher()
+	if c == nil {
+		return "", fmt.Errorf("%w: set CREDENTIALS_ENCRYPTION_KEY to read values written before key rotation, then run rotate-keys", ErrNotSealed)
+	}
+	return c.Decrypt(value)
+}
@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustURLSigner(t *testing.T, activeID string, keys map[string][]byte) *URLSigner {
+	t.Helper()
+	signer, err := NewURLSigner(activeID, keys)
+	if err != nil {
+		t.Fatalf("NewURLSigner: %v", err)
+	}
+	return signer
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	signer := mustURLSigner(t, "", map[string][]byte{"s1": testKey(1)})
+	now := time.Unix(1_770_000_000, 0)
+	expiresAt := now.Add(30 * time.Minute)
+
+	token := signer.Sign("ACL-1", expiresAt)
+	got, err := signer.Verify(token, "ACL-1", now)
+	if err != nil || !got.Equal(expiresAt) {
+		t.Fatalf("Verify = %v, %v; want %v", got, err, expiresAt)
+	}
+
+	if _, err := signer.Verify(token, "ACL-1", expiresAt.Add(-time.Second)); err != nil {
+		t.Errorf("Verify just before expiry: %v", err)
+	}
+	for _, at := range []time.Time{expiresAt, expiresAt.Add(time.Hour)} {
+		got, err := signer.Verify(token, "ACL-1", at)
+		if !errors.Is(err, ErrTokenExpired) || !got.Equal(expiresAt) {
+			t.Errorf("Verify at %v = %v, %v; want ErrTokenExpired and the expiry", at, got, err)
+		}
+	}
+}
+
+func TestURLSignerRejectsTamperedTokens(t *testing.T) {
+	signer := mustURLSigner(t, "", map[string][]byte{"s1": testKey(1)})
+	now := time.Unix(1_770_000_000, 0)
+	token := signer.Sign("ACL-1", now.Add(30*time.Minute))
+	parts := strings.Split(token, ".")
+
+	later := signer.Sign("ACL-1", now.Add(24*time.Hour))
+	laterParts := strings.Split(later, ".")
+
+	tests := map[string]string{
+		"empty":               "",
+		"version":             "v2." + strings.Join(parts[1:], "."),
+		"unknown key":         parts[0] + ".s2." + strings.Join(parts[2:], "."),
+		"expiry extended":     strings.Join(append(parts[:2:2], laterParts[2], parts[3]), "."),
+		"expiry not a number": strings.Join(append(parts[:2:2], "soon", parts[3]), "."),
+		"signature":           strings.Join(parts[:3], ".") + "." + strings.Repeat("A", len(parts[3])),
+		"missing signature":   strings.Join(parts[:3], "."),
+		"extra part":          token + ".x",
+	}
+	for name, tampered := range tests {
+		if _, err := signer.Verify(tampered, "ACL-1", now); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: Verify = %v; want ErrInvalidToken", name, err)
+		}
+	}
+
+	if _, err := signer.Verify(token, "ACL-2", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify for another subject = %v; want ErrInvalidToken", err)
+	}
+
+	other := mustURLSigner(t, "", map[string][]byte{"s1": testKey(2)})
+	if _, err := other.Verify(token, "ACL-1", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify with another key under the same ID = %v; want ErrInvalidToken", err)
+	}
+}
+
+func TestURLSignerRotation(t *testing.T) {
+	now := time.Unix(1_770_000_000, 0)
+	old := mustURLSigner(t, "", map[string][]byte{"s1": testKey(1)})
+	token := old.Sign("ACL-1", now.Add(time.Hour))
+
+	rotated := mustURLSigner(t, "", map[string][]byte{"s1": testKey(1), "s2": testKey(2)})
+	if _, err := rotated.Verify(token, "ACL-1", now); err != nil {
+		t.Errorf("Verify of a token from the old key: %v", err)
+	}
+	if got := rotated.Sign("ACL-1", now.Add(time.Hour)); !strings.HasPrefix(got, "v1.s2.") {
+		t.Errorf("Sign = %q; want it signed with s2", got)
+	}
+
+	retired := mustURLSigner(t, "", map[string][]byte{"s2": testKey(2)})
+	if _, err := retired.Verify(token, "ACL-1", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify after retiring s1 = %v; want ErrInvalidToken", err)
+	}
+}
+
+func TestNewURLSignerRejectsBadKeys(t *testing.T) {
+	tests := map[string]struct {
+		activeID string
+		keys     map[string][]byte
+	}{
+		"no keys":        {keys: nil},
+		"short key":      {keys: map[string][]byte{"s1": testKey(1)[:31]}},
+		"empty id":       {keys: map[string][]byte{"": testKey(1)}},
+		"id with dot":    {keys: map[string][]byte{"s.1": testKey(1)}},
+		"unknown active": {activeID: "s2", keys: map[string][]byte{"s1": testKey(1)}},
+	}
+	for name, tt := range tests {
+		if _, err := NewURLSigner(tt.activeID, tt.keys); err == nil {
+			t.Errorf("%s: NewURLSigner succeeded; want an error", name)
+		}
+	}
+}
@@ -10,15 +10,45 @@ type YugabyteClient interface {
 	Create(ctx context.Context, value interface{}) error
 	First(ctx context.Context, dest interface{}, query interface{}, args ...interface{}) error
 	Save(ctx context.Context, value interface{}) error
+	WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	Reader(ctx context.Context) *gorm.DB
 	GetDB() *gorm.DB
 }
 
 type yugabyteClient struct {
-	db *gorm.DB
+	db     *gorm.DB
+	reader *gorm.DB
 }
 
-func NewYugabyteClient(db *gorm.DB) YugabyteClient {
-	return &yugabyteClient{db: db}
+// NewYugabyteClient wraps db. reader, which may be nil, serves the reads made
+// with a context from AllowStaleReads.
+func NewYugabyteClient(db *gorm.DB, reader *gorm.DB) YugabyteClient {
+	return &yugabyteClient{db: db, reader: reader}
+}
+
+type staleReadsKey struct{}
+
+// AllowStaleReads marks ctx so that reads made with it may be served by a
+// read replica or a follower, which can lag the leader by a few seconds. Use
+// it for display and polling, never to read data that is about to be written.
+func AllowStaleReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleReadsKey{}, true)
+}
+
+// StaleReadsAllowed reports whether ctx was marked by AllowStaleReads.
+func StaleReadsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(staleReadsKey{}).(bool)
+	return allowed
+}
+
+// Reader returns the connection for a read made with ctx: the read
+// connection when ctx allows stale reads and one is configured, otherwise the
+// primary.
+func (c *yugabyteClient) Reader(ctx context.Context) *gorm.DB {
+	if c.reader != nil && StaleReadsAllowed(ctx) {
+		return c.reader.WithContext(ctx)
+	}
+	return c.db.WithContext(ctx)
 }
 
 func (c *yugabyteClient) Create(ctx context.Context, value interface{}) error {
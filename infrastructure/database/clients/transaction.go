@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxTransactionAttempts bounds how often a conflicting transaction is
+	// run, including the first attempt.
+	maxTransactionAttempts = 5
+
+	transactionBackoffBase = 20 * time.Millisecond
+	transactionBackoffMax  = time.Second
+)
+
+// IsRetryable reports whether err is a serialization failure (40001) or a
+// deadlock (40P01). YugabyteDB also reports read restarts and conflicting
+// distributed transactions as 40001. The transaction was rolled back, so it
+// can be run again as a whole.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation
+// (23505), e.g. when a concurrent caller inserted the same key first.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// WithTransaction runs fn in a transaction and runs it again, with jittered
+// exponential backoff, while it fails with a retryable error. fn may
+// therefore be called more than once and must not have side effects outside
+// tx. Every statement inherits ctx, and no retry starts after ctx is done.
+func (c *yugabyteClient) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	for attempt := 1; ; attempt++ {
+		err := c.db.WithContext(ctx).Transaction(fn)
+		if err == nil || !IsRetryable(err) || attempt == maxTransactionAttempts {
+			return err
+		}
+
+		delay := transactionBackoff(attempt)
+		zap.L().Debug("Retrying conflicting transaction",
+			zap.String("component", "database"),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// transactionBackoff returns a random delay of up to base*2^(attempt-1),
+// capped at transactionBackoffMax, so that conflicting callers spread out.
+func transactionBackoff(attempt int) time.Duration {
+	ceiling := transactionBackoffBase << (attempt - 1)
+	if ceiling > transactionBackoffMax || ceiling <= 0 {
+		ceiling = transactionBackoffMax
+	}
+	return ceiling/2 + rand.N(ceiling/2+1)
+}
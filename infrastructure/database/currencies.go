@@ -0,0 +1,62 @@
+package database
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seedCurrencies upserts the ISO 4217 catalogue into the currencies table.
+// Names and numeric codes always follow the catalogue. Minor unit, limits
+// and the enabled flag are only written the first time a code is seeded,
+// so changes made through the admin API survive restarts.
+func seedCurrencies(db *gorm.DB) error {
+	actor := "system"
+	dataStatus := "ACTIVE"
+	now := time.Now().UnixMilli()
+
+	rows := make([]models.CurrenciesDataModel, 0, len(entities.ISO4217Currencies))
+	for _, iso := range entities.ISO4217Currencies {
+		row := models.CurrenciesDataModel{
+			Code:        iso.Code,
+			Name:        iso.Name,
+			NumericCode: iso.Numeric,
+			MinorUnit:   iso.MinorUnit,
+			CreatedDate: &now,
+			CreatedUser: &actor,
+			DataStatus:  &dataStatus,
+		}
+		if defaults, ok := entities.AcledaCurrencyDefaults[iso.Code]; ok {
+			minAmount, maxAmount := defaults.MinAmount, defaults.MaxAmount
+			row.MinorUnit = defaults.MinorUnit
+			row.MinAmount = &minAmount
+			row.MaxAmount = &maxAmount
+			row.Enabled = true
+		}
+		rows = append(rows, row)
+	}
+
+	firstSeed := func(column string) clause.Assignment {
+		return clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value: gorm.Expr("CASE WHEN currencies.numeric_code IS NULL OR currencies.numeric_code = '' " +
+				"THEN EXCLUDED." + column + " ELSE currencies." + column + " END"),
+		}
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}},
+		DoUpdates: clause.Set{
+			firstSeed("minor_unit"),
+			firstSeed("min_amount"),
+			firstSeed("max_amount"),
+			firstSeed("enabled"),
+			{Column: clause.Column{Name: "name"}, Value: gorm.Expr("EXCLUDED.name")},
+			{Column: clause.Column{Name: "numeric_code"}, Value: gorm.Expr("EXCLUDED.numeric_code")},
+		},
+	}).CreateInBatches(rows, 100).Error
+}
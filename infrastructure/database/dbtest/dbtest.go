@@ -0,0 +1,56 @@
+// Package dbtest opens the database used by integration tests. Tests that
+// need one call Open, which skips them unless TEST_DATABASE_URL points at a
+// Postgres or YugabyteDB database they may write to. Under CI the variable
+// is required, so a misconfigured job fails instead of skipping the suite.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/database"
+	"payment-airpay/infrastructure/database/clients"
+
+	"gorm.io/gorm"
+)
+
+// EnvURL names the variable holding the test database's connection string.
+const EnvURL = "TEST_DATABASE_URL"
+
+var (
+	once    sync.Once
+	shared  *gorm.DB
+	openErr error
+)
+
+// Open returns a client for the test database, migrated to the latest
+// schema. The connection is shared by the tests of a package and left open
+// until the process exits. Tests should use unique codes and IDs, as rows
+// are not cleaned up between runs.
+func Open(t testing.TB) clients.YugabyteClient {
+	t.Helper()
+
+	dsn := os.Getenv(EnvURL)
+	if dsn == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("%s must be set when CI is", EnvURL)
+		}
+		t.Skipf("%s is not set", EnvURL)
+	}
+
+	once.Do(func() {
+		cfg := &configuration.Config{YugabyteMaxOpenConns: 20, YugabyteMaxIdleConns: 5}
+		shared, openErr = database.OpenYugabyteDSN(cfg, dsn)
+		if openErr != nil {
+			return
+		}
+		_, openErr = database.MigrateYugabyteDB(context.Background(), shared)
+	})
+	if openErr != nil {
+		t.Fatalf("failed to open test database: %v", openErr)
+	}
+	return clients.NewYugabyteClient(shared, nil)
+}
@@ -1,11 +1,17 @@
 package database
 
+import "context"
+
 // Mock ElasticsearchClient to fix compilation errors until fully implemented.
 // This matches the usage `database.ElasticsearchClient.Index("...").Request(&model).Do(ctx)`
 type elasticsearchClientMock struct{}
 
 var ElasticsearchClient *elasticsearchClientMock = nil
 
+func (m *elasticsearchClientMock) Ping(ctx context.Context) error {
+	return nil
+}
+
 func (m *elasticsearchClientMock) Index(index string) *elasticsearchIndexRequestMock {
 	return &elasticsearchIndexRequestMock{}
 }
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"payment-airpay/infrastructure/crypto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// encryptedColumns lists every encrypted column with the way its values
+// without the enc: prefix are read: merchant passwords were plain text,
+// Acleda credentials were sealed with the legacy CREDENTIALS_ENCRYPTION_KEY.
+var encryptedColumns = []struct {
+	table    string
+	column   string
+	unsealed func(string) (string, error)
+}{
+	{table: "merchants", column: "password", unsealed: plainText},
+	{table: "merchant_acleda_credentials", column: "password", unsealed: crypto.OpenLegacySecret},
+	{table: "merchant_acleda_credentials", column: "secret", unsealed: crypto.OpenLegacySecret},
+}
+
+func plainText(value string) (string, error) {
+	return value, nil
+}
+
+const rotateBatchSize = 500
+
+// RotateEncryptionKeys re-seals every encrypted value that is not sealed
+// with the active key of ring, including plain text written before the
+// column was encrypted and credentials sealed with the legacy key. It
+// stops at the first value it cannot open. It returns the number of values rewritten. Retired
+// keys can be dropped from the configuration once it has run.
+func RotateEncryptionKeys(ctx context.Context, db *gorm.DB, ring *crypto.KeyRing) (int, error) {
+	if ring == nil {
+		return 0, crypto.ErrKeyNotConfigured
+	}
+
+	total := 0
+	for _, c := range encryptedColumns {
+		n, err := rotateColumn(ctx, db, ring, c.table, c.column, c.unsealed)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("failed to rotate %s.%s: %w", c.table, c.column, err)
+		}
+		zap.L().Info("Rotated encrypted column",
+			zap.String("component", "encryption"),
+			zap.String("table", c.table),
+			zap.String("column", c.column),
+			zap.Int("rotated", n))
+	}
+	return total, nil
+}
+
+func rotateColumn(ctx context.Context, db *gorm.DB, ring *crypto.KeyRing, table, column string, unsealed func(string) (string, error)) (int, error) {
+	type row struct {
+		ID    uuid.UUID
+		Value string
+	}
+
+	rotated := 0
+	lastID := uuid.Nil
+	for {
+		var rows []row
+		err := db.WithContext(ctx).Table(table).
+			Select("id, "+column+" AS value").
+			Where("id > ? AND "+column+" <> ''", lastID).
+			Order("id ASC").
+			Limit(rotateBatchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return rotated, err
+		}
+		if len(rows) == 0 {
+			return rotated, nil
+		}
+
+		for _, r := range rows {
+			lastID = r.ID
+			if id, ok := crypto.KeyID(r.Value); ok && id == ring.ActiveKeyID() {
+				continue
+			}
+
+			var plaintext string
+			if crypto.IsSealed(r.Value) {
+				plaintext, err = ring.Decrypt(r.Value)
+			} else {
+				plaintext, err = unsealed(r.Value)
+			}
+			if err != nil {
+				return rotated, fmt.Errorf("row %s: %w", r.ID, err)
+			}
+			sealed, err := ring.Encrypt(plaintext)
+			if err != nil {
+				return rotated, err
+			}
+
+			// Match on the old value so a concurrent update is not overwritten.
+			result := db.WithContext(ctx).Table(table).
+				Where("id = ? AND "+column+" = ?", r.ID, r.Value).
+				Update(column, sealed)
+			if result.Error != nil {
+				return rotated, result.Error
+			}
+			rotated += int(result.RowsAffected)
+		}
+	}
+}
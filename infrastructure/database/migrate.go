@@ -0,0 +1,376 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment-airpay/infrastructure/database/migrations"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+const (
+	// migrationLockTTL is how long a lock is honoured. A migrator that
+	// crashed while holding it blocks others for at most this long.
+	migrationLockTTL = 15 * time.Minute
+
+	migrationLockPoll = time.Second
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationState is a migration together with whether it has been applied.
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified is set when the up file changed after it was applied.
+	Modified bool
+	// Unknown is set for an applied version that this build does not have,
+	// e.g. after rolling back to an older release.
+	Unknown bool
+}
+
+type schemaMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrations returns the embedded migrations in version order.
+func Migrations() ([]Migration, error) {
+	return loadMigrations(migrations.Files)
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range names {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+		direction := path.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			return nil, fmt.Errorf("migration %s: name must end in .up.sql or .down.sql", file)
+		}
+		base = strings.TrimSuffix(base, direction)
+
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>", file)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", file, prefix)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, name)
+		}
+		if direction == ".up" {
+			m.Up = string(data)
+			sum := sha256.Sum256(data)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// MigrateUp applies every pending migration in version order and returns the
+// ones it applied. Concurrent callers wait for each other, so every replica
+// may run it on deploy.
+func MigrateUp(ctx context.Context, db *gorm.DB) ([]Migration, error) {
+	list, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = withMigrationLock(ctx, db, func() error {
+		done, err := appliedMigrations(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, m := range list {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if err := applyMigration(ctx, db, m); err != nil {
+				return err
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first, and
+// returns the ones it reverted.
+func MigrateDown(ctx context.Context, db *gorm.DB, steps int) ([]Migration, error) {
+	list, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int64]Migration, len(list))
+	for _, m := range list {
+		known[m.Version] = m
+	}
+
+	var reverted []Migration
+	err = withMigrationLock(ctx, db, func() error {
+		done, err := appliedMigrations(ctx, db)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(done))
+		for version := range done {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			m, ok := known[versions[i]]
+			if !ok {
+				return fmt.Errorf("migration %d is applied but not part of this build", versions[i])
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted: it has no down file", m.Version, m.Name)
+			}
+			if err := revertMigration(ctx, db, m); err != nil {
+				return err
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatus lists every known and every applied migration.
+func MigrationStatus(ctx context.Context, db *gorm.DB) ([]MigrationState, error) {
+	list, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTables(ctx, db); err != nil {
+		return nil, err
+	}
+	done, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(list))
+	for _, m := range list {
+		state := MigrationState{Migration: m}
+		if row, ok := done[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+			state.Modified = row.Checksum != m.Checksum
+			delete(done, m.Version)
+		}
+		states = append(states, state)
+	}
+	for _, row := range done {
+		appliedAt := row.AppliedAt
+		states = append(states, MigrationState{
+			Migration: Migration{Version: row.Version, Name: row.Name, Checksum: row.Checksum},
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// CheckSchemaVersion fails with ErrSchemaOutdated when a migration of this
+// build has not been applied. Applied migrations this build does not know
+// only log a warning, so an older release can still start after a rollback.
+func CheckSchemaVersion(ctx context.Context, db *gorm.DB) error {
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, s := range states {
+		switch {
+		case !s.Applied:
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		case s.Unknown:
+			zap.L().Warn("Database has a migration this build does not know",
+				zap.String("component", "database"),
+				zap.Int64("version", s.Version),
+				zap.String("name", s.Name),
+			)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func ensureMigrationTables(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			checksum varchar(64) NOT NULL,
+			applied_at timestamptz NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+			id int PRIMARY KEY,
+			owner text NOT NULL,
+			acquired_at timestamptz NOT NULL
+		);
+	`).Error
+}
+
+func appliedMigrations(ctx context.Context, db *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+func applyMigration(ctx context.Context, db *gorm.DB, m Migration) error {
+	log := zap.L().With(zap.String("component", "database"))
+	log.Info("Applying migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(m.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{
+			Version:   m.Version,
+			Name:      m.Name,
+			Checksum:  m.Checksum,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+func revertMigration(ctx context.Context, db *gorm.DB, m Migration) error {
+	log := zap.L().With(zap.String("component", "database"))
+	log.Info("Reverting migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(m.Down).Error; err != nil {
+			return err
+		}
+		return tx.Where("version = ?", m.Version).Delete(&schemaMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("revert migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// withMigrationLock runs fn while holding the row lock in
+// schema_migrations_lock. YugabyteDB does not reliably support advisory
+// locks, so the lock is a lease row that a single upsert either takes, or
+// takes over once it is older than migrationLockTTL.
+func withMigrationLock(ctx context.Context, db *gorm.DB, fn func() error) error {
+	if err := ensureMigrationTables(ctx, db); err != nil {
+		return err
+	}
+
+	owner := migrationLockOwner()
+	for {
+		result := db.WithContext(ctx).Exec(`
+			INSERT INTO schema_migrations_lock (id, owner, acquired_at) VALUES (1, ?, now())
+			ON CONFLICT (id) DO UPDATE SET owner = EXCLUDED.owner, acquired_at = EXCLUDED.acquired_at
+			WHERE schema_migrations_lock.acquired_at < now() - make_interval(secs => ?)
+		`, owner, migrationLockTTL.Seconds())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			break
+		}
+
+		zap.L().Info("Waiting for the migration lock", zap.String("component", "database"))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(migrationLockPoll):
+		}
+	}
+
+	defer func() {
+		// Release even when ctx is done, or the next run waits out the TTL.
+		err := db.WithContext(context.WithoutCancel(ctx)).
+			Exec("DELETE FROM schema_migrations_lock WHERE id = 1 AND owner = ?", owner).Error
+		if err != nil {
+			zap.L().Warn("Failed to release the migration lock", zap.String("component", "database"), zap.Error(err))
+		}
+	}()
+
+	return fn()
+}
+
+func migrationLockOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d/%s", host, os.Getpid(), uuid.NewString())
+}
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS reconciliation_items;
+DROP TABLE IF EXISTS reconciliation_daily_totals;
+DROP TABLE IF EXISTS reconciliation_reports;
+DROP TABLE IF EXISTS payment_acleda_refunds;
+DROP TABLE IF EXISTS payment_acleda_payment_links;
+DROP TABLE IF EXISTS merchant_acleda_credentials;
+DROP TABLE IF EXISTS merchant_currencies;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS countries;
+DROP TABLE IF EXISTS currencies;
+DROP TABLE IF EXISTS payment_methods;
+DROP TABLE IF EXISTS merchants;
+DROP TABLE IF EXISTS va_providers;
+DROP TABLE IF EXISTS ewallet_providers;
@@ -0,0 +1,304 @@
+-- Baseline: the schema AutoMigrate used to maintain. Every statement is
+-- guarded, so databases created before versioned migrations only record it.
+
+-- countries.country_id was renamed to code.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'countries' AND column_name = 'country_id'
+    ) THEN
+        ALTER TABLE countries RENAME COLUMN country_id TO code;
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS ewallet_providers (
+    id uuid,
+    name text,
+    provider_name text,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ewallet_providers_provider_name ON ewallet_providers (provider_name);
+
+CREATE TABLE IF NOT EXISTS va_providers (
+    id uuid,
+    name text,
+    provider_name text,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_va_providers_provider_name ON va_providers (provider_name);
+
+CREATE TABLE IF NOT EXISTS merchants (
+    id uuid,
+    name text,
+    code text,
+    username text,
+    password text,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_code ON merchants (code);
+
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id uuid,
+    name text,
+    code text,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_methods_code ON payment_methods (code);
+
+CREATE TABLE IF NOT EXISTS currencies (
+    id uuid,
+    name text,
+    code text,
+    numeric_code varchar(3),
+    minor_unit bigint DEFAULT 2,
+    min_amount numeric(20,4),
+    max_amount numeric(20,4),
+    enabled boolean DEFAULT false,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_currencies_code ON currencies (code);
+
+CREATE TABLE IF NOT EXISTS countries (
+    id uuid,
+    name text,
+    code text,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_countries_code ON countries (code);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id uuid,
+    transaction_id text,
+    payment_gateway text,
+    reference_no text,
+    payment_method_id uuid,
+    currency_id uuid,
+    amount decimal,
+    description text,
+    status text,
+    expired_payment timestamptz,
+    callback_url text,
+    merchant_id uuid,
+    country_id uuid,
+    response_json jsonb,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    deleted_date bigint,
+    deleted_user text,
+    deleted_ip text,
+    data_status text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_payments_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT fk_payments_payment_method FOREIGN KEY (payment_method_id) REFERENCES payment_methods (id) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT fk_payments_currency FOREIGN KEY (currency_id) REFERENCES currencies (id) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT fk_payments_country FOREIGN KEY (country_id) REFERENCES countries (id) ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_transaction_id ON payments (transaction_id);
+
+CREATE TABLE IF NOT EXISTS merchant_currencies (
+    id uuid,
+    merchant_id uuid,
+    currency_code varchar(3),
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_merchant_currencies_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchant_currencies_merchant_currency ON merchant_currencies (merchant_id,currency_code);
+
+CREATE TABLE IF NOT EXISTS merchant_acleda_credentials (
+    id uuid,
+    merchant_id uuid,
+    acleda_merchant_id varchar(255),
+    login_id varchar(255),
+    password text,
+    secret text,
+    created_date bigint,
+    created_user text,
+    created_ip text,
+    updated_date bigint,
+    updated_user text,
+    updated_ip text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_merchant_acleda_credentials_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchant_acleda_credentials_merchant_id ON merchant_acleda_credentials (merchant_id);
+
+CREATE TABLE IF NOT EXISTS payment_acleda_payment_links (
+    id varchar(255),
+    transaction_id varchar(255),
+    merchant_id varchar(255),
+    session_id varchar(255),
+    payment_token_id varchar(255),
+    description text,
+    amount numeric(20,4),
+    payment_currency varchar(10),
+    invoice_id varchar(255),
+    status varchar(50),
+    expiry_time bigint,
+    created_at timestamptz,
+    updated_at timestamptz,
+    purchase_amount numeric(20,4),
+    purchase_date bigint,
+    quantity bigint,
+    confirm_date bigint,
+    purchase_type bigint,
+    save_token bigint,
+    fee_amount numeric(20,4),
+    tx_direction bigint,
+    acleda_merchant_id varchar(255),
+    return_url text,
+    error_url text,
+    request_json text,
+    response_json text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_acleda_payment_links_transaction_id ON payment_acleda_payment_links (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_payment_links_merchant_created ON payment_acleda_payment_links (merchant_id,created_at);
+
+CREATE TABLE IF NOT EXISTS payment_acleda_refunds (
+    id varchar(255),
+    payment_link_id varchar(255),
+    transaction_id varchar(255),
+    type varchar(20),
+    amount numeric(20,4),
+    currency varchar(10),
+    reason text,
+    status varchar(50),
+    bank_reference varchar(255),
+    error_details text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    request_json text,
+    response_json text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_payment_acleda_refunds_payment_link FOREIGN KEY (payment_link_id) REFERENCES payment_acleda_payment_links (id) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_payment_acleda_refunds_payment_link_id ON payment_acleda_refunds (payment_link_id);
+CREATE INDEX IF NOT EXISTS idx_payment_acleda_refunds_transaction_id ON payment_acleda_refunds (transaction_id);
+
+CREATE TABLE IF NOT EXISTS reconciliation_reports (
+    id varchar(255),
+    file_name varchar(255),
+    file_checksum varchar(64),
+    source varchar(20),
+    total_lines bigint,
+    matched_count bigint,
+    mismatched_count bigint,
+    unmatched_count bigint,
+    created_by varchar(255),
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_reports_created_at ON reconciliation_reports (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliation_reports_file_checksum ON reconciliation_reports (file_checksum);
+
+CREATE TABLE IF NOT EXISTS reconciliation_daily_totals (
+    id bigserial,
+    report_id varchar(255),
+    settlement_date varchar(10),
+    currency varchar(10),
+    bank_amount numeric(20,4),
+    bank_fee_amount numeric(20,4),
+    recorded_amount numeric(20,4),
+    recorded_fee numeric(20,4),
+    matched_count bigint,
+    mismatched_count bigint,
+    unmatched_count bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_reconciliation_reports_daily_totals FOREIGN KEY (report_id) REFERENCES reconciliation_reports (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_daily_totals_settlement_date ON reconciliation_daily_totals (settlement_date);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_daily_totals_report_id ON reconciliation_daily_totals (report_id);
+
+CREATE TABLE IF NOT EXISTS reconciliation_items (
+    id varchar(255),
+    report_id varchar(255),
+    line_no bigint,
+    invoice_id varchar(255),
+    session_id varchar(255),
+    transaction_id varchar(255),
+    payment_link_id varchar(255),
+    bank_amount numeric(20,4),
+    bank_fee_amount numeric(20,4),
+    bank_status varchar(50),
+    currency varchar(10),
+    settled_at timestamptz,
+    recorded_amount numeric(20,4),
+    recorded_fee numeric(20,4),
+    recorded_status varchar(50),
+    match_status varchar(20),
+    mismatches text,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_reconciliation_items_report FOREIGN KEY (report_id) REFERENCES reconciliation_reports (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_items_match_status ON reconciliation_items (match_status);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_items_payment_link_id ON reconciliation_items (payment_link_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_items_report_id ON reconciliation_items (report_id);
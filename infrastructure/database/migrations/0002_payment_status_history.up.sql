@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS payment_status_history (
+    id varchar(255),
+    payment_link_id varchar(255) NOT NULL,
+    transaction_id varchar(255) NOT NULL,
+    from_status varchar(50) NOT NULL,
+    to_status varchar(50) NOT NULL,
+    source varchar(20) NOT NULL,
+    actor varchar(255),
+    evidence text,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_payment_status_history_payment_link FOREIGN KEY (payment_link_id) REFERENCES payment_acleda_payment_links (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_payment_status_history_transaction_created ON payment_status_history (transaction_id, created_at);
+
+-- Statuses used to be free strings; the state machine expects upper case.
+UPDATE payment_acleda_payment_links SET status = upper(status) WHERE status <> upper(status);
@@ -0,0 +1 @@
+ALTER TABLE payment_acleda_payment_links DROP COLUMN IF EXISTS version;
@@ -0,0 +1 @@
+ALTER TABLE payment_acleda_payment_links ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS acleda_session_outbox;
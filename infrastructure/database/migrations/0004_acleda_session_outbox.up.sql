@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS acleda_session_outbox (
+    id varchar(255),
+    transaction_id varchar(255) NOT NULL,
+    merchant_id varchar(255),
+    acleda_merchant_id varchar(255),
+    session_id varchar(255),
+    payment_token_id varchar(255),
+    status varchar(20) NOT NULL,
+    link_json text,
+    last_error text,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_acleda_session_outbox_transaction_id ON acleda_session_outbox (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_acleda_session_outbox_status_created ON acleda_session_outbox (status, created_at);
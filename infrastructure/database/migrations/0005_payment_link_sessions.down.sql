@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payment_acleda_link_sessions;
+ALTER TABLE payment_acleda_payment_links DROP COLUMN IF EXISTS session_expiry_time;
+ALTER TABLE payment_acleda_payment_links DROP COLUMN IF EXISTS session_opened_at;
@@ -0,0 +1,23 @@
+ALTER TABLE payment_acleda_payment_links ADD COLUMN IF NOT EXISTS session_opened_at timestamptz;
+ALTER TABLE payment_acleda_payment_links ADD COLUMN IF NOT EXISTS session_expiry_time bigint;
+
+CREATE TABLE IF NOT EXISTS payment_acleda_link_sessions (
+    id varchar(255),
+    payment_link_id varchar(255) NOT NULL,
+    transaction_id varchar(255) NOT NULL,
+    session_id varchar(255),
+    payment_token_id varchar(255),
+    acleda_merchant_id varchar(255),
+    expiry_time bigint,
+    opened_at timestamptz,
+    replaced_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_payment_acleda_link_sessions_payment_link FOREIGN KEY (payment_link_id) REFERENCES payment_acleda_payment_links (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_payment_acleda_link_sessions_payment_link_id ON payment_acleda_link_sessions (payment_link_id);
+CREATE INDEX IF NOT EXISTS idx_payment_acleda_link_sessions_session_id ON payment_acleda_link_sessions (session_id);
+
+-- Until now every link kept the session it was created with.
+UPDATE payment_acleda_payment_links
+SET session_opened_at = created_at, session_expiry_time = expiry_time
+WHERE session_opened_at IS NULL;
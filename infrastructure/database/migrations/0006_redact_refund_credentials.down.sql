@@ -0,0 +1,2 @@
+-- Redacted credentials cannot be restored.
+SELECT 1;
@@ -0,0 +1,5 @@
+-- Refund requests used to be stored with the Acleda login, password and
+-- signature in plain text. Mask them the way the gateway now does.
+UPDATE payment_acleda_refunds
+SET request_json = (request_json::jsonb || '{"loginId": "[REDACTED]", "password": "[REDACTED]", "signature": "[REDACTED]"}'::jsonb)::text
+WHERE CASE WHEN request_json LIKE '{%' THEN request_json::jsonb ? 'password' AND request_json::jsonb ->> 'password' <> '[REDACTED]' ELSE false END;
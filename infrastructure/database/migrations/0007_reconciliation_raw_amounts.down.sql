@@ -0,0 +1,2 @@
+ALTER TABLE reconciliation_items DROP COLUMN IF EXISTS bank_fee_raw;
+ALTER TABLE reconciliation_items DROP COLUMN IF EXISTS bank_amount_raw;
@@ -0,0 +1,2 @@
+ALTER TABLE reconciliation_items ADD COLUMN IF NOT EXISTS bank_amount_raw varchar(64);
+ALTER TABLE reconciliation_items ADD COLUMN IF NOT EXISTS bank_fee_raw varchar(64);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_acleda_session_outbox_transaction;
+DROP INDEX IF EXISTS idx_acleda_session_outbox_first_session;
+DELETE FROM acleda_session_outbox WHERE replaces_session_id <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_acleda_session_outbox_transaction_id ON acleda_session_outbox (transaction_id);
+ALTER TABLE acleda_session_outbox DROP COLUMN IF EXISTS replaces_session_id;
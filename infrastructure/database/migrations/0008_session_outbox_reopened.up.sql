@@ -0,0 +1,6 @@
+-- Re-opened sessions get outbox records of their own, so a transaction can
+-- have several. Only its first session stays unique.
+ALTER TABLE acleda_session_outbox ADD COLUMN IF NOT EXISTS replaces_session_id varchar(255) NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_acleda_session_outbox_transaction_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_acleda_session_outbox_first_session ON acleda_session_outbox (transaction_id) WHERE replaces_session_id = '';
+CREATE INDEX IF NOT EXISTS idx_acleda_session_outbox_transaction ON acleda_session_outbox (transaction_id);
@@ -0,0 +1,13 @@
+// Package migrations embeds the versioned schema migrations.
+//
+// Each migration is a pair of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, applied in version order by the database
+// package. YugabyteDB runs DDL outside the surrounding transaction, so every
+// up migration must be safe to run again after a partial failure: guard
+// statements with IF NOT EXISTS / IF EXISTS.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
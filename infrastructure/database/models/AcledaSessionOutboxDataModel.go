@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AcledaSessionOutboxDataModel struct {
+	ID            string `gorm:"primaryKey;column:id;type:varchar(255)"`
+	TransactionID string `gorm:"column:transaction_id;type:varchar(255)"`
+	// ReplacesSessionID is set for a session re-opened for an existing link.
+	// Only the first session of a transaction has it empty.
+	ReplacesSessionID string `gorm:"column:replaces_session_id;type:varchar(255)"`
+	MerchantID        string `gorm:"column:merchant_id;type:varchar(255)"`
+	AcledaMerchantID  string `gorm:"column:acleda_merchant_id;type:varchar(255)"`
+	SessionID         string `gorm:"column:session_id;type:varchar(255)"`
+	PaymentTokenID    string `gorm:"column:payment_token_id;type:varchar(255)"`
+	Status            string `gorm:"column:status;type:varchar(20)"`
+	// LinkJSON is the PaymentAcledaPaymentLinksDataModel to store once the
+	// database is reachable again, set while the session is OPENED. For a
+	// re-opened session it is the PaymentLinkSessionDataModel to record.
+	LinkJSON  string    `gorm:"column:link_json;type:text"`
+	LastError string    `gorm:"column:last_error;type:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (AcledaSessionOutboxDataModel) TableName() string {
+	return "acleda_session_outbox"
+}
+
+func (p *AcledaSessionOutboxDataModel) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Convert to entity
+func (p *AcledaSessionOutboxDataModel) ToEntity() entities.AcledaSession {
+	return entities.AcledaSession{
+		ID:                p.ID,
+		TransactionID:     p.TransactionID,
+		ReplacesSessionID: p.ReplacesSessionID,
+		MerchantID:        p.MerchantID,
+		AcledaMerchantID:  p.AcledaMerchantID,
+		SessionID:         p.SessionID,
+		PaymentTokenID:    p.PaymentTokenID,
+		Status:            p.Status,
+		LastError:         p.LastError,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
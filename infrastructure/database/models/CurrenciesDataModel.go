@@ -1,11 +1,20 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+)
 
 type CurrenciesDataModel struct {
 	ID          uuid.UUID `gorm:"primaryKey;column:id;type:uuid"`
 	Name        string    `gorm:"column:name"`
 	Code        string    `gorm:"column:code;uniqueIndex"`
+	NumericCode string    `gorm:"column:numeric_code;type:varchar(3)"`
+	MinorUnit   int       `gorm:"column:minor_unit;default:2"`
+	MinAmount   *string   `gorm:"column:min_amount;type:numeric(20,4)"`
+	MaxAmount   *string   `gorm:"column:max_amount;type:numeric(20,4)"`
+	Enabled     bool      `gorm:"column:enabled;default:false"`
 	CreatedDate *int64
 	CreatedUser *string
 	CreatedIp   *string
@@ -17,3 +26,24 @@ type CurrenciesDataModel struct {
 	DeletedIp   *string
 	DataStatus  *string
 }
+
+// Convert to entity
+func (c *CurrenciesDataModel) ToEntity() entities.Currency {
+	currency := entities.Currency{
+		ID:          c.ID.String(),
+		Code:        c.Code,
+		NumericCode: c.NumericCode,
+		Name:        c.Name,
+		MinorUnit:   c.MinorUnit,
+		MinAmount:   entities.NewMoney(0, c.Code),
+		MaxAmount:   entities.NewMoney(0, c.Code),
+		Enabled:     c.Enabled,
+	}
+	if c.MinAmount != nil {
+		currency.MinAmount = moneyFromColumn(*c.MinAmount, c.Code)
+	}
+	if c.MaxAmount != nil {
+		currency.MaxAmount = moneyFromColumn(*c.MaxAmount, c.Code)
+	}
+	return currency
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"payment-airpay/infrastructure/crypto"
+
+	"github.com/google/uuid"
+)
+
+// MerchantAcledaCredentialsDataModel holds a merchant's own Acleda account.
+// Password and Secret are encrypted at rest.
+type MerchantAcledaCredentialsDataModel struct {
+	ID               uuid.UUID              `gorm:"primaryKey;column:id;type:uuid"`
+	MerchantID       uuid.UUID              `gorm:"column:merchant_id;type:uuid;uniqueIndex"`
+	AcledaMerchantID string                 `gorm:"column:acleda_merchant_id;type:varchar(255)"`
+	LoginID          string                 `gorm:"column:login_id;type:varchar(255)"`
+	Password         crypto.EncryptedSecret `gorm:"column:password;type:text"`
+	Secret           crypto.EncryptedSecret `gorm:"column:secret;type:text"`
+	CreatedDate      *int64
+	CreatedUser      *string
+	CreatedIp        *string
+	UpdatedDate      *int64
+	UpdatedUser      *string
+	UpdatedIp        *string
+
+	Merchant MerchantsDataModel `gorm:"foreignKey:MerchantID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
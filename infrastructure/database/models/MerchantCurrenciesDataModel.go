@@ -0,0 +1,16 @@
+package models
+
+import "github.com/google/uuid"
+
+// MerchantCurrenciesDataModel lists the currencies a merchant may charge in.
+// A merchant without rows may use every enabled currency.
+type MerchantCurrenciesDataModel struct {
+	ID           uuid.UUID `gorm:"primaryKey;column:id;type:uuid"`
+	MerchantID   uuid.UUID `gorm:"column:merchant_id;type:uuid;uniqueIndex:idx_merchant_currencies_merchant_currency,priority:1"`
+	CurrencyCode string    `gorm:"column:currency_code;type:varchar(3);uniqueIndex:idx_merchant_currencies_merchant_currency,priority:2"`
+	CreatedDate  *int64
+	CreatedUser  *string
+	CreatedIp    *string
+
+	Merchant MerchantsDataModel `gorm:"foreignKey:MerchantID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -1,13 +1,17 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"payment-airpay/infrastructure/crypto"
+
+	"github.com/google/uuid"
+)
 
 type MerchantsDataModel struct {
-	ID          uuid.UUID `gorm:"primaryKey;column:id;type:uuid"`
-	Name        string    `gorm:"column:name"`
-	Code        string    `gorm:"column:code;uniqueIndex"`
-	Username    string    `gorm:"column:username"`
-	Password    string    `gorm:"column:password"`
+	ID          uuid.UUID              `gorm:"primaryKey;column:id;type:uuid"`
+	Name        string                 `gorm:"column:name"`
+	Code        string                 `gorm:"column:code;uniqueIndex"`
+	Username    string                 `gorm:"column:username"`
+	Password    crypto.EncryptedString `gorm:"column:password"`
 	CreatedDate *int64
 	CreatedUser *string
 	CreatedIp   *string
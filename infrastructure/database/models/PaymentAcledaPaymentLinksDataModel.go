@@ -6,33 +6,44 @@ import (
 	"payment-airpay/domain/entities"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type PaymentAcledaPaymentLinksDataModel struct {
 	ID              string    `gorm:"primaryKey;column:id;type:varchar(255)"`
 	TransactionID   string    `gorm:"column:transaction_id;uniqueIndex;type:varchar(255)"`
-	MerchantID      string    `gorm:"column:merchant_id;type:varchar(255)"`
+	MerchantID      string    `gorm:"column:merchant_id;type:varchar(255);index:idx_payment_links_merchant_created,priority:1"`
 	SessionID       string    `gorm:"column:session_id;type:varchar(255)"`
 	PaymentTokenID  string    `gorm:"column:payment_token_id;type:varchar(255)"`
 	Description     string    `gorm:"column:description;type:text"`
-	Amount          float64   `gorm:"column:amount"`
+	Amount          string    `gorm:"column:amount;type:numeric(20,4)"`
 	PaymentCurrency string    `gorm:"column:payment_currency;type:varchar(10)"`
 	InvoiceID       string    `gorm:"column:invoice_id;type:varchar(255)"`
 	Status          string    `gorm:"column:status;type:varchar(50)"`
 	ExpiryTime      int       `gorm:"column:expiry_time"`
-	CreatedAt       time.Time `gorm:"column:created_at"`
+	CreatedAt       time.Time `gorm:"column:created_at;index:idx_payment_links_merchant_created,priority:2"`
 	UpdatedAt       time.Time `gorm:"column:updated_at"`
+	// Version guards concurrent updates: every update must match it and
+	// increment it.
+	Version int64 `gorm:"column:version;not null;default:1"`
 
 	// Additional fields from Acleda response
-	PurchaseAmount float64 `gorm:"column:purchase_amount"`
-	PurchaseDate   int64   `gorm:"column:purchase_date"`
-	Quantity       int     `gorm:"column:quantity"`
-	ConfirmDate    int64   `gorm:"column:confirm_date"`
-	PurchaseType   int     `gorm:"column:purchase_type"`
-	SaveToken      int     `gorm:"column:save_token"`
-	FeeAmount      float64 `gorm:"column:fee_amount"`
-	TxDirection    int     `gorm:"column:tx_direction"`
+	PurchaseAmount string `gorm:"column:purchase_amount;type:numeric(20,4)"`
+	PurchaseDate   int64  `gorm:"column:purchase_date"`
+	Quantity       int    `gorm:"column:quantity"`
+	ConfirmDate    int64  `gorm:"column:confirm_date"`
+	PurchaseType   int    `gorm:"column:purchase_type"`
+	SaveToken      int    `gorm:"column:save_token"`
+	FeeAmount      string `gorm:"column:fee_amount;type:numeric(20,4)"`
+	TxDirection    int    `gorm:"column:tx_direction"`
+
+	// Acleda account the session was opened under
+	AcledaMerchantID string `gorm:"column:acleda_merchant_id;type:varchar(255)"`
+
+	// Current session; earlier ones are in PaymentLinkSessionDataModel
+	SessionOpenedAt   *time.Time `gorm:"column:session_opened_at"`
+	SessionExpiryTime int        `gorm:"column:session_expiry_time"`
 
 	// URLs
 	ReturnURL string `gorm:"column:return_url;type:text"`
@@ -51,41 +62,66 @@ func (p *PaymentAcledaPaymentLinksDataModel) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == "" {
 		p.ID = uuid.New().String()
 	}
+	if p.Version == 0 {
+		p.Version = 1
+	}
 	return nil
 }
 
 // Convert to entity
 func (p *PaymentAcledaPaymentLinksDataModel) ToEntity() entities.PaymentAcledaPaymentLink {
-	return entities.PaymentAcledaPaymentLink{
-		ID:              p.ID,
-		TransactionID:   p.TransactionID,
-		MerchantID:      p.MerchantID,
-		SessionID:       p.SessionID,
-		PaymentTokenID:  p.PaymentTokenID,
-		Description:     p.Description,
-		Amount:          p.Amount,
-		Currency:        p.PaymentCurrency,
-		InvoiceID:       p.InvoiceID,
-		Status:          p.Status,
-		ExpiryTime:      p.ExpiryTime,
-		CreatedAt:       p.CreatedAt,
-		UpdatedAt:       p.UpdatedAt,
-		PaymentID:       "",
-		PaymentMethodID: "",
-		CountryID:       "",
-		MerchantCode:    p.MerchantID,
-		CurrencyID:      "",
-		PurchaseAmount:  p.PurchaseAmount,
-		PurchaseDate:    p.PurchaseDate,
-		Quantity:        p.Quantity,
-		ConfirmDate:     p.ConfirmDate,
-		PurchaseType:    p.PurchaseType,
-		SaveToken:       p.SaveToken,
-		FeeAmount:       p.FeeAmount,
-		TxDirection:     p.TxDirection,
-		ReturnURL:       p.ReturnURL,
-		ErrorURL:        p.ErrorURL,
-		RequestJSON:     p.RequestJSON,
-		ResponseJSON:    p.ResponseJSON,
+	link := entities.PaymentAcledaPaymentLink{
+		ID:               p.ID,
+		TransactionID:    p.TransactionID,
+		MerchantID:       p.MerchantID,
+		AcledaMerchantID: p.AcledaMerchantID,
+		SessionID:        p.SessionID,
+		PaymentTokenID:   p.PaymentTokenID,
+		Description:      p.Description,
+		Amount:           moneyFromColumn(p.Amount, p.PaymentCurrency),
+		Currency:         p.PaymentCurrency,
+		InvoiceID:        p.InvoiceID,
+		Status:           p.Status,
+		ExpiryTime:       p.ExpiryTime,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+		Version:          p.Version,
+		PaymentID:        "",
+		PaymentMethodID:  "",
+		CountryID:        "",
+		MerchantCode:     p.MerchantID,
+		CurrencyID:       "",
+		PurchaseAmount:   moneyFromColumn(p.PurchaseAmount, p.PaymentCurrency),
+		PurchaseDate:     p.PurchaseDate,
+		Quantity:         p.Quantity,
+		ConfirmDate:      p.ConfirmDate,
+		PurchaseType:     p.PurchaseType,
+		SaveToken:        p.SaveToken,
+		FeeAmount:        moneyFromColumn(p.FeeAmount, p.PaymentCurrency),
+		TxDirection:      p.TxDirection,
+		ReturnURL:        p.ReturnURL,
+		ErrorURL:         p.ErrorURL,
+		RequestJSON:      p.RequestJSON,
+		ResponseJSON:     p.ResponseJSON,
+	}
+	if p.SessionOpenedAt != nil {
+		link.SessionOpenedAt = *p.SessionOpenedAt
+	}
+	link.SessionExpiryTime = p.SessionExpiryTime
+	return link
+}
+
+// moneyFromColumn reads a numeric(20,4) amount column. A value that does not
+// fit its currency is logged and read as zero rather than silently rounded.
+func moneyFromColumn(value, currency string) entities.Money {
+	money, err := entities.MoneyFromDecimal(value, currency)
+	if err != nil {
+		zap.L().Warn("Invalid stored amount",
+			zap.String("component", "database"),
+			zap.String("amount", value),
+			zap.String("currency", currency),
+			zap.Error(err))
+		return entities.NewMoney(0, currency)
 	}
+	return money
 }
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentAcledaRefundsDataModel struct {
+	ID            string    `gorm:"primaryKey;column:id;type:varchar(255)"`
+	PaymentLinkID string    `gorm:"column:payment_link_id;index;type:varchar(255)"`
+	TransactionID string    `gorm:"column:transaction_id;index;type:varchar(255)"`
+	Type          string    `gorm:"column:type;type:varchar(20)"`
+	Amount        string    `gorm:"column:amount;type:numeric(20,4)"`
+	Currency      string    `gorm:"column:currency;type:varchar(10)"`
+	Reason        string    `gorm:"column:reason;type:text"`
+	Status        string    `gorm:"column:status;type:varchar(50)"`
+	BankReference string    `gorm:"column:bank_reference;type:varchar(255)"`
+	ErrorDetails  string    `gorm:"column:error_details;type:text"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at"`
+
+	// Request/Response JSON for debugging
+	RequestJSON  string `gorm:"column:request_json;type:text"`
+	ResponseJSON string `gorm:"column:response_json;type:text"`
+
+	PaymentLink PaymentAcledaPaymentLinksDataModel `gorm:"foreignKey:PaymentLinkID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (PaymentAcledaRefundsDataModel) TableName() string {
+	return "payment_acleda_refunds"
+}
+
+func (p *PaymentAcledaRefundsDataModel) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Convert to entity
+func (p *PaymentAcledaRefundsDataModel) ToEntity() entities.PaymentAcledaRefund {
+	return entities.PaymentAcledaRefund{
+		ID:            p.ID,
+		PaymentLinkID: p.PaymentLinkID,
+		TransactionID: p.TransactionID,
+		Type:          p.Type,
+		Amount:        moneyFromColumn(p.Amount, p.Currency),
+		Currency:      p.Currency,
+		Reason:        p.Reason,
+		Status:        p.Status,
+		BankReference: p.BankReference,
+		ErrorDetails:  p.ErrorDetails,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+		RequestJSON:   p.RequestJSON,
+		ResponseJSON:  p.ResponseJSON,
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentLinkSessionDataModel struct {
+	ID               string    `gorm:"primaryKey;column:id;type:varchar(255)"`
+	PaymentLinkID    string    `gorm:"column:payment_link_id;index;type:varchar(255)"`
+	TransactionID    string    `gorm:"column:transaction_id;type:varchar(255)"`
+	SessionID        string    `gorm:"column:session_id;index;type:varchar(255)"`
+	PaymentTokenID   string    `gorm:"column:payment_token_id;type:varchar(255)"`
+	AcledaMerchantID string    `gorm:"column:acleda_merchant_id;type:varchar(255)"`
+	ExpiryTime       int       `gorm:"column:expiry_time"`
+	OpenedAt         time.Time `gorm:"column:opened_at"`
+	ReplacedAt       time.Time `gorm:"column:replaced_at"`
+}
+
+func (PaymentLinkSessionDataModel) TableName() string {
+	return "payment_acleda_link_sessions"
+}
+
+func (p *PaymentLinkSessionDataModel) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Convert to entity
+func (p *PaymentLinkSessionDataModel) ToEntity() entities.PaymentLinkSession {
+	return entities.PaymentLinkSession{
+		ID:               p.ID,
+		PaymentLinkID:    p.PaymentLinkID,
+		TransactionID:    p.TransactionID,
+		SessionID:        p.SessionID,
+		PaymentTokenID:   p.PaymentTokenID,
+		AcledaMerchantID: p.AcledaMerchantID,
+		ExpiryTime:       p.ExpiryTime,
+		OpenedAt:         p.OpenedAt,
+		ReplacedAt:       p.ReplacedAt,
+	}
+}
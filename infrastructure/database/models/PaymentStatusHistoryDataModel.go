@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentStatusHistoryDataModel struct {
+	ID            string    `gorm:"primaryKey;column:id;type:varchar(255)"`
+	PaymentLinkID string    `gorm:"column:payment_link_id;type:varchar(255)"`
+	TransactionID string    `gorm:"column:transaction_id;type:varchar(255)"`
+	FromStatus    string    `gorm:"column:from_status;type:varchar(50)"`
+	ToStatus      string    `gorm:"column:to_status;type:varchar(50)"`
+	Source        string    `gorm:"column:source;type:varchar(20)"`
+	Actor         string    `gorm:"column:actor;type:varchar(255)"`
+	Evidence      string    `gorm:"column:evidence;type:text"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+func (PaymentStatusHistoryDataModel) TableName() string {
+	return "payment_status_history"
+}
+
+func (p *PaymentStatusHistoryDataModel) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (p *PaymentStatusHistoryDataModel) ToEntity() entities.PaymentStatusChange {
+	return entities.PaymentStatusChange{
+		ID:            p.ID,
+		PaymentLinkID: p.PaymentLinkID,
+		TransactionID: p.TransactionID,
+		FromStatus:    p.FromStatus,
+		ToStatus:      p.ToStatus,
+		Source:        p.Source,
+		Actor:         p.Actor,
+		Evidence:      p.Evidence,
+		CreatedAt:     p.CreatedAt,
+	}
+}
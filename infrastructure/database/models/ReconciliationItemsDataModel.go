@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReconciliationItemsDataModel struct {
+	ID             string    `gorm:"primaryKey;column:id;type:varchar(255)"`
+	ReportID       string    `gorm:"column:report_id;index;type:varchar(255)"`
+	LineNo         int       `gorm:"column:line_no"`
+	InvoiceID      string    `gorm:"column:invoice_id;type:varchar(255)"`
+	SessionID      string    `gorm:"column:session_id;type:varchar(255)"`
+	TransactionID  string    `gorm:"column:transaction_id;type:varchar(255)"`
+	PaymentLinkID  *string   `gorm:"column:payment_link_id;type:varchar(255);index"`
+	BankAmount     string    `gorm:"column:bank_amount;type:numeric(20,4)"`
+	BankFeeAmount  string    `gorm:"column:bank_fee_amount;type:numeric(20,4)"`
+	BankAmountRaw  string    `gorm:"column:bank_amount_raw;type:varchar(64)"`
+	BankFeeRaw     string    `gorm:"column:bank_fee_raw;type:varchar(64)"`
+	BankStatus     string    `gorm:"column:bank_status;type:varchar(50)"`
+	Currency       string    `gorm:"column:currency;type:varchar(10)"`
+	SettledAt      time.Time `gorm:"column:settled_at"`
+	RecordedAmount string    `gorm:"column:recorded_amount;type:numeric(20,4)"`
+	RecordedFee    string    `gorm:"column:recorded_fee;type:numeric(20,4)"`
+	RecordedStatus string    `gorm:"column:recorded_status;type:varchar(50)"`
+	MatchStatus    string    `gorm:"column:match_status;type:varchar(20);index"`
+	Mismatches     string    `gorm:"column:mismatches;type:text"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+
+	Report ReconciliationReportsDataModel `gorm:"foreignKey:ReportID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (ReconciliationItemsDataModel) TableName() string {
+	return "reconciliation_items"
+}
+
+func (m *ReconciliationItemsDataModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Convert to entity
+func (m *ReconciliationItemsDataModel) ToEntity() entities.ReconciliationItem {
+	item := entities.ReconciliationItem{
+		ID:             m.ID,
+		ReportID:       m.ReportID,
+		LineNo:         m.LineNo,
+		InvoiceID:      m.InvoiceID,
+		SessionID:      m.SessionID,
+		TransactionID:  m.TransactionID,
+		BankAmount:     moneyFromColumn(m.BankAmount, m.Currency),
+		BankFeeAmount:  moneyFromColumn(m.BankFeeAmount, m.Currency),
+		BankAmountRaw:  m.BankAmountRaw,
+		BankFeeRaw:     m.BankFeeRaw,
+		BankStatus:     m.BankStatus,
+		Currency:       m.Currency,
+		SettledAt:      m.SettledAt,
+		RecordedAmount: moneyFromColumn(m.RecordedAmount, m.Currency),
+		RecordedFee:    moneyFromColumn(m.RecordedFee, m.Currency),
+		RecordedStatus: m.RecordedStatus,
+		MatchStatus:    m.MatchStatus,
+		Mismatches:     m.Mismatches,
+		CreatedAt:      m.CreatedAt,
+	}
+	if m.PaymentLinkID != nil {
+		item.PaymentLinkID = *m.PaymentLinkID
+	}
+	return item
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"payment-airpay/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReconciliationReportsDataModel struct {
+	ID              string    `gorm:"primaryKey;column:id;type:varchar(255)"`
+	FileName        string    `gorm:"column:file_name;type:varchar(255)"`
+	FileChecksum    string    `gorm:"column:file_checksum;uniqueIndex;type:varchar(64)"`
+	Source          string    `gorm:"column:source;type:varchar(20)"`
+	TotalLines      int       `gorm:"column:total_lines"`
+	MatchedCount    int       `gorm:"column:matched_count"`
+	MismatchedCount int       `gorm:"column:mismatched_count"`
+	UnmatchedCount  int       `gorm:"column:unmatched_count"`
+	CreatedBy       string    `gorm:"column:created_by;type:varchar(255)"`
+	CreatedAt       time.Time `gorm:"column:created_at;index"`
+
+	DailyTotals []ReconciliationDailyTotalsDataModel `gorm:"foreignKey:ReportID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (ReconciliationReportsDataModel) TableName() string {
+	return "reconciliation_reports"
+}
+
+func (m *ReconciliationReportsDataModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Convert to entity
+func (m *ReconciliationReportsDataModel) ToEntity() entities.ReconciliationReport {
+	report := entities.ReconciliationReport{
+		ID:              m.ID,
+		FileName:        m.FileName,
+		FileChecksum:    m.FileChecksum,
+		Source:          m.Source,
+		TotalLines:      m.TotalLines,
+		MatchedCount:    m.MatchedCount,
+		MismatchedCount: m.MismatchedCount,
+		UnmatchedCount:  m.UnmatchedCount,
+		CreatedBy:       m.CreatedBy,
+		CreatedAt:       m.CreatedAt,
+	}
+	for i := range m.DailyTotals {
+		report.DailyTotals = append(report.DailyTotals, m.DailyTotals[i].ToEntity())
+	}
+	return report
+}
+
+type ReconciliationDailyTotalsDataModel struct {
+	ID              uint   `gorm:"primaryKey;autoIncrement;column:id"`
+	ReportID        string `gorm:"column:report_id;index;type:varchar(255)"`
+	SettlementDate  string `gorm:"column:settlement_date;type:varchar(10);index"`
+	Currency        string `gorm:"column:currency;type:varchar(10)"`
+	BankAmount      string `gorm:"column:bank_amount;type:numeric(20,4)"`
+	BankFeeAmount   string `gorm:"column:bank_fee_amount;type:numeric(20,4)"`
+	RecordedAmount  string `gorm:"column:recorded_amount;type:numeric(20,4)"`
+	RecordedFee     string `gorm:"column:recorded_fee;type:numeric(20,4)"`
+	MatchedCount    int    `gorm:"column:matched_count"`
+	MismatchedCount int    `gorm:"column:mismatched_count"`
+	UnmatchedCount  int    `gorm:"column:unmatched_count"`
+}
+
+func (ReconciliationDailyTotalsDataModel) TableName() string {
+	return "reconciliation_daily_totals"
+}
+
+// Convert to entity
+func (m *ReconciliationDailyTotalsDataModel) ToEntity() entities.ReconciliationDailyTotal {
+	return entities.ReconciliationDailyTotal{
+		SettlementDate:  m.SettlementDate,
+		Currency:        m.Currency,
+		BankAmount:      moneyFromColumn(m.BankAmount, m.Currency),
+		BankFeeAmount:   moneyFromColumn(m.BankFeeAmount, m.Currency),
+		RecordedAmount:  moneyFromColumn(m.RecordedAmount, m.Currency),
+		RecordedFee:     moneyFromColumn(m.RecordedFee, m.Currency),
+		MatchedCount:    m.MatchedCount,
+		MismatchedCount: m.MismatchedCount,
+		UnmatchedCount:  m.UnmatchedCount,
+	}
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/crypto"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AcledaCredentialRepositoryYugabyteDB stores per-merchant Acleda accounts.
+// The password and secret columns are crypto.EncryptedSecret.
+type AcledaCredentialRepositoryYugabyteDB struct {
+	db clients.YugabyteClient
+}
+
+func NewAcledaCredentialRepositoryYugabyteDB(db clients.YugabyteClient) *AcledaCredentialRepositoryYugabyteDB {
+	return &AcledaCredentialRepositoryYugabyteDB{db: db}
+}
+
+// GetByMerchantCode returns the decrypted credentials of a merchant, or
+// entities.ErrAcledaCredentialNotFound when none are configured.
+func (r *AcledaCredentialRepositoryYugabyteDB) GetByMerchantCode(ctx context.Context, merchantCode string) (*entities.AcledaCredential, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, entities.ErrAcledaCredentialNotFound
+	}
+
+	var m models.MerchantAcledaCredentialsDataModel
+	err := r.db.GetDB().WithContext(ctx).
+		Joins("JOIN merchants ON merchants.id = merchant_acleda_credentials.merchant_id").
+		Where("merchants.code = ?", merchantCode).
+		Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, entities.ErrAcledaCredentialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	credential := &entities.AcledaCredential{
+		MerchantCode:     merchantCode,
+		AcledaMerchantID: m.AcledaMerchantID,
+		LoginID:          m.LoginID,
+		Password:         string(m.Password),
+		Secret:           string(m.Secret),
+		UpdatedDate:      m.CreatedDate,
+	}
+	if m.UpdatedDate != nil {
+		credential.UpdatedDate = m.UpdatedDate
+	}
+	if m.UpdatedUser != nil {
+		credential.UpdatedUser = *m.UpdatedUser
+	} else if m.CreatedUser != nil {
+		credential.UpdatedUser = *m.CreatedUser
+	}
+	return credential, nil
+}
+
+// Save creates or replaces the credentials of a merchant. It returns
+// gorm.ErrRecordNotFound when the merchant does not exist.
+func (r *AcledaCredentialRepositoryYugabyteDB) Save(ctx context.Context, credential entities.AcledaCredential, actor entities.AuditActor) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	if crypto.DefaultKeyRing() == nil {
+		return crypto.ErrKeyNotConfigured
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var merchant models.MerchantsDataModel
+		if err := tx.Where("code = ?", credential.MerchantCode).First(&merchant).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UnixMilli()
+		row := models.MerchantAcledaCredentialsDataModel{
+			MerchantID:       merchant.ID,
+			AcledaMerchantID: credential.AcledaMerchantID,
+			LoginID:          credential.LoginID,
+			Password:         crypto.EncryptedSecret(credential.Password),
+			Secret:           crypto.EncryptedSecret(credential.Secret),
+			CreatedDate:      &now,
+			CreatedUser:      &actor.User,
+			CreatedIp:        &actor.IP,
+		}
+		return tx.Omit("Merchant").Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "merchant_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"acleda_merchant_id": row.AcledaMerchantID,
+				"login_id":           row.LoginID,
+				"password":           row.Password,
+				"secret":             row.Secret,
+				"updated_date":       now,
+				"updated_user":       actor.User,
+				"updated_ip":         actor.IP,
+			}),
+		}).Create(&row).Error
+	})
+}
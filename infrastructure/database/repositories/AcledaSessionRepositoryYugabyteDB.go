@@ -0,0 +1,343 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// unattachedSessionStatuses are the outbox states the reconciler works on.
+var unattachedSessionStatuses = []string{entities.AcledaSessionOpening, entities.AcledaSessionOpened}
+
+type AcledaSessionRepositoryYugabyteDB struct {
+	db clients.YugabyteClient
+}
+
+func NewAcledaSessionRepositoryYugabyteDB(db clients.YugabyteClient) *AcledaSessionRepositoryYugabyteDB {
+	return &AcledaSessionRepositoryYugabyteDB{db: db}
+}
+
+// RecordOpening stores session as OPENING and returns the ID of the
+// record. It must succeed before the bank is called, so that every session
+// the bank may open has a record.
+func (r *AcledaSessionRepositoryYugabyteDB) RecordOpening(ctx context.Context, session entities.AcledaSession) (string, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return "", nil
+	}
+
+	now := time.Now()
+	model := models.AcledaSessionOutboxDataModel{
+		ID:                session.ID,
+		TransactionID:     session.TransactionID,
+		ReplacesSessionID: session.ReplacesSessionID,
+		MerchantID:        session.MerchantID,
+		AcledaMerchantID:  session.AcledaMerchantID,
+		Status:            entities.AcledaSessionOpening,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := r.db.GetDB().WithContext(ctx).Create(&model).Error; err != nil {
+		return "", err
+	}
+	return model.ID, nil
+}
+
+// Attach stores link and marks session id ATTACHED in one transaction.
+func (r *AcledaSessionRepositoryYugabyteDB) Attach(ctx context.Context, id string, link entities.PaymentAcledaPaymentLink) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	model := paymentLinkToModel(link)
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&model).Error; err != nil {
+			return err
+		}
+		return markAttached(tx, id, model.SessionID, model.PaymentTokenID)
+	})
+}
+
+// MarkOpened records that the bank opened session id for link and keeps the
+// link, so that AttachStored can store it later.
+func (r *AcledaSessionRepositoryYugabyteDB) MarkOpened(ctx context.Context, id string, link entities.PaymentAcledaPaymentLink) error {
+	return r.markOpened(ctx, id, link.SessionID, link.PaymentTokenID, paymentLinkToModel(link))
+}
+
+// AttachReopened makes next the current session of link, keeping the one it
+// replaces in payment_acleda_link_sessions, and marks session id ATTACHED in
+// one transaction. link must be the link as read by the caller: if it has
+// changed since, next is kept as a prior session instead and a
+// PaymentLinkConflictError is returned. It returns the updated link.
+func (r *AcledaSessionRepositoryYugabyteDB) AttachReopened(ctx context.Context, id string, link entities.PaymentAcledaPaymentLink, next entities.PaymentLinkSession) (*entities.PaymentAcledaPaymentLink, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var updated entities.PaymentAcledaPaymentLink
+	conflict := false
+	err := r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		conflict = false
+
+		var model models.PaymentAcledaPaymentLinksDataModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transaction_id = ?", link.TransactionID).
+			First(&model).Error
+		if err != nil {
+			return err
+		}
+
+		// Another request replaced the session, or the link was paid, first.
+		// Ours stays open at the bank, so keep it for reconciliation.
+		if model.Version != link.Version || model.Status != entities.PaymentLinkStatusPending {
+			conflict = true
+			if err := recordPriorSession(tx, model.ID, next); err != nil {
+				return err
+			}
+			return markAttached(tx, id, next.SessionID, next.PaymentTokenID)
+		}
+
+		if updated, err = replaceSession(tx, model, next); err != nil {
+			return err
+		}
+		return markAttached(tx, id, next.SessionID, next.PaymentTokenID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if conflict {
+		return nil, &entities.PaymentLinkConflictError{TransactionID: link.TransactionID, Version: link.Version}
+	}
+	return &updated, nil
+}
+
+// MarkReopened records that the bank opened session id, a new session for
+// an existing link, and keeps next, so that AttachStored can record it
+// against the link later.
+func (r *AcledaSessionRepositoryYugabyteDB) MarkReopened(ctx context.Context, id string, next entities.PaymentLinkSession) error {
+	return r.markOpened(ctx, id, next.SessionID, next.PaymentTokenID, models.PaymentLinkSessionDataModel{
+		TransactionID:    next.TransactionID,
+		SessionID:        next.SessionID,
+		PaymentTokenID:   next.PaymentTokenID,
+		AcledaMerchantID: next.AcledaMerchantID,
+		ExpiryTime:       next.ExpiryTime,
+		OpenedAt:         next.OpenedAt,
+	})
+}
+
+func (r *AcledaSessionRepositoryYugabyteDB) markOpened(ctx context.Context, id, sessionID, paymentTokenID string, keep interface{}) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(keep)
+	if err != nil {
+		return err
+	}
+	return r.db.GetDB().WithContext(ctx).Model(&models.AcledaSessionOutboxDataModel{}).
+		Where("id = ? AND status = ?", id, entities.AcledaSessionOpening).
+		Updates(map[string]interface{}{
+			"status":           entities.AcledaSessionOpened,
+			"session_id":       sessionID,
+			"payment_token_id": paymentTokenID,
+			"link_json":        string(payload),
+			"updated_at":       time.Now(),
+		}).Error
+}
+
+// MarkFailed records that the bank refused to open session id.
+func (r *AcledaSessionRepositoryYugabyteDB) MarkFailed(ctx context.Context, id, reason string) error {
+	return r.setStatus(ctx, id, entities.AcledaSessionFailed, reason)
+}
+
+// MarkOrphaned flags a session that could not be attached to a payment link.
+func (r *AcledaSessionRepositoryYugabyteDB) MarkOrphaned(ctx context.Context, id, reason string) error {
+	return r.setStatus(ctx, id, entities.AcledaSessionOrphaned, reason)
+}
+
+func (r *AcledaSessionRepositoryYugabyteDB) setStatus(ctx context.Context, id, status, reason string) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	return r.db.GetDB().WithContext(ctx).Model(&models.AcledaSessionOutboxDataModel{}).
+		Where("id = ? AND status IN ?", id, unattachedSessionStatuses).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": reason,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// AttachStored attaches unattached session id to the payment link stored
+// for its transaction, or stores the link kept by MarkOpened first. A
+// re-opened session kept by MarkReopened is recorded as a prior session of
+// its link. It reports false when there is nothing to attach.
+func (r *AcledaSessionRepositoryYugabyteDB) AttachStored(ctx context.Context, id string) (bool, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return false, nil
+	}
+
+	var attached bool
+	err := r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		attached = false
+
+		var session models.AcledaSessionOutboxDataModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status IN ?", id, unattachedSessionStatuses).
+			First(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if session.ReplacesSessionID != "" {
+			if session.LinkJSON == "" {
+				return nil
+			}
+			var next models.PaymentLinkSessionDataModel
+			if err := json.Unmarshal([]byte(session.LinkJSON), &next); err != nil {
+				return err
+			}
+			var link models.PaymentAcledaPaymentLinksDataModel
+			if err := tx.Select("id").Where("transaction_id = ?", session.TransactionID).First(&link).Error; err != nil {
+				return err
+			}
+			if err := recordPriorSession(tx, link.ID, next.ToEntity()); err != nil {
+				return err
+			}
+			attached = true
+			return markAttached(tx, id, next.SessionID, next.PaymentTokenID)
+		}
+
+		var link models.PaymentAcledaPaymentLinksDataModel
+		err = tx.Where("transaction_id = ?", session.TransactionID).First(&link).Error
+		switch {
+		case err == nil:
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		case session.LinkJSON == "":
+			return nil
+		default:
+			if err := json.Unmarshal([]byte(session.LinkJSON), &link); err != nil {
+				return err
+			}
+			if err := tx.Create(&link).Error; err != nil {
+				return err
+			}
+		}
+
+		attached = true
+		return markAttached(tx, id, link.SessionID, link.PaymentTokenID)
+	})
+	return attached, err
+}
+
+// ListUnattached returns sessions created before createdBefore that are
+// still OPENING or OPENED, oldest first.
+func (r *AcledaSessionRepositoryYugabyteDB) ListUnattached(ctx context.Context, createdBefore time.Time, limit int) ([]entities.AcledaSession, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var rows []models.AcledaSessionOutboxDataModel
+	err := r.db.GetDB().WithContext(ctx).
+		Where("status IN ? AND created_at < ?", unattachedSessionStatuses, createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.AcledaSession, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, nil
+}
+
+func markAttached(tx *gorm.DB, id, sessionID, paymentTokenID string) error {
+	return tx.Model(&models.AcledaSessionOutboxDataModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":           entities.AcledaSessionAttached,
+			"session_id":       sessionID,
+			"payment_token_id": paymentTokenID,
+			"link_json":        "",
+			"last_error":       "",
+			"updated_at":       time.Now(),
+		}).Error
+}
+
+// replaceSession makes next the current session of the pending link read
+// into model and keeps the one it replaces. It returns the updated link.
+func replaceSession(tx *gorm.DB, model models.PaymentAcledaPaymentLinksDataModel, next entities.PaymentLinkSession) (entities.PaymentAcledaPaymentLink, error) {
+	current := model.ToEntity()
+	now := time.Now()
+	prior := models.PaymentLinkSessionDataModel{
+		PaymentLinkID:    model.ID,
+		TransactionID:    model.TransactionID,
+		SessionID:        model.SessionID,
+		PaymentTokenID:   model.PaymentTokenID,
+		AcledaMerchantID: model.AcledaMerchantID,
+		ExpiryTime:       model.SessionExpiryTime,
+		OpenedAt:         current.SessionOpenedAt,
+		ReplacedAt:       now,
+	}
+	if prior.OpenedAt.IsZero() {
+		prior.OpenedAt = model.CreatedAt
+	}
+	if err := tx.Create(&prior).Error; err != nil {
+		return current, err
+	}
+
+	if err := compareAndSetLink(tx, model, map[string]interface{}{
+		"session_id":          next.SessionID,
+		"payment_token_id":    next.PaymentTokenID,
+		"acleda_merchant_id":  next.AcledaMerchantID,
+		"session_opened_at":   next.OpenedAt,
+		"session_expiry_time": next.ExpiryTime,
+		"updated_at":          now,
+	}); err != nil {
+		return current, err
+	}
+
+	updated := current
+	updated.SessionID = next.SessionID
+	updated.PaymentTokenID = next.PaymentTokenID
+	updated.AcledaMerchantID = next.AcledaMerchantID
+	updated.SessionOpenedAt = next.OpenedAt
+	updated.SessionExpiryTime = next.ExpiryTime
+	updated.UpdatedAt = now
+	updated.Version = model.Version + 1
+	return updated, nil
+}
+
+// recordPriorSession keeps a session that was opened for a link but never
+// became its current one, so that settlement lines carrying it still match.
+func recordPriorSession(tx *gorm.DB, paymentLinkID string, session entities.PaymentLinkSession) error {
+	model := models.PaymentLinkSessionDataModel{
+		PaymentLinkID:    paymentLinkID,
+		TransactionID:    session.TransactionID,
+		SessionID:        session.SessionID,
+		PaymentTokenID:   session.PaymentTokenID,
+		AcledaMerchantID: session.AcledaMerchantID,
+		ExpiryTime:       session.ExpiryTime,
+		OpenedAt:         session.OpenedAt,
+		ReplacedAt:       session.ReplacedAt,
+	}
+	if model.ReplacedAt.IsZero() {
+		model.ReplacedAt = time.Now()
+	}
+	return tx.Create(&model).Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
+)
+
+type CurrencyRepositoryYugabyteDB struct {
+	db clients.YugabyteClient
+}
+
+func NewCurrencyRepositoryYugabyteDB(db clients.YugabyteClient) *CurrencyRepositoryYugabyteDB {
+	return &CurrencyRepositoryYugabyteDB{db: db}
+}
+
+// GetByCode returns the active currency with the given ISO 4217 code, or
+// gorm.ErrRecordNotFound when it is not in the catalogue.
+func (r *CurrencyRepositoryYugabyteDB) GetByCode(ctx context.Context, code string) (*entities.Currency, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var m models.CurrenciesDataModel
+	err := r.db.GetDB().WithContext(ctx).
+		Where("code = ? AND (data_status IS NULL OR data_status = ?)", strings.ToUpper(strings.TrimSpace(code)), "ACTIVE").
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+
+	currency := m.ToEntity()
+	return &currency, nil
+}
+
+// ListMerchantCurrencies returns the currency codes configured for a
+// merchant. An empty list means the merchant is not restricted.
+func (r *CurrencyRepositoryYugabyteDB) ListMerchantCurrencies(ctx context.Context, merchantCode string) ([]string, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var codes []string
+	err := r.db.GetDB().WithContext(ctx).Model(&models.MerchantCurrenciesDataModel{}).
+		Joins("JOIN merchants ON merchants.id = merchant_currencies.merchant_id").
+		Where("merchants.code = ?", merchantCode).
+		Order("merchant_currencies.currency_code ASC").
+		Pluck("merchant_currencies.currency_code", &codes).Error
+	return codes, err
+}
+
+// SetMerchantCurrencies replaces the currencies configured for a merchant.
+func (r *CurrencyRepositoryYugabyteDB) SetMerchantCurrencies(ctx context.Context, merchantCode string, codes []string, actor, ip string) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var merchant models.MerchantsDataModel
+		if err := tx.Where("code = ?", merchantCode).First(&merchant).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("merchant_id = ?", merchant.ID).
+			Delete(&models.MerchantCurrenciesDataModel{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+
+		now := time.Now().UnixMilli()
+		rows := make([]models.MerchantCurrenciesDataModel, 0, len(codes))
+		for _, code := range codes {
+			rows = append(rows, models.MerchantCurrenciesDataModel{
+				MerchantID:   merchant.ID,
+				CurrencyCode: code,
+				CreatedDate:  &now,
+				CreatedUser:  &actor,
+				CreatedIp:    &ip,
+			})
+		}
+		return tx.Omit("Merchant").Create(&rows).Error
+	})
+}
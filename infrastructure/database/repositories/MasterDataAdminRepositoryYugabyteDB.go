@@ -0,0 +1,345 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/database/clients"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// masterDataTable maps a master data kind to its table and natural key.
+type masterDataTable struct {
+	name    string
+	codeCol string
+}
+
+var masterDataTables = map[string]masterDataTable{
+	entities.MasterDataCountries:        {name: "countries", codeCol: "code"},
+	entities.MasterDataCurrencies:       {name: "currencies", codeCol: "code"},
+	entities.MasterDataPaymentMethods:   {name: "payment_methods", codeCol: "code"},
+	entities.MasterDataVAProviders:      {name: "va_providers", codeCol: "provider_name"},
+	entities.MasterDataEWalletProviders: {name: "ewallet_providers", codeCol: "provider_name"},
+}
+
+// masterDataRow is the common shape of every master data table. The
+// currency columns are only selected for currencies.
+type masterDataRow struct {
+	ID          uuid.UUID
+	Code        string
+	Name        string
+	NumericCode *string
+	MinorUnit   *int
+	MinAmount   *string
+	MaxAmount   *string
+	Enabled     *bool
+	CreatedDate *int64
+	CreatedUser *string
+	CreatedIp   *string
+	UpdatedDate *int64
+	UpdatedUser *string
+	UpdatedIp   *string
+	DeletedDate *int64
+	DeletedUser *string
+	DeletedIp   *string
+	DataStatus  *string
+}
+
+// MasterDataAdminRepositoryYugabyteDB backs the admin API for countries,
+// currencies, payment methods and VA / e-wallet providers. Deletes are soft:
+// they set DataStatus to DELETED and fill the Deleted* audit columns.
+type MasterDataAdminRepositoryYugabyteDB struct {
+	db clients.YugabyteClient
+}
+
+func NewMasterDataAdminRepositoryYugabyteDB(db clients.YugabyteClient) *MasterDataAdminRepositoryYugabyteDB {
+	return &MasterDataAdminRepositoryYugabyteDB{db: db}
+}
+
+func (r *MasterDataAdminRepositoryYugabyteDB) List(ctx context.Context, filter entities.MasterDataFilter) ([]entities.MasterData, int64, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, 0, nil
+	}
+
+	table, ok := masterDataTables[filter.Kind]
+	if !ok {
+		return nil, 0, entities.ErrUnknownMasterDataKind
+	}
+
+	query := r.db.GetDB().WithContext(ctx).Table(table.name)
+	switch strings.ToUpper(filter.DataStatus) {
+	case "ALL":
+	case entities.DataStatusDeleted:
+		query = query.Where("data_status = ?", entities.DataStatusDeleted)
+	default:
+		query = query.Where("(data_status IS NULL OR data_status = ?)", entities.DataStatusActive)
+	}
+	if filter.Code != "" {
+		query = query.Where(table.codeCol+" = ?", filter.Code)
+	}
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+escapeLike(filter.Name)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []masterDataRow
+	err := query.Select(masterDataColumns(filter.Kind, table)).
+		Order(table.codeCol + " ASC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]entities.MasterData, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].toEntity(filter.Kind))
+	}
+	return out, total, nil
+}
+
+// Get returns a record by ID, including soft-deleted ones.
+func (r *MasterDataAdminRepositoryYugabyteDB) Get(ctx context.Context, kind, id string) (*entities.MasterData, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, entities.ErrMasterDataNotFound
+	}
+	return r.get(r.db.GetDB().WithContext(ctx), kind, id)
+}
+
+// Create inserts a record. Creating a code that was soft-deleted restores
+// the old row with the new values instead of tripping the unique index.
+func (r *MasterDataAdminRepositoryYugabyteDB) Create(ctx context.Context, record entities.MasterData, actor entities.AuditActor) (*entities.MasterData, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	table, ok := masterDataTables[record.Kind]
+	if !ok {
+		return nil, entities.ErrUnknownMasterDataKind
+	}
+
+	var created *entities.MasterData
+	err := r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+
+		var existing masterDataRow
+		err := tx.Table(table.name).
+			Select("id, data_status").
+			Where(table.codeCol+" = ?", record.Code).
+			Take(&existing).Error
+		switch {
+		case err == nil && (existing.DataStatus == nil || *existing.DataStatus != entities.DataStatusDeleted):
+			return entities.ErrMasterDataConflict
+		case err == nil:
+			values := masterDataValues(record, table)
+			values["data_status"] = entities.DataStatusActive
+			values["deleted_date"] = nil
+			values["deleted_user"] = nil
+			values["deleted_ip"] = nil
+			values["updated_date"] = now
+			values["updated_user"] = actor.User
+			values["updated_ip"] = actor.IP
+			if err := tx.Table(table.name).Where("id = ?", existing.ID).Updates(values).Error; err != nil {
+				return err
+			}
+			created, err = r.get(tx, record.Kind, existing.ID.String())
+			return err
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		values := masterDataValues(record, table)
+		values["id"] = id
+		values["data_status"] = entities.DataStatusActive
+		values["created_date"] = now
+		values["created_user"] = actor.User
+		values["created_ip"] = actor.IP
+		if err := tx.Table(table.name).Create(values).Error; err != nil {
+			return err
+		}
+		created, err = r.get(tx, record.Kind, id.String())
+		return err
+	})
+	return created, err
+}
+
+// Update changes the name and, for currencies, the currency attributes of an
+// active record. The code is the natural key and cannot be changed.
+func (r *MasterDataAdminRepositoryYugabyteDB) Update(ctx context.Context, record entities.MasterData, actor entities.AuditActor) (*entities.MasterData, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	table, ok := masterDataTables[record.Kind]
+	if !ok {
+		return nil, entities.ErrUnknownMasterDataKind
+	}
+
+	values := masterDataValues(record, table)
+	delete(values, table.codeCol)
+	values["updated_date"] = time.Now().UnixMilli()
+	values["updated_user"] = actor.User
+	values["updated_ip"] = actor.IP
+
+	db := r.db.GetDB().WithContext(ctx)
+	result := db.Table(table.name).
+		Where("id = ? AND (data_status IS NULL OR data_status = ?)", record.ID, entities.DataStatusActive).
+		Updates(values)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, entities.ErrMasterDataNotFound
+	}
+	return r.get(db, record.Kind, record.ID)
+}
+
+// Delete soft-deletes an active record.
+func (r *MasterDataAdminRepositoryYugabyteDB) Delete(ctx context.Context, kind, id string, actor entities.AuditActor) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	table, ok := masterDataTables[kind]
+	if !ok {
+		return entities.ErrUnknownMasterDataKind
+	}
+
+	result := r.db.GetDB().WithContext(ctx).Table(table.name).
+		Where("id = ? AND (data_status IS NULL OR data_status = ?)", id, entities.DataStatusActive).
+		Updates(map[string]interface{}{
+			"data_status":  entities.DataStatusDeleted,
+			"deleted_date": time.Now().UnixMilli(),
+			"deleted_user": actor.User,
+			"deleted_ip":   actor.IP,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrMasterDataNotFound
+	}
+	return nil
+}
+
+func (r *MasterDataAdminRepositoryYugabyteDB) get(db *gorm.DB, kind, id string) (*entities.MasterData, error) {
+	table, ok := masterDataTables[kind]
+	if !ok {
+		return nil, entities.ErrUnknownMasterDataKind
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entities.ErrMasterDataNotFound
+	}
+
+	var row masterDataRow
+	err := db.Table(table.name).
+		Select(masterDataColumns(kind, table)).
+		Where("id = ?", id).
+		Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, entities.ErrMasterDataNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := row.toEntity(kind)
+	return &record, nil
+}
+
+func masterDataColumns(kind string, table masterDataTable) string {
+	columns := "id, " + table.codeCol + " AS code, name, " +
+		"created_date, created_user, created_ip, updated_date, updated_user, updated_ip, " +
+		"deleted_date, deleted_user, deleted_ip, data_status"
+	if kind == entities.MasterDataCurrencies {
+		columns += ", numeric_code, minor_unit, min_amount::text AS min_amount, max_amount::text AS max_amount, enabled"
+	}
+	return columns
+}
+
+func masterDataValues(record entities.MasterData, table masterDataTable) map[string]interface{} {
+	values := map[string]interface{}{
+		table.codeCol: record.Code,
+		"name":        record.Name,
+	}
+	if c := record.Currency; c != nil {
+		values["numeric_code"] = c.NumericCode
+		values["minor_unit"] = c.MinorUnit
+		values["min_amount"] = c.MinAmount
+		values["max_amount"] = c.MaxAmount
+		values["enabled"] = c.Enabled
+	}
+	return values
+}
+
+func (row *masterDataRow) toEntity(kind string) entities.MasterData {
+	record := entities.MasterData{
+		ID:          row.ID.String(),
+		Kind:        kind,
+		Code:        row.Code,
+		Name:        row.Name,
+		DataStatus:  entities.DataStatusActive,
+		CreatedDate: row.CreatedDate,
+		CreatedUser: row.CreatedUser,
+		CreatedIp:   row.CreatedIp,
+		UpdatedDate: row.UpdatedDate,
+		UpdatedUser: row.UpdatedUser,
+		UpdatedIp:   row.UpdatedIp,
+		DeletedDate: row.DeletedDate,
+		DeletedUser: row.DeletedUser,
+		DeletedIp:   row.DeletedIp,
+	}
+	if row.DataStatus != nil && *row.DataStatus != "" {
+		record.DataStatus = *row.DataStatus
+	}
+	if kind == entities.MasterDataCurrencies {
+		attrs := &entities.CurrencyAttributes{
+			MinAmount: currencyDecimal(row.MinAmount, row.Code),
+			MaxAmount: currencyDecimal(row.MaxAmount, row.Code),
+		}
+		if row.NumericCode != nil {
+			attrs.NumericCode = *row.NumericCode
+		}
+		if row.MinorUnit != nil {
+			attrs.MinorUnit = *row.MinorUnit
+		}
+		if row.Enabled != nil {
+			attrs.Enabled = *row.Enabled
+		}
+		record.Currency = attrs
+	}
+	return record
+}
+
+// currencyDecimal renders a numeric(20,4) column with the currency's own
+// decimal places, e.g. "0.0100" as "0.01" for USD.
+func currencyDecimal(value *string, code string) *string {
+	if value == nil {
+		return nil
+	}
+	money, err := entities.MoneyFromDecimal(*value, code)
+	if err != nil {
+		return value
+	}
+	s := money.String()
+	return &s
+}
+
+// escapeLike escapes the LIKE wildcards in a user-supplied search term.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
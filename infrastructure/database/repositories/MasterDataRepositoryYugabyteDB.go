@@ -1,90 +1,123 @@
 package repositories
 
 import (
-	"errors"
+	"context"
 	"strings"
 	"time"
 
+	"payment-airpay/infrastructure/database/clients"
 	"payment-airpay/infrastructure/database/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type MasterDataRepositoryYugabyteDB struct{}
+// masterDataCacheTTL bounds how long a resolved code is served from memory.
+// IDs never change, so the TTL only limits memory held for rarely used codes.
+const masterDataCacheTTL = 5 * time.Minute
 
-func NewMasterDataRepositoryYugabyteDB() *MasterDataRepositoryYugabyteDB {
-	return &MasterDataRepositoryYugabyteDB{}
+type MasterDataRepositoryYugabyteDB struct {
+	db    clients.YugabyteClient
+	cache *idCache
 }
 
-func (r *MasterDataRepositoryYugabyteDB) GetOrCreateMerchant(tx *gorm.DB, code string, name string) (uuid.UUID, error) {
-	if tx == nil {
+func NewMasterDataRepositoryYugabyteDB(db clients.YugabyteClient) *MasterDataRepositoryYugabyteDB {
+	return &MasterDataRepositoryYugabyteDB{db: db, cache: newIDCache(masterDataCacheTTL)}
+}
+
+// getOrCreate returns the ID of the row of table whose column equals key,
+// inserting row first when there is none. The insert is an
+// INSERT ... ON CONFLICT DO NOTHING, so concurrent first-time callers never
+// fail on the unique index: the one that loses reads the winner's row. A
+// conflict with a row committed after the transaction's snapshot surfaces
+// as a serialization failure, which WithTransaction retries.
+//
+// row must be a pointer to the model and id must point at its ID field.
+func (r *MasterDataRepositoryYugabyteDB) getOrCreate(ctx context.Context, table, column, key string, row interface{}, id *uuid.UUID) (uuid.UUID, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
 		return uuid.Nil, nil
 	}
 
-	code = strings.TrimSpace(code)
-	var m models.MerchantsDataModel
-	err := tx.Where("code = ?", code).First(&m).Error
-	if err == nil {
-		return m.ID, nil
+	cacheKey := table + ":" + key
+	if cached, ok := r.cache.get(cacheKey); ok {
+		return cached, nil
 	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
+
+	var found uuid.UUID
+	err := r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: column}},
+			DoNothing: true,
+		}).Create(row)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			found = *id
+			return nil
+		}
+		return tx.Table(table).Select("id").Where(column+" = ?", key).Take(&found).Error
+	})
+	if err != nil {
 		return uuid.Nil, err
 	}
 
+	r.cache.put(cacheKey, found)
+	return found, nil
+}
+
+func (r *MasterDataRepositoryYugabyteDB) GetOrCreateMerchant(ctx context.Context, code string, name string) (uuid.UUID, error) {
+	code = strings.TrimSpace(code)
 	if strings.TrimSpace(name) == "" {
 		name = code
 	}
+
 	actor := "system"
 	dataStatus := "ACTIVE"
 	now := time.Now().UnixMilli()
-
-	newM := models.MerchantsDataModel{
+	m := models.MerchantsDataModel{
 		Code:        code,
 		Name:        name,
 		CreatedDate: &now,
 		CreatedUser: &actor,
 		DataStatus:  &dataStatus,
 	}
-	if err := tx.Create(&newM).Error; err != nil {
-		return uuid.Nil, err
-	}
-	return newM.ID, nil
+	return r.getOrCreate(ctx, "merchants", "code", code, &m, &m.ID)
 }
 
-func (r *MasterDataRepositoryYugabyteDB) GetOrCreatePaymentMethod(tx *gorm.DB, code string) (uuid.UUID, error) {
+// GetMerchantByUsername returns the active merchant that owns the API username.
+func (r *MasterDataRepositoryYugabyteDB) GetMerchantByUsername(tx *gorm.DB, username string) (*models.MerchantsDataModel, error) {
 	if tx == nil {
-		return uuid.Nil, nil
+		return nil, gorm.ErrRecordNotFound
 	}
 
+	var m models.MerchantsDataModel
+	err := tx.Where("username = ? AND (data_status IS NULL OR data_status = ?)", strings.TrimSpace(username), "ACTIVE").
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *MasterDataRepositoryYugabyteDB) GetOrCreatePaymentMethod(ctx context.Context, code string) (uuid.UUID, error) {
 	code = strings.TrimSpace(code)
 	if code == "" {
 		return uuid.Nil, nil
 	}
 
-	var m models.PaymentMethodsDataModel
-	err := tx.Where("code = ?", code).First(&m).Error
-	if err == nil {
-		return m.ID, nil
-	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return uuid.Nil, err
-	}
-
 	actor := "system"
 	dataStatus := "ACTIVE"
 	now := time.Now().UnixMilli()
-	newM := models.PaymentMethodsDataModel{
+	m := models.PaymentMethodsDataModel{
 		Name:        screamingSnakeToTitle(code),
 		Code:        code,
 		CreatedDate: &now,
 		CreatedUser: &actor,
 		DataStatus:  &dataStatus,
 	}
-	if err := tx.Create(&newM).Error; err != nil {
-		return uuid.Nil, err
-	}
-	return newM.ID, nil
+	return r.getOrCreate(ctx, "payment_methods", "code", code, &m, &m.ID)
 }
 
 func screamingSnakeToTitle(s string) string {
@@ -97,65 +130,12 @@ func screamingSnakeToTitle(s string) string {
 	return strings.Join(parts, " ")
 }
 
-func (r *MasterDataRepositoryYugabyteDB) GetOrCreateCurrency(tx *gorm.DB, code string) (uuid.UUID, error) {
-	if tx == nil {
-		return uuid.Nil, nil
-	}
-
-	code = strings.TrimSpace(code)
-	if code == "" {
-		return uuid.Nil, nil
-	}
-
-	var c models.CurrenciesDataModel
-	err := tx.Where("code = ?", code).First(&c).Error
-	if err == nil {
-		return c.ID, nil
-	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return uuid.Nil, err
-	}
-
-	name := code
-	if strings.EqualFold(code, "IDR") {
-		name = "Indonesia"
-	}
-
-	actor := "system"
-	dataStatus := "ACTIVE"
-	now := time.Now().UnixMilli()
-	newC := models.CurrenciesDataModel{
-		Code:        code,
-		Name:        name,
-		CreatedDate: &now,
-		CreatedUser: &actor,
-		DataStatus:  &dataStatus,
-	}
-	if err := tx.Create(&newC).Error; err != nil {
-		return uuid.Nil, err
-	}
-	return newC.ID, nil
-}
-
-func (r *MasterDataRepositoryYugabyteDB) GetOrCreateVAProvider(tx *gorm.DB, name string, providerName string) (uuid.UUID, error) {
-	if tx == nil {
-		return uuid.Nil, nil
-	}
-
+func (r *MasterDataRepositoryYugabyteDB) GetOrCreateVAProvider(ctx context.Context, name string, providerName string) (uuid.UUID, error) {
 	providerName = strings.TrimSpace(providerName)
 	if providerName == "" {
 		return uuid.Nil, nil
 	}
 
-	var p models.VAProvidersDataModel
-	err := tx.Where("provider_name = ?", providerName).First(&p).Error
-	if err == nil {
-		return p.ID, nil
-	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return uuid.Nil, err
-	}
-
 	name = strings.TrimSpace(name)
 	if name == "" {
 		name = providerName
@@ -164,73 +144,41 @@ func (r *MasterDataRepositoryYugabyteDB) GetOrCreateVAProvider(tx *gorm.DB, name
 	actor := "system"
 	dataStatus := "ACTIVE"
 	now := time.Now().UnixMilli()
-	newP := models.VAProvidersDataModel{
+	p := models.VAProvidersDataModel{
 		Name:         name,
 		ProviderName: providerName,
 		CreatedDate:  &now,
 		CreatedUser:  &actor,
 		DataStatus:   &dataStatus,
 	}
-	if err := tx.Create(&newP).Error; err != nil {
-		return uuid.Nil, err
-	}
-	return newP.ID, nil
+	return r.getOrCreate(ctx, "va_providers", "provider_name", providerName, &p, &p.ID)
 }
 
-func (r *MasterDataRepositoryYugabyteDB) GetOrCreateEWalletProvider(tx *gorm.DB, providerName string) (uuid.UUID, error) {
-	if tx == nil {
-		return uuid.Nil, nil
-	}
-
+func (r *MasterDataRepositoryYugabyteDB) GetOrCreateEWalletProvider(ctx context.Context, providerName string) (uuid.UUID, error) {
 	providerName = strings.TrimSpace(providerName)
 	if providerName == "" {
 		return uuid.Nil, nil
 	}
 
-	var p models.EWalletProvidersDataModel
-	err := tx.Where("provider_name = ?", providerName).First(&p).Error
-	if err == nil {
-		return p.ID, nil
-	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return uuid.Nil, err
-	}
-
 	actor := "system"
 	dataStatus := "ACTIVE"
 	now := time.Now().UnixMilli()
-	newP := models.EWalletProvidersDataModel{
+	p := models.EWalletProvidersDataModel{
 		Name:         providerName,
 		ProviderName: providerName,
 		CreatedDate:  &now,
 		CreatedUser:  &actor,
 		DataStatus:   &dataStatus,
 	}
-	if err := tx.Create(&newP).Error; err != nil {
-		return uuid.Nil, err
-	}
-	return newP.ID, nil
+	return r.getOrCreate(ctx, "ewallet_providers", "provider_name", providerName, &p, &p.ID)
 }
 
-func (r *MasterDataRepositoryYugabyteDB) GetOrCreateCountry(tx *gorm.DB, countryID string) (uuid.UUID, error) {
-	if tx == nil {
-		return uuid.Nil, nil
-	}
-
+func (r *MasterDataRepositoryYugabyteDB) GetOrCreateCountry(ctx context.Context, countryID string) (uuid.UUID, error) {
 	countryID = strings.TrimSpace(countryID)
 	if countryID == "" {
 		return uuid.Nil, nil
 	}
 
-	var c models.CountriesDataModel
-	err := tx.Where("code = ?", countryID).First(&c).Error
-	if err == nil {
-		return c.ID, nil
-	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return uuid.Nil, err
-	}
-
 	name := countryID
 	if strings.EqualFold(countryID, "ID") {
 		name = "Indonesia"
@@ -239,15 +187,12 @@ func (r *MasterDataRepositoryYugabyteDB) GetOrCreateCountry(tx *gorm.DB, country
 	actor := "system"
 	dataStatus := "ACTIVE"
 	now := time.Now().UnixMilli()
-	newC := models.CountriesDataModel{
+	c := models.CountriesDataModel{
 		Code:        countryID,
 		Name:        name,
 		CreatedDate: &now,
 		CreatedUser: &actor,
 		DataStatus:  &dataStatus,
 	}
-	if err := tx.Create(&newC).Error; err != nil {
-		return uuid.Nil, err
-	}
-	return newC.ID, nil
+	return r.getOrCreate(ctx, "countries", "code", countryID, &c, &c.ID)
 }
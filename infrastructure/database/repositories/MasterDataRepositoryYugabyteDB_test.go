@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"payment-airpay/infrastructure/database/dbtest"
+
+	"github.com/google/uuid"
+)
+
+// TestGetOrCreateConcurrent starts many first-time lookups of the same code
+// at once, each through its own repository so none is served by the cache.
+// The ON CONFLICT upsert must leave one row and give every caller its ID.
+func TestGetOrCreateConcurrent(t *testing.T) {
+	db := dbtest.Open(t)
+	ctx := context.Background()
+	code := "TEST_" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "_"))
+
+	const callers = 16
+	ids := make([]uuid.UUID, callers)
+	errs := make([]error, callers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			repo := NewMasterDataRepositoryYugabyteDB(db)
+			<-start
+			ids[i], errs[i] = repo.GetOrCreatePaymentMethod(ctx, code)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("caller %d: %v", i, err)
+		}
+		if ids[i] == uuid.Nil || ids[i] != ids[0] {
+			t.Fatalf("caller %d got ID %v; caller 0 got %v", i, ids[i], ids[0])
+		}
+	}
+
+	var rows int64
+	if err := db.GetDB().Table("payment_methods").Where("code = ?", code).Count(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rows != 1 {
+		t.Fatalf("payment_methods rows for %s = %d; want 1", code, rows)
+	}
+}
+
+func TestGetOrCreateServesCachedID(t *testing.T) {
+	db := dbtest.Open(t)
+	ctx := context.Background()
+	code := "M_" + uuid.NewString()
+	repo := NewMasterDataRepositoryYugabyteDB(db)
+
+	first, err := repo.GetOrCreateMerchant(ctx, code, "Test merchant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, ok := repo.cache.get("merchants:" + code); !ok || cached != first {
+		t.Fatalf("cache after create = %v, %v; want %v, true", cached, ok, first)
+	}
+
+	again, err := NewMasterDataRepositoryYugabyteDB(db).GetOrCreateMerchant(ctx, code, "Test merchant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Fatalf("second repository got %v; want the existing row %v", again, first)
+	}
+}
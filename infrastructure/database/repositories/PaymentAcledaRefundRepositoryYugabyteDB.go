@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refundHoldingStatuses are the refund statuses that count against the
+// captured amount. Failed refunds release their hold.
+var refundHoldingStatuses = []string{entities.RefundStatusPending, entities.RefundStatusSucceeded}
+
+type PaymentAcledaRefundRepositoryYugabyteDB struct {
+	db clients.YugabyteClient
+}
+
+func NewPaymentAcledaRefundRepositoryYugabyteDB(db clients.YugabyteClient) *PaymentAcledaRefundRepositoryYugabyteDB {
+	return &PaymentAcledaRefundRepositoryYugabyteDB{db: db}
+}
+
+// Reserve inserts a PENDING refund while holding a row lock on the parent
+// payment link, so concurrent refunds cannot together exceed capturedAmount.
+// The link must still be PAID once locked.
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) Reserve(ctx context.Context, refund entities.PaymentAcledaRefund, capturedAmount entities.Money) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var link models.PaymentAcledaPaymentLinksDataModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", refund.PaymentLinkID).
+			First(&link).Error; err != nil {
+			return err
+		}
+		// The link may have been refunded or changed since the caller read it.
+		if link.Status != entities.PaymentLinkStatusPaid {
+			return fmt.Errorf("%w: status is %s", entities.ErrPaymentNotRefundable, link.Status)
+		}
+
+		var held string
+		if err := tx.Model(&models.PaymentAcledaRefundsDataModel{}).
+			Where("payment_link_id = ? AND status IN ?", refund.PaymentLinkID, refundHoldingStatuses).
+			Select("COALESCE(SUM(amount), 0)::text").
+			Scan(&held).Error; err != nil {
+			return err
+		}
+
+		heldAmount, err := entities.MoneyFromDecimal(held, refund.Currency)
+		if err != nil {
+			return err
+		}
+		total, err := heldAmount.Add(refund.Amount)
+		if err != nil {
+			return err
+		}
+		if cmp, err := total.Cmp(capturedAmount); err != nil {
+			return err
+		} else if cmp > 0 {
+			return entities.ErrRefundExceedsCaptured
+		}
+
+		model := refundToModel(refund)
+		return tx.Create(&model).Error
+	})
+}
+
+// UpdateResult records the outcome of the bank call for a reserved refund.
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) UpdateResult(ctx context.Context, refund entities.PaymentAcledaRefund) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&models.PaymentAcledaRefundsDataModel{}).
+			Where("id = ?", refund.ID).
+			Updates(map[string]interface{}{
+				"status":         refund.Status,
+				"bank_reference": refund.BankReference,
+				"error_details":  refund.ErrorDetails,
+				"request_json":   refund.RequestJSON,
+				"response_json":  refund.ResponseJSON,
+				"updated_at":     time.Now(),
+			}).Error
+	})
+}
+
+// Resolve records the final outcome of a refund that is still PENDING. It
+// returns ErrRefundNotPending when the refund was already resolved.
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) Resolve(ctx context.Context, refund entities.PaymentAcledaRefund) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&models.PaymentAcledaRefundsDataModel{}).
+			Where("id = ? AND status = ?", refund.ID, entities.RefundStatusPending).
+			Updates(map[string]interface{}{
+				"status":         refund.Status,
+				"bank_reference": refund.BankReference,
+				"error_details":  refund.ErrorDetails,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrRefundNotPending
+		}
+		return nil
+	})
+}
+
+// GetByID returns a refund, or nil when there is none.
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) GetByID(ctx context.Context, id string) (*entities.PaymentAcledaRefund, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var model models.PaymentAcledaRefundsDataModel
+	err := r.db.GetDB().WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	refund := model.ToEntity()
+	return &refund, nil
+}
+
+// ListPending returns refunds whose bank outcome is unknown and that were
+// requested before createdBefore, oldest first.
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]entities.PaymentAcledaRefund, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var rows []models.PaymentAcledaRefundsDataModel
+	err := r.db.Reader(ctx).
+		Where("status = ? AND created_at < ?", entities.RefundStatusPending, createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.PaymentAcledaRefund, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, nil
+}
+
+// SumSucceeded returns the total amount successfully refunded for a payment link.
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) SumSucceeded(ctx context.Context, paymentLinkID, currency string) (entities.Money, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return entities.NewMoney(0, currency), nil
+	}
+
+	var total string
+	err := r.db.GetDB().WithContext(ctx).Model(&models.PaymentAcledaRefundsDataModel{}).
+		Where("payment_link_id = ? AND status = ?", paymentLinkID, entities.RefundStatusSucceeded).
+		Select("COALESCE(SUM(amount), 0)::text").
+		Scan(&total).Error
+	if err != nil {
+		return entities.Money{}, err
+	}
+	return entities.MoneyFromDecimal(total, currency)
+}
+
+func (r *PaymentAcledaRefundRepositoryYugabyteDB) ListByTransactionID(ctx context.Context, transactionID string) ([]entities.PaymentAcledaRefund, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var rows []models.PaymentAcledaRefundsDataModel
+	err := r.db.Reader(ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.PaymentAcledaRefund, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, nil
+}
+
+func refundToModel(refund entities.PaymentAcledaRefund) models.PaymentAcledaRefundsDataModel {
+	return models.PaymentAcledaRefundsDataModel{
+		ID:            refund.ID,
+		PaymentLinkID: refund.PaymentLinkID,
+		TransactionID: refund.TransactionID,
+		Type:          refund.Type,
+		Amount:        refund.Amount.StorageString(),
+		Currency:      refund.Currency,
+		Reason:        refund.Reason,
+		Status:        refund.Status,
+		BankReference: refund.BankReference,
+		ErrorDetails:  refund.ErrorDetails,
+		CreatedAt:     refund.CreatedAt,
+		UpdatedAt:     refund.UpdatedAt,
+		RequestJSON:   refund.RequestJSON,
+		ResponseJSON:  refund.ResponseJSON,
+	}
+}
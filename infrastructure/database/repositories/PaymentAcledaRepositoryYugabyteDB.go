@@ -3,10 +3,15 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
+	"time"
 
 	"payment-airpay/domain/entities"
 	"payment-airpay/infrastructure/database/clients"
 	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
 )
 
 type PaymentAcledaRepositoryYugabyteDB struct {
@@ -22,36 +27,47 @@ func (r *PaymentAcledaRepositoryYugabyteDB) Create(ctx context.Context, paymentL
 		return nil
 	}
 
-	// Convert entity to model
+	model := paymentLinkToModel(paymentLink)
+	return r.db.GetDB().WithContext(ctx).Create(&model).Error
+}
+
+// paymentLinkToModel converts link for storage. The request and response
+// columns keep the link as it was created.
+func paymentLinkToModel(link entities.PaymentAcledaPaymentLink) models.PaymentAcledaPaymentLinksDataModel {
 	model := models.PaymentAcledaPaymentLinksDataModel{
-		ID:              paymentLink.ID,
-		TransactionID:   paymentLink.TransactionID,
-		MerchantID:      paymentLink.MerchantID,
-		SessionID:       paymentLink.SessionID,
-		PaymentTokenID:  paymentLink.PaymentTokenID,
-		Description:     paymentLink.Description,
-		Amount:          paymentLink.Amount,
-		PaymentCurrency: paymentLink.Currency,
-		InvoiceID:       paymentLink.InvoiceID,
-		Status:          paymentLink.Status,
-		ExpiryTime:      paymentLink.ExpiryTime,
-		CreatedAt:       paymentLink.CreatedAt,
-		UpdatedAt:       paymentLink.UpdatedAt,
-		PurchaseAmount:  paymentLink.PurchaseAmount,
-		PurchaseDate:    paymentLink.PurchaseDate,
-		Quantity:        paymentLink.Quantity,
-		ConfirmDate:     paymentLink.ConfirmDate,
-		PurchaseType:    paymentLink.PurchaseType,
-		SaveToken:       paymentLink.SaveToken,
-		FeeAmount:       paymentLink.FeeAmount,
-		TxDirection:     paymentLink.TxDirection,
-		ReturnURL:       paymentLink.ReturnURL,
-		ErrorURL:        paymentLink.ErrorURL,
-		RequestJSON:     toJSON(paymentLink),
-		ResponseJSON:    toJSON(paymentLink),
+		ID:               link.ID,
+		TransactionID:    link.TransactionID,
+		MerchantID:       link.MerchantID,
+		AcledaMerchantID: link.AcledaMerchantID,
+		SessionID:        link.SessionID,
+		PaymentTokenID:   link.PaymentTokenID,
+		Description:      link.Description,
+		Amount:           link.Amount.StorageString(),
+		PaymentCurrency:  link.Currency,
+		InvoiceID:        link.InvoiceID,
+		Status:           link.Status,
+		ExpiryTime:       link.ExpiryTime,
+		CreatedAt:        link.CreatedAt,
+		UpdatedAt:        link.UpdatedAt,
+		PurchaseAmount:   link.PurchaseAmount.StorageString(),
+		PurchaseDate:     link.PurchaseDate,
+		Quantity:         link.Quantity,
+		ConfirmDate:      link.ConfirmDate,
+		PurchaseType:     link.PurchaseType,
+		SaveToken:        link.SaveToken,
+		FeeAmount:        link.FeeAmount.StorageString(),
+		TxDirection:      link.TxDirection,
+		ReturnURL:        link.ReturnURL,
+		ErrorURL:         link.ErrorURL,
+		RequestJSON:      toJSON(link),
+		ResponseJSON:     toJSON(link),
 	}
-
-	return r.db.GetDB().WithContext(ctx).Create(&model).Error
+	if !link.SessionOpenedAt.IsZero() {
+		openedAt := link.SessionOpenedAt
+		model.SessionOpenedAt = &openedAt
+	}
+	model.SessionExpiryTime = link.SessionExpiryTime
+	return model
 }
 
 func (r *PaymentAcledaRepositoryYugabyteDB) GetByTransactionID(ctx context.Context, transactionID string) (*entities.PaymentAcledaPaymentLink, error) {
@@ -60,7 +76,11 @@ func (r *PaymentAcledaRepositoryYugabyteDB) GetByTransactionID(ctx context.Conte
 	}
 
 	var model models.PaymentAcledaPaymentLinksDataModel
-	err := r.db.GetDB().WithContext(ctx).Where("transaction_id = ?", transactionID).First(&model).Error
+	err := r.db.Reader(ctx).Where("transaction_id = ?", transactionID).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && clients.StaleReadsAllowed(ctx) {
+		// A link created moments ago may not have reached the replica yet.
+		err = r.db.GetDB().WithContext(ctx).Where("transaction_id = ?", transactionID).First(&model).Error
+	}
 	if err != nil {
 
 		return nil, err
@@ -70,14 +90,239 @@ func (r *PaymentAcledaRepositoryYugabyteDB) GetByTransactionID(ctx context.Conte
 	return &entity, nil
 }
 
-func (r *PaymentAcledaRepositoryYugabyteDB) UpdateStatus(ctx context.Context, transactionID, status string) error {
+// TransitionStatus moves a payment link to change.ToStatus and records the
+// change in payment_status_history, both in one transaction. The transition
+// is validated against the link as read and written only if the link still
+// has that version, so a concurrent callback, poll or sweep cannot be
+// overwritten; the loser gets a PaymentLinkConflictError. It returns the
+// recorded change, or nil when the link already had that status.
+func (r *PaymentAcledaRepositoryYugabyteDB) TransitionStatus(ctx context.Context, change entities.PaymentStatusChange) (*entities.PaymentStatusChange, error) {
 	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+	if change.Source == "" {
+		return nil, entities.ErrStatusChangeSourceMissing
+	}
+	to, err := entities.ParsePaymentLinkStatus(change.ToStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded *entities.PaymentStatusChange
+	err = r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		recorded = nil
+
+		var link models.PaymentAcledaPaymentLinksDataModel
+		if err := tx.Where("transaction_id = ?", change.TransactionID).First(&link).Error; err != nil {
+			return err
+		}
+		if change.ExpectedVersion != 0 && link.Version != change.ExpectedVersion {
+			return &entities.PaymentLinkConflictError{TransactionID: link.TransactionID, Version: change.ExpectedVersion}
+		}
+
+		if err := entities.ValidatePaymentLinkTransition(link.Status, to); err != nil {
+			return err
+		}
+		from, _ := entities.ParsePaymentLinkStatus(link.Status)
+		if from == to {
+			return nil
+		}
+
+		now := time.Now()
+		if err := compareAndSetLink(tx, link, map[string]interface{}{
+			"status":     to,
+			"updated_at": now,
+		}); err != nil {
+			return err
+		}
+
+		history := models.PaymentStatusHistoryDataModel{
+			PaymentLinkID: link.ID,
+			TransactionID: link.TransactionID,
+			FromStatus:    from,
+			ToStatus:      to,
+			Source:        change.Source,
+			Actor:         change.Actor,
+			Evidence:      change.Evidence,
+			CreatedAt:     now,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		entry := history.ToEntity()
+		recorded = &entry
 		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return recorded, nil
+}
 
-	return r.db.GetDB().WithContext(ctx).Model(&models.PaymentAcledaPaymentLinksDataModel{}).
+// compareAndSetLink updates fields of link and increments its version, but
+// only if the stored version is still link.Version.
+func compareAndSetLink(tx *gorm.DB, link models.PaymentAcledaPaymentLinksDataModel, fields map[string]interface{}) error {
+	fields["version"] = gorm.Expr("version + 1")
+	result := tx.Model(&models.PaymentAcledaPaymentLinksDataModel{}).
+		Where("id = ? AND version = ?", link.ID, link.Version).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &entities.PaymentLinkConflictError{TransactionID: link.TransactionID, Version: link.Version}
+	}
+	return nil
+}
+
+// ListStatusHistory returns the status changes of a payment link, oldest first.
+func (r *PaymentAcledaRepositoryYugabyteDB) ListStatusHistory(ctx context.Context, transactionID string) ([]entities.PaymentStatusChange, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var rows []models.PaymentStatusHistoryDataModel
+	err := r.db.Reader(ctx).
 		Where("transaction_id = ?", transactionID).
-		Update("status", status).Error
+		Order("created_at ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.PaymentStatusChange, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, nil
+}
+
+// paymentLinkExpiresAtSQL mirrors PaymentAcledaPaymentLink.ExpiresAt.
+const paymentLinkExpiresAtSQL = "created_at + (CASE WHEN expiry_time > 0 THEN expiry_time ELSE 60 END) * interval '1 minute'"
+
+// ListPending returns up to limit pending payment links. With expired set
+// it returns the ones whose expiry is before now, oldest first; otherwise
+// the ones still payable at now, newest first.
+func (r *PaymentAcledaRepositoryYugabyteDB) ListPending(ctx context.Context, now time.Time, expired bool, limit int) ([]entities.PaymentAcledaPaymentLink, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	condition, order := paymentLinkExpiresAtSQL+" > ?", "created_at DESC"
+	if expired {
+		condition, order = paymentLinkExpiresAtSQL+" <= ?", "created_at ASC"
+	}
+	var rows []models.PaymentAcledaPaymentLinksDataModel
+	err := r.db.GetDB().WithContext(ctx).
+		Where("status = ?", entities.PaymentLinkStatusPending).
+		Where(condition, now).
+		Order(order).
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.PaymentAcledaPaymentLink, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, nil
+}
+
+// CountByStatus returns the number of payment links per status.
+func (r *PaymentAcledaRepositoryYugabyteDB) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.GetDB().WithContext(ctx).Model(&models.PaymentAcledaPaymentLinksDataModel{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// paymentLinkSortColumns whitelists the columns a caller may sort by.
+var paymentLinkSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"amount":     "amount",
+	"status":     "status",
+}
+
+// List returns one page of payment links matching filter and the total number
+// of matching rows.
+func (r *PaymentAcledaRepositoryYugabyteDB) List(ctx context.Context, filter entities.PaymentLinkFilter) ([]entities.PaymentAcledaPaymentLink, int64, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, 0, nil
+	}
+
+	query := r.db.Reader(ctx).Model(&models.PaymentAcledaPaymentLinksDataModel{}).
+		Where("merchant_id = ?", filter.MerchantID)
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Currency != "" {
+		query = query.Where("payment_currency = ?", filter.Currency)
+	}
+	if filter.InvoiceID != "" {
+		query = query.Where("invoice_id = ?", filter.InvoiceID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.AmountMin != nil {
+		query = query.Where("amount >= ?::numeric", entities.FormatDecimal(*filter.AmountMin, entities.StorageScale))
+	}
+	if filter.AmountMax != nil {
+		query = query.Where("amount <= ?::numeric", entities.FormatDecimal(*filter.AmountMax, entities.StorageScale))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := paymentLinkSortColumns[filter.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	order := column + " DESC"
+	if strings.EqualFold(filter.SortOrder, "asc") {
+		order = column + " ASC"
+	}
+
+	var rows []models.PaymentAcledaPaymentLinksDataModel
+	err := query.Order(order).Order("id ASC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]entities.PaymentAcledaPaymentLink, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, total, nil
 }
 
 func toJSON(v interface{}) string {
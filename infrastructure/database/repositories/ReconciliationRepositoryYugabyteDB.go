@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/database/models"
+
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepositoryYugabyteDB struct {
+	db clients.YugabyteClient
+}
+
+func NewReconciliationRepositoryYugabyteDB(db clients.YugabyteClient) *ReconciliationRepositoryYugabyteDB {
+	return &ReconciliationRepositoryYugabyteDB{db: db}
+}
+
+func (r *ReconciliationRepositoryYugabyteDB) ExistsByChecksum(ctx context.Context, checksum string) (bool, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return false, nil
+	}
+
+	var count int64
+	err := r.db.GetDB().WithContext(ctx).Model(&models.ReconciliationReportsDataModel{}).
+		Where("file_checksum = ?", checksum).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindPaymentLink looks a settlement line up by invoice ID, then transaction
+// ID, then session ID, then the sessions a link has replaced. It returns nil
+// when nothing matches.
+func (r *ReconciliationRepositoryYugabyteDB) FindPaymentLink(ctx context.Context, invoiceID, transactionID, sessionID string) (*entities.PaymentAcledaPaymentLink, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	lookups := []struct {
+		column string
+		value  string
+	}{
+		{"invoice_id", invoiceID},
+		{"transaction_id", transactionID},
+		{"session_id", sessionID},
+	}
+
+	for _, lookup := range lookups {
+		if lookup.value == "" {
+			continue
+		}
+		var model models.PaymentAcledaPaymentLinksDataModel
+		err := r.db.GetDB().WithContext(ctx).Where(lookup.column+" = ?", lookup.value).First(&model).Error
+		if err == nil {
+			entity := model.ToEntity()
+			return &entity, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if sessionID == "" {
+		return nil, nil
+	}
+	var model models.PaymentAcledaPaymentLinksDataModel
+	err := r.db.GetDB().WithContext(ctx).
+		Where("id IN (?)", r.db.GetDB().Model(&models.PaymentLinkSessionDataModel{}).Select("payment_link_id").Where("session_id = ?", sessionID)).
+		First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entity := model.ToEntity()
+	return &entity, nil
+}
+
+// Save stores a report, its daily totals and every reconciled line atomically.
+func (r *ReconciliationRepositoryYugabyteDB) Save(ctx context.Context, report entities.ReconciliationReport, items []entities.ReconciliationItem) error {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil
+	}
+
+	reportModel := models.ReconciliationReportsDataModel{
+		ID:              report.ID,
+		FileName:        report.FileName,
+		FileChecksum:    report.FileChecksum,
+		Source:          report.Source,
+		TotalLines:      report.TotalLines,
+		MatchedCount:    report.MatchedCount,
+		MismatchedCount: report.MismatchedCount,
+		UnmatchedCount:  report.UnmatchedCount,
+		CreatedBy:       report.CreatedBy,
+		CreatedAt:       report.CreatedAt,
+	}
+	for _, t := range report.DailyTotals {
+		reportModel.DailyTotals = append(reportModel.DailyTotals, models.ReconciliationDailyTotalsDataModel{
+			SettlementDate:  t.SettlementDate,
+			Currency:        t.Currency,
+			BankAmount:      t.BankAmount.StorageString(),
+			BankFeeAmount:   t.BankFeeAmount.StorageString(),
+			RecordedAmount:  t.RecordedAmount.StorageString(),
+			RecordedFee:     t.RecordedFee.StorageString(),
+			MatchedCount:    t.MatchedCount,
+			MismatchedCount: t.MismatchedCount,
+			UnmatchedCount:  t.UnmatchedCount,
+		})
+	}
+
+	itemModels := make([]models.ReconciliationItemsDataModel, 0, len(items))
+	for _, item := range items {
+		m := models.ReconciliationItemsDataModel{
+			ID:             item.ID,
+			ReportID:       report.ID,
+			LineNo:         item.LineNo,
+			InvoiceID:      item.InvoiceID,
+			SessionID:      item.SessionID,
+			TransactionID:  item.TransactionID,
+			BankAmount:     item.BankAmount.StorageString(),
+			BankFeeAmount:  item.BankFeeAmount.StorageString(),
+			BankAmountRaw:  item.BankAmountRaw,
+			BankFeeRaw:     item.BankFeeRaw,
+			BankStatus:     item.BankStatus,
+			Currency:       item.Currency,
+			SettledAt:      item.SettledAt,
+			RecordedAmount: item.RecordedAmount.StorageString(),
+			RecordedFee:    item.RecordedFee.StorageString(),
+			RecordedStatus: item.RecordedStatus,
+			MatchStatus:    item.MatchStatus,
+			Mismatches:     item.Mismatches,
+			CreatedAt:      item.CreatedAt,
+		}
+		if item.PaymentLinkID != "" {
+			linkID := item.PaymentLinkID
+			m.PaymentLinkID = &linkID
+		}
+		itemModels = append(itemModels, m)
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&reportModel).Error; err != nil {
+			return err
+		}
+		if len(itemModels) == 0 {
+			return nil
+		}
+		return tx.Omit("Report").CreateInBatches(&itemModels, 500).Error
+	})
+}
+
+func (r *ReconciliationRepositoryYugabyteDB) ListReports(ctx context.Context, page, limit int) ([]entities.ReconciliationReport, int64, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, 0, nil
+	}
+
+	query := r.db.GetDB().WithContext(ctx).Model(&models.ReconciliationReportsDataModel{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.ReconciliationReportsDataModel
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]entities.ReconciliationReport, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, total, nil
+}
+
+func (r *ReconciliationRepositoryYugabyteDB) GetReport(ctx context.Context, id string) (*entities.ReconciliationReport, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, nil
+	}
+
+	var model models.ReconciliationReportsDataModel
+	err := r.db.GetDB().WithContext(ctx).
+		Preload("DailyTotals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("settlement_date ASC, currency ASC")
+		}).
+		Where("id = ?", id).
+		First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := model.ToEntity()
+	return &report, nil
+}
+
+// ListItems pages through reconciled lines. An empty reportID searches all
+// reports; matchStatuses limits the result to those statuses.
+func (r *ReconciliationRepositoryYugabyteDB) ListItems(ctx context.Context, reportID string, matchStatuses []string, page, limit int) ([]entities.ReconciliationItem, int64, error) {
+	if r == nil || r.db == nil || r.db.GetDB() == nil {
+		return nil, 0, nil
+	}
+
+	query := r.db.GetDB().WithContext(ctx).Model(&models.ReconciliationItemsDataModel{})
+	if reportID != "" {
+		query = query.Where("report_id = ?", reportID)
+	}
+	if len(matchStatuses) > 0 {
+		query = query.Where("match_status IN ?", matchStatuses)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.ReconciliationItemsDataModel
+	if err := query.Order("created_at DESC").Order("line_no ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]entities.ReconciliationItem, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].ToEntity())
+	}
+	return out, total, nil
+}
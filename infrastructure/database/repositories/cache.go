@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// idCacheSweepSize is the entry count above which a put first drops the
+// expired entries, so codes that are never looked up again do not pile up.
+const idCacheSweepSize = 1024
+
+// idCache maps keys to IDs for a limited time. It is safe for concurrent use.
+type idCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]idCacheEntry
+}
+
+type idCacheEntry struct {
+	id        uuid.UUID
+	expiresAt time.Time
+}
+
+func newIDCache(ttl time.Duration) *idCache {
+	return &idCache{ttl: ttl, entries: make(map[string]idCacheEntry)}
+}
+
+func (c *idCache) get(key string) (uuid.UUID, bool) {
+	if c == nil {
+		return uuid.Nil, false
+	}
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return uuid.Nil, false
+	}
+	return entry.id, true
+}
+
+func (c *idCache) put(key string, id uuid.UUID) {
+	if c == nil || id == uuid.Nil {
+		return
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= idCacheSweepSize {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = idCacheEntry{id: id, expiresAt: now.Add(c.ttl)}
+}
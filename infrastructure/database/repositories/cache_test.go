@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIDCacheEntriesExpire(t *testing.T) {
+	c := newIDCache(20 * time.Millisecond)
+	id := uuid.New()
+
+	c.put("payment_methods:ACLEDA", id)
+	got, ok := c.get("payment_methods:ACLEDA")
+	if !ok || got != id {
+		t.Fatalf("get before expiry = %v, %v; want %v, true", got, ok, id)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if got, ok := c.get("payment_methods:ACLEDA"); ok {
+		t.Fatalf("get after expiry = %v, true; want a miss", got)
+	}
+}
+
+func TestIDCacheSweepsExpiredEntries(t *testing.T) {
+	c := newIDCache(time.Minute)
+	past := time.Now().Add(-time.Second)
+	for i := 0; i < idCacheSweepSize; i++ {
+		c.entries[uuid.NewString()] = idCacheEntry{id: uuid.New(), expiresAt: past}
+	}
+
+	c.put("merchants:M001", uuid.New())
+	if len(c.entries) != 1 {
+		t.Fatalf("entries after sweep = %d; want 1", len(c.entries))
+	}
+}
+
+func TestIDCacheIgnoresNilID(t *testing.T) {
+	c := newIDCache(time.Minute)
+	c.put("merchants:M001", uuid.Nil)
+	if _, ok := c.get("merchants:M001"); ok {
+		t.Fatal("get after putting uuid.Nil hit; want a miss")
+	}
+
+	var nilCache *idCache
+	nilCache.put("merchants:M001", uuid.New())
+	if _, ok := nilCache.get("merchants:M001"); ok {
+		t.Fatal("get on a nil cache hit; want a miss")
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"payment-airpay/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "tracing:span"
+
+// registerTracing wraps every gorm statement in a client span that is a child
+// of the span in the statement's context (set with db.WithContext). The SQL
+// is recorded with placeholders, never with the bound values.
+func registerTracing(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+
+	cb := db.Callback()
+	cb.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create"))
+	cb.Create().After("gorm:create").Register("tracing:after_create", endSpan)
+	cb.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query"))
+	cb.Query().After("gorm:query").Register("tracing:after_query", endSpan)
+	cb.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update"))
+	cb.Update().After("gorm:update").Register("tracing:after_update", endSpan)
+	cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete"))
+	cb.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan)
+	cb.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row"))
+	cb.Row().After("gorm:row").Register("tracing:after_row", endSpan)
+	cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw"))
+	cb.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx == nil || tx.Statement == nil {
+			return
+		}
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		_, span := tracing.Tracer().Start(ctx, "db."+operation+" "+tx.Statement.Table,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system.name", "postgresql"),
+				attribute.String("db.operation.name", operation),
+				attribute.String("db.collection.name", tx.Statement.Table),
+			),
+		)
+		tx.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	if tx == nil || tx.Statement == nil {
+		return
+	}
+	value, ok := tx.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("db.query.text", tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, "")
+	}
+	span.End()
+}
@@ -1,15 +1,20 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"payment-airpay/infrastructure/configuration"
-	"payment-airpay/infrastructure/database/models"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
@@ -17,59 +22,195 @@ import (
 
 var YugabyteDBClient *gorm.DB
 
+// YugabyteReadDBClient serves reads that tolerate lag. It is nil unless read
+// hosts or follower reads are configured.
+var YugabyteReadDBClient *gorm.DB
+
 func InitializeYugabyteDB() {
-	conn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
-		configuration.AppConfig.YugabyteHost,
-		configuration.AppConfig.YugabyteUsername,
-		configuration.AppConfig.YugabytePassword,
-		configuration.AppConfig.YugabyteDatabase,
-		configuration.AppConfig.YugabytePort,
-	)
-
-	db, err := gorm.Open(postgres.Open(conn), &gorm.Config{
+	log := zap.L().With(zap.String("component", "database"))
+
+	db, err := OpenYugabyteDB(configuration.AppConfig)
+	if err != nil {
+		log.Fatal("Failed to open YugabyteDB", zap.Error(err))
+	}
+
+	YugabyteDBClient = db
+
+	reader, err := OpenYugabyteReadDB(configuration.AppConfig)
+	if err != nil {
+		log.Fatal("Failed to open YugabyteDB read connection", zap.Error(err))
+	}
+	YugabyteReadDBClient = reader
+
+	// Migrations are applied by the migrate command, not on boot, so that
+	// replicas starting together never race on DDL.
+	if err := CheckSchemaVersion(context.Background(), YugabyteDBClient); err != nil {
+		log.Fatal("Run 'migrate up' before starting the service", zap.Error(err))
+	}
+}
+
+// CloseYugabyteDB closes the connection pools of YugabyteDBClient and
+// YugabyteReadDBClient.
+func CloseYugabyteDB() error {
+	var errs []error
+	for _, db := range []*gorm.DB{YugabyteReadDBClient, YugabyteDBClient} {
+		if db == nil {
+			continue
+		}
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// OpenYugabyteDB opens a gorm connection for the given configuration and
+// registers the shared callbacks. It does not touch the YugabyteDBClient global.
+func OpenYugabyteDB(cfg *configuration.Config) (*gorm.DB, error) {
+	return openYugabyteDB(cfg, yugabyteDSN(cfg, cfg.YugabyteHost, strconv.Itoa(cfg.YugabytePort), nil))
+}
+
+// OpenYugabyteDSN opens a gorm connection to dsn, a key/value string or a
+// postgres:// URL, with the pool settings of cfg and the shared callbacks.
+// Tests use it to reach a throwaway database.
+func OpenYugabyteDSN(cfg *configuration.Config, dsn string) (*gorm.DB, error) {
+	return openYugabyteDB(cfg, dsn)
+}
+
+// OpenYugabyteReadDB opens the connection for lag-tolerant reads: to the read
+// hosts when set, otherwise to the primary with follower reads. Every session
+// is read-only. It returns nil when neither is configured.
+func OpenYugabyteReadDB(cfg *configuration.Config) (*gorm.DB, error) {
+	if cfg.YugabyteReadHosts == "" && !cfg.YugabyteFollowerReads {
+		return nil, nil
+	}
+
+	hosts, ports := cfg.YugabyteHost, strconv.Itoa(cfg.YugabytePort)
+	if cfg.YugabyteReadHosts != "" {
+		var err error
+		hosts, ports, err = splitHosts(cfg.YugabyteReadHosts, cfg.YugabytePort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := map[string]string{"default_transaction_read_only": "on"}
+	if cfg.YugabyteFollowerReads {
+		params["yb_read_from_followers"] = "on"
+		params["yb_follower_read_staleness_ms"] = strconv.Itoa(cfg.YugabyteFollowerReadStaleness)
+	}
+	return openYugabyteDB(cfg, yugabyteDSN(cfg, hosts, ports, params))
+}
+
+func openYugabyteDB(cfg *configuration.Config, dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: false,
 			NameReplacer:  strings.NewReplacer("DataModel", ""),
 		},
 	})
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	YugabyteDBClient = db
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.YugabyteMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.YugabyteMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.YugabyteConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.YugabyteConnMaxIdleTime) * time.Second)
+
+	registerUUIDv7BeforeCreate(db)
+	registerTracing(db)
+
+	return db, nil
+}
+
+// yugabyteDSN builds a key/value connection string. hosts and ports may be
+// comma separated lists of the same length; params are extra session
+// settings.
+func yugabyteDSN(cfg *configuration.Config, hosts, ports string, params map[string]string) string {
+	values := map[string]string{
+		"host":     hosts,
+		"port":     ports,
+		"user":     cfg.YugabyteUsername,
+		"password": cfg.YugabytePassword,
+		"dbname":   cfg.YugabyteDatabase,
+		"sslmode":  cfg.YugabyteSSLMode,
+	}
+	if cfg.YugabyteSSLMode == "" {
+		values["sslmode"] = "disable"
+	}
+	if cfg.YugabyteSSLRootCert != "" {
+		values["sslrootcert"] = cfg.YugabyteSSLRootCert
+	}
+	if cfg.YugabyteStatementTimeout > 0 {
+		values["statement_timeout"] = strconv.Itoa(cfg.YugabyteStatementTimeout)
+	}
+	for k, v := range params {
+		values[k] = v
+	}
 
-	registerUUIDv7BeforeCreate(YugabyteDBClient)
-
-	// Rename existing country_id column to code (idempotent)
-	YugabyteDBClient.Exec(`
-		DO $$
-		BEGIN
-			IF EXISTS (
-				SELECT 1 FROM information_schema.columns
-				WHERE table_name = 'countries' AND column_name = 'country_id'
-			) THEN
-				ALTER TABLE countries RENAME COLUMN country_id TO code;
-			END IF;
-		END $$;
-	`)
-
-	isMigration := true
-	if isMigration {
-		if err := YugabyteDBClient.AutoMigrate(
-			&models.EWalletProvidersDataModel{},
-			&models.VAProvidersDataModel{},
-			&models.PaymentsDataModel{},
-			&models.MerchantsDataModel{},
-			&models.PaymentMethodsDataModel{},
-			&models.CurrenciesDataModel{},
-			&models.CountriesDataModel{},
-			&models.PaymentAcledaPaymentLinksDataModel{},
-		); err != nil {
-			log.Fatal(err)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+quoteDSNValue(values[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes v so that spaces, quotes and backslashes, e.g. in a
+// password, survive the key/value connection string.
+func quoteDSNValue(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v)
+	return "'" + v + "'"
+}
+
+// splitHosts turns "a:5433,b" into the host and port lists of a connection
+// string, using defaultPort where none is given.
+func splitHosts(list string, defaultPort int) (string, string, error) {
+	var hosts, ports []string
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
 		}
+		host, port, err := net.SplitHostPort(entry)
+		if err != nil {
+			host, port = entry, strconv.Itoa(defaultPort)
+		}
+		if _, err := strconv.Atoi(port); err != nil || host == "" {
+			return "", "", fmt.Errorf("invalid read host %q", entry)
+		}
+		hosts = append(hosts, host)
+		ports = append(ports, port)
 	}
+	if len(hosts) == 0 {
+		return "", "", fmt.Errorf("no read hosts in %q", list)
+	}
+	return strings.Join(hosts, ","), strings.Join(ports, ","), nil
+}
 
+// MigrateYugabyteDB applies the pending migrations and then syncs the
+// currency catalogue, which follows the code rather than a migration.
+func MigrateYugabyteDB(ctx context.Context, db *gorm.DB) ([]Migration, error) {
+	applied, err := MigrateUp(ctx, db)
+	if err != nil {
+		return applied, err
+	}
+	if err := seedCurrencies(db.WithContext(ctx)); err != nil {
+		return applied, fmt.Errorf("failed to seed currencies: %w", err)
+	}
+	return applied, nil
 }
 
 func registerUUIDv7BeforeCreate(db *gorm.DB) {
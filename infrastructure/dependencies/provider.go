@@ -1,19 +1,39 @@
 package dependencies
 
 import (
+	"net/http"
 	"payment-airpay/application/services"
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/controllers"
+	"payment-airpay/infrastructure/crypto"
 	"payment-airpay/infrastructure/database"
+	"payment-airpay/infrastructure/database/clients"
 	"payment-airpay/infrastructure/database/connectors"
 	"payment-airpay/infrastructure/database/repositories"
 	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/health"
+	"payment-airpay/infrastructure/logger"
+	"payment-airpay/infrastructure/middleware"
 	"payment-airpay/infrastructure/publishers"
+	"payment-airpay/infrastructure/queue"
+	"payment-airpay/infrastructure/server"
 	"payment-airpay/infrastructure/service"
+	"payment-airpay/infrastructure/tracing"
+	"payment-airpay/infrastructure/views"
+	"payment-airpay/infrastructure/workers"
 	"sync"
+	"time"
 
+	"github.com/go-resty/resty/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/template/html/v2"
 	"github.com/google/wire"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // singleton
+var loggerOnce sync.Once
 var gatewayOnce sync.Once
 var transactionServiceOnce sync.Once
 var stagingServiceOnce sync.Once
@@ -22,8 +42,30 @@ var yugabyteClientOnce sync.Once
 var masterDataRepoOnce sync.Once
 var paymentRepoOnce sync.Once
 var acledaRepoOnce sync.Once
+var yugabyteClientWrapperOnce sync.Once
+var paymentAcledaRepoOnce sync.Once
+var restyClientOnce sync.Once
+var paymentLinkServiceOnce sync.Once
+var eventQueueOnce sync.Once
+var workerOnce sync.Once
+var refundRepoOnce sync.Once
+var refundServiceOnce sync.Once
+var listPaymentsServiceOnce sync.Once
+var reconciliationRepoOnce sync.Once
+var reconciliationServiceOnce sync.Once
+var currencyRepoOnce sync.Once
+var merchantCurrencyServiceOnce sync.Once
+var masterDataAdminRepoOnce sync.Once
+var masterDataServiceOnce sync.Once
+var acledaCredentialRepoOnce sync.Once
+var acledaCredentialServiceOnce sync.Once
+var acledaSessionRepoOnce sync.Once
+var sessionReconciliationServiceOnce sync.Once
+var paymentStatusServiceOnce sync.Once
 
 // singleton instance
+var loggerInstance *zap.Logger
+var logLevelInstance zap.AtomicLevel
 var acledaGatewayInstance *acleda.AcledaGateway
 var transactionServiceInstance *service.PaymentAcleda
 var stagingServiceInstance *services.CreateAcledaStagingPaymentService
@@ -32,8 +74,31 @@ var yugabyteClientInstance *connectors.YugabyteConnector
 var masterDataRepoInstance *repositories.MasterDataRepositoryYugabyteDB
 var paymentRepoInstance *repositories.PaymentRepositoryYugabyteDB
 var acledaRepoInstance *repositories.AcledaRepositoryYugabyteDB
+var yugabyteClientWrapperInstance clients.YugabyteClient
+var paymentAcledaRepoInstance *repositories.PaymentAcledaRepositoryYugabyteDB
+var restyClientInstance *resty.Client
+var paymentLinkServiceInstance *services.CreateAcledaPaymentLinkService
+var eventQueueInstance services.EventQueue
+var workerInstance *workers.Worker
+var refundRepoInstance *repositories.PaymentAcledaRefundRepositoryYugabyteDB
+var refundServiceInstance *services.CreateAcledaRefundService
+var listPaymentsServiceInstance *services.ListAcledaPaymentsService
+var reconciliationRepoInstance *repositories.ReconciliationRepositoryYugabyteDB
+var reconciliationServiceInstance *services.ReconcileAcledaSettlementService
+var currencyRepoInstance *repositories.CurrencyRepositoryYugabyteDB
+var merchantCurrencyServiceInstance *services.MerchantCurrencyService
+var masterDataAdminRepoInstance *repositories.MasterDataAdminRepositoryYugabyteDB
+var masterDataServiceInstance *services.ManageMasterDataService
+var acledaCredentialRepoInstance *repositories.AcledaCredentialRepositoryYugabyteDB
+var acledaCredentialServiceInstance *services.ManageAcledaCredentialsService
+var acledaSessionRepoInstance *repositories.AcledaSessionRepositoryYugabyteDB
+var sessionReconciliationServiceInstance *services.ReconcileAcledaSessionsService
+var paymentStatusServiceInstance *services.UpdateAcledaPaymentStatusService
 
 var ProviderSet wire.ProviderSet = wire.NewSet(
+	ProvideAppConfig,
+	ProvideLogger,
+	ProvideYugabyteDB,
 	ProvideAcledaGateway,
 	ProvideTransactionService,
 	ProvideAcledaStagingService,
@@ -42,14 +107,84 @@ var ProviderSet wire.ProviderSet = wire.NewSet(
 	ProvidePaymentRepository,
 	ProvideAcledaRepository,
 	ProvidePublisher,
+	ProvideYugabyteClientWrapper,
+	ProvidePaymentAcledaRepository,
+	ProvideRestyClient,
+	ProvideURLSigner,
+	ProvideCreateAcledaPaymentLinkService,
+	ProvideEventQueue,
+	ProvideRefundRepository,
+	ProvideCreateAcledaRefundService,
+	ProvideListAcledaPaymentsService,
+	ProvideReconciliationRepository,
+	ProvideReconcileAcledaSettlementService,
+	ProvideCurrencyRepository,
+	ProvideMerchantCurrencyService,
+	ProvideMasterDataAdminRepository,
+	ProvideManageMasterDataService,
+	ProvideAcledaCredentialRepository,
+	ProvideManageAcledaCredentialsService,
+	ProvideAcledaSessionRepository,
+	ProvideReconcileAcledaSessionsService,
+	ProvideUpdateAcledaPaymentStatusService,
 	wire.Bind(new(services.PaymentGateway), new(*acleda.AcledaGateway)),
+	wire.Bind(new(services.AcledaSessionGateway), new(*acleda.AcledaGateway)),
+	wire.Bind(new(services.PaymentLinkRepository), new(*repositories.PaymentAcledaRepositoryYugabyteDB)),
+	wire.Bind(new(services.AcledaRefundGateway), new(*acleda.AcledaGateway)),
+	wire.Bind(new(services.AcledaStatusGateway), new(*acleda.AcledaGateway)),
+	wire.Bind(new(services.RefundRepository), new(*repositories.PaymentAcledaRefundRepositoryYugabyteDB)),
+	wire.Bind(new(services.ReconciliationRepository), new(*repositories.ReconciliationRepositoryYugabyteDB)),
+	wire.Bind(new(services.CurrencyRepository), new(*repositories.CurrencyRepositoryYugabyteDB)),
+	wire.Bind(new(services.MasterDataRepository), new(*repositories.MasterDataAdminRepositoryYugabyteDB)),
+	wire.Bind(new(services.AcledaCredentialRepository), new(*repositories.AcledaCredentialRepositoryYugabyteDB)),
+	wire.Bind(new(services.AcledaSessionRepository), new(*repositories.AcledaSessionRepositoryYugabyteDB)),
+	wire.Bind(new(services.PaymentPageSigner), new(*crypto.URLSigner)),
 	wire.Bind(new(services.TransactionService), new(*service.PaymentAcleda)),
 	wire.Bind(new(services.Publisher), new(*publishers.PublisherLog)),
 )
 
+func ProvideAppConfig() *configuration.Config {
+	return configuration.AppConfig
+}
+
+// ProvideLogger builds the process logger from LOG_LEVEL, LOG_FORMAT and ENV
+// and installs it as the zap global for packages without injection.
+func ProvideLogger() *zap.Logger {
+	loggerOnce.Do(func() {
+		cfg := ProvideAppConfig()
+		var err error
+		loggerInstance, logLevelInstance, err = logger.New(cfg.Environment, cfg.LogLevel, cfg.LogFormat)
+		if err != nil {
+			var fallbackErr error
+			loggerInstance, logLevelInstance, fallbackErr = logger.New(cfg.Environment, "info", cfg.LogFormat)
+			if fallbackErr != nil {
+				loggerInstance, logLevelInstance = zap.NewExample(), zap.NewAtomicLevel()
+			}
+			loggerInstance.Warn("Invalid log configuration, falling back to info", zap.Error(err))
+		}
+		zap.ReplaceGlobals(loggerInstance)
+	})
+	return loggerInstance
+}
+
+// ProvideLogLevel returns the level of the process logger; changing it takes
+// effect immediately.
+func ProvideLogLevel() zap.AtomicLevel {
+	ProvideLogger()
+	return logLevelInstance
+}
+
+func ProvideYugabyteDB() *gorm.DB {
+	return database.YugabyteDBClient
+}
+
+func ProvideYugabyteReadDB() *gorm.DB {
+	return database.YugabyteReadDBClient
+}
+
 func ProvideAcledaGateway() *acleda.AcledaGateway {
 	gatewayOnce.Do(func() {
-		acledaGatewayInstance = acleda.NewAcledaGateway()
+		acledaGatewayInstance = acleda.NewAcledaGateway(ProvideAppConfig())
 	})
 	return acledaGatewayInstance
 }
@@ -60,21 +195,21 @@ func ProvideTransactionService() *service.PaymentAcleda {
 		paymentRepo := ProvidePaymentRepository()
 		acledaRepo := ProvideAcledaRepository()
 		db := ProvideYugabyteClient()
-		transactionServiceInstance = service.NewPaymentAcleda(masterRepo, paymentRepo, acledaRepo, db)
+		transactionServiceInstance = service.NewPaymentAcleda(masterRepo, paymentRepo, acledaRepo, db, ProvideLogger())
 	})
 	return transactionServiceInstance
 }
 
 func ProvideYugabyteClient() *connectors.YugabyteConnector {
 	yugabyteClientOnce.Do(func() {
-		yugabyteClientInstance = connectors.NewYugabyteConnector(database.YugabyteDBClient)
+		yugabyteClientInstance = connectors.NewYugabyteConnector(ProvideYugabyteDB())
 	})
 	return yugabyteClientInstance
 }
 
 func ProvideMasterDataRepository() *repositories.MasterDataRepositoryYugabyteDB {
 	masterDataRepoOnce.Do(func() {
-		masterDataRepoInstance = repositories.NewMasterDataRepositoryYugabyteDB()
+		masterDataRepoInstance = repositories.NewMasterDataRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
 	})
 	return masterDataRepoInstance
 }
@@ -96,7 +231,7 @@ func ProvideAcledaRepository() *repositories.AcledaRepositoryYugabyteDB {
 func ProvideAcledaStagingService() *services.CreateAcledaStagingPaymentService {
 	stagingServiceOnce.Do(func() {
 		gateway := ProvideAcledaGateway()
-		stagingServiceInstance = services.NewCreateAcledaStagingPaymentService(gateway)
+		stagingServiceInstance = services.NewCreateAcledaStagingPaymentService(gateway, ProvideLogger())
 	})
 	return stagingServiceInstance
 }
@@ -107,12 +242,293 @@ func ProvidePaymentAcledaService() *service.PaymentAcleda {
 		ProvidePaymentRepository(),
 		ProvideAcledaRepository(),
 		ProvideYugabyteClient(),
+		ProvideLogger(),
 	)
 }
 
 func ProvidePublisher() *publishers.PublisherLog {
 	publisherOnce.Do(func() {
-		publisherInstance = publishers.NewPublisherLog()
+		publisherInstance = publishers.NewPublisherLog(ProvideLogger())
 	})
 	return publisherInstance
 }
+
+func ProvideYugabyteClientWrapper() clients.YugabyteClient {
+	yugabyteClientWrapperOnce.Do(func() {
+		yugabyteClientWrapperInstance = clients.NewYugabyteClient(ProvideYugabyteDB(), ProvideYugabyteReadDB())
+	})
+	return yugabyteClientWrapperInstance
+}
+
+func ProvidePaymentAcledaRepository() *repositories.PaymentAcledaRepositoryYugabyteDB {
+	paymentAcledaRepoOnce.Do(func() {
+		paymentAcledaRepoInstance = repositories.NewPaymentAcledaRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return paymentAcledaRepoInstance
+}
+
+func ProvideRestyClient() *resty.Client {
+	restyClientOnce.Do(func() {
+		restyClientInstance = resty.New().
+			SetTimeout(60 * time.Second).
+			SetTransport(tracing.Transport(http.DefaultTransport))
+	})
+	return restyClientInstance
+}
+
+func ProvideCreateAcledaPaymentLinkService() *services.CreateAcledaPaymentLinkService {
+	paymentLinkServiceOnce.Do(func() {
+		paymentLinkServiceInstance = services.NewCreateAcledaPaymentLinkService(
+			ProvideAcledaGateway(),
+			ProvidePaymentAcledaRepository(),
+			ProvideAcledaSessionRepository(),
+			ProvideCurrencyRepository(),
+			ProvideAcledaCredentialRepository(),
+			ProvideURLSigner(),
+			ProvideRestyClient(),
+			ProvideAppConfig(),
+			ProvideLogger(),
+		)
+	})
+	return paymentLinkServiceInstance
+}
+
+// ProvideURLSigner returns the signer installed by crypto.InitializeURLSigner.
+func ProvideURLSigner() *crypto.URLSigner {
+	return crypto.DefaultURLSigner()
+}
+
+func ProvideCurrencyRepository() *repositories.CurrencyRepositoryYugabyteDB {
+	currencyRepoOnce.Do(func() {
+		currencyRepoInstance = repositories.NewCurrencyRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return currencyRepoInstance
+}
+
+func ProvideMerchantCurrencyService() *services.MerchantCurrencyService {
+	merchantCurrencyServiceOnce.Do(func() {
+		merchantCurrencyServiceInstance = services.NewMerchantCurrencyService(ProvideCurrencyRepository())
+	})
+	return merchantCurrencyServiceInstance
+}
+
+func ProvideMasterDataAdminRepository() *repositories.MasterDataAdminRepositoryYugabyteDB {
+	masterDataAdminRepoOnce.Do(func() {
+		masterDataAdminRepoInstance = repositories.NewMasterDataAdminRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return masterDataAdminRepoInstance
+}
+
+func ProvideManageMasterDataService() *services.ManageMasterDataService {
+	masterDataServiceOnce.Do(func() {
+		masterDataServiceInstance = services.NewManageMasterDataService(ProvideMasterDataAdminRepository())
+	})
+	return masterDataServiceInstance
+}
+
+func ProvideAcledaCredentialRepository() *repositories.AcledaCredentialRepositoryYugabyteDB {
+	acledaCredentialRepoOnce.Do(func() {
+		acledaCredentialRepoInstance = repositories.NewAcledaCredentialRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return acledaCredentialRepoInstance
+}
+
+func ProvideManageAcledaCredentialsService() *services.ManageAcledaCredentialsService {
+	acledaCredentialServiceOnce.Do(func() {
+		acledaCredentialServiceInstance = services.NewManageAcledaCredentialsService(ProvideAcledaCredentialRepository())
+	})
+	return acledaCredentialServiceInstance
+}
+
+// ProvideEventQueue publishes to RabbitMQ when a channel is open and falls back
+// to an in-memory queue otherwise.
+func ProvideEventQueue() services.EventQueue {
+	eventQueueOnce.Do(func() {
+		if queue.RabbitChan != nil {
+			eventQueueInstance = queue.NewRabbitMQQueue(queue.RabbitChan)
+		} else {
+			eventQueueInstance = queue.NewInMemoryQueue()
+		}
+	})
+	return eventQueueInstance
+}
+
+func ProvideRefundRepository() *repositories.PaymentAcledaRefundRepositoryYugabyteDB {
+	refundRepoOnce.Do(func() {
+		refundRepoInstance = repositories.NewPaymentAcledaRefundRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return refundRepoInstance
+}
+
+func ProvideCreateAcledaRefundService() *services.CreateAcledaRefundService {
+	refundServiceOnce.Do(func() {
+		refundServiceInstance = services.NewCreateAcledaRefundService(
+			ProvideAcledaGateway(),
+			ProvidePaymentAcledaRepository(),
+			ProvideRefundRepository(),
+			ProvideAcledaCredentialRepository(),
+			ProvideEventQueue(),
+			ProvidePublisher(),
+			ProvideRestyClient(),
+			ProvideAppConfig(),
+			ProvideLogger(),
+		)
+	})
+	return refundServiceInstance
+}
+
+func ProvideListAcledaPaymentsService() *services.ListAcledaPaymentsService {
+	listPaymentsServiceOnce.Do(func() {
+		listPaymentsServiceInstance = services.NewListAcledaPaymentsService(ProvidePaymentAcledaRepository())
+	})
+	return listPaymentsServiceInstance
+}
+
+func ProvideReconciliationRepository() *repositories.ReconciliationRepositoryYugabyteDB {
+	reconciliationRepoOnce.Do(func() {
+		reconciliationRepoInstance = repositories.NewReconciliationRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return reconciliationRepoInstance
+}
+
+func ProvideReconcileAcledaSettlementService() *services.ReconcileAcledaSettlementService {
+	reconciliationServiceOnce.Do(func() {
+		reconciliationServiceInstance = services.NewReconcileAcledaSettlementService(ProvideReconciliationRepository(), ProvideLogger())
+	})
+	return reconciliationServiceInstance
+}
+
+func ProvideSettlementReconciliationWorker() *workers.SettlementReconciliationWorker {
+	cfg := ProvideAppConfig()
+	return workers.NewSettlementReconciliationWorker(
+		ProvideReconcileAcledaSettlementService(),
+		cfg.AcledaSettlementDir,
+		time.Duration(cfg.AcledaSettlementPollInterval)*time.Second,
+		ProvideLogger(),
+	)
+}
+
+func ProvideAcledaSessionRepository() *repositories.AcledaSessionRepositoryYugabyteDB {
+	acledaSessionRepoOnce.Do(func() {
+		acledaSessionRepoInstance = repositories.NewAcledaSessionRepositoryYugabyteDB(ProvideYugabyteClientWrapper())
+	})
+	return acledaSessionRepoInstance
+}
+
+func ProvideReconcileAcledaSessionsService() *services.ReconcileAcledaSessionsService {
+	sessionReconciliationServiceOnce.Do(func() {
+		sessionReconciliationServiceInstance = services.NewReconcileAcledaSessionsService(ProvideAcledaSessionRepository(), ProvideLogger())
+	})
+	return sessionReconciliationServiceInstance
+}
+
+func ProvideSessionReconciliationWorker() *workers.SessionReconciliationWorker {
+	return workers.NewSessionReconciliationWorker(
+		ProvideReconcileAcledaSessionsService(),
+		time.Duration(ProvideAppConfig().AcledaSessionReconcileInterval)*time.Second,
+		ProvideLogger(),
+	)
+}
+
+func ProvideUpdateAcledaPaymentStatusService() *services.UpdateAcledaPaymentStatusService {
+	paymentStatusServiceOnce.Do(func() {
+		paymentStatusServiceInstance = services.NewUpdateAcledaPaymentStatusService(
+			ProvideAcledaGateway(),
+			ProvidePaymentAcledaRepository(),
+			ProvideAppConfig(),
+			ProvideLogger(),
+		)
+	})
+	return paymentStatusServiceInstance
+}
+
+func ProvidePaymentStatusWorker() *workers.PaymentStatusWorker {
+	return workers.NewPaymentStatusWorker(
+		ProvideUpdateAcledaPaymentStatusService(),
+		time.Duration(ProvideAppConfig().AcledaStatusPollInterval)*time.Second,
+		ProvideLogger(),
+	)
+}
+
+func ProvidePaymentAcledaTaskWorker() *workers.Worker {
+	workerOnce.Do(func() {
+		workerInstance = workers.NewPaymentAcledaTaskWorker(100, ProvideLogger())
+	})
+	return workerInstance
+}
+
+func ProvideMiddlewares() *middleware.Middlewares {
+	return middleware.NewMiddlewares(ProvideLogger(), ProvideMasterDataRepository(), ProvideYugabyteDB(), ProvideAppConfig())
+}
+
+func ProvideAcledaController() *controllers.AcledaController {
+	return controllers.NewAcledaController(
+		ProvideCreateAcledaPaymentLinkService(),
+		ProvideCreateAcledaRefundService(),
+		ProvideListAcledaPaymentsService(),
+		ProvideUpdateAcledaPaymentStatusService(),
+		ProvideURLSigner(),
+		ProvideAppConfig(),
+	)
+}
+
+func ProvideAcledaStagingController() *controllers.AcledaStagingController {
+	return controllers.NewAcledaStagingController(ProvideAcledaStagingService())
+}
+
+func ProvideReconciliationController() *controllers.ReconciliationController {
+	return controllers.NewReconciliationController(ProvideReconcileAcledaSettlementService())
+}
+
+func ProvideMerchantCurrencyController() *controllers.MerchantCurrencyController {
+	return controllers.NewMerchantCurrencyController(ProvideMerchantCurrencyService())
+}
+
+func ProvideMasterDataController() *controllers.MasterDataController {
+	return controllers.NewMasterDataController(ProvideManageMasterDataService())
+}
+
+func ProvideAcledaCredentialController() *controllers.AcledaCredentialController {
+	return controllers.NewAcledaCredentialController(ProvideManageAcledaCredentialsService())
+}
+
+func ProvidePaymentAdminController() *controllers.PaymentAdminController {
+	return controllers.NewPaymentAdminController(ProvideCreateAcledaRefundService(), ProvideUpdateAcledaPaymentStatusService())
+}
+
+func ProvideLogLevelController() *controllers.LogLevelController {
+	return controllers.NewLogLevelController(ProvideLogLevel())
+}
+
+func ProvideHealthController() *controllers.HealthController {
+	cfg := ProvideAppConfig()
+	return controllers.NewHealthController(health.NewChecker(
+		2*time.Second,
+		health.Yugabyte(),
+		health.YugabyteRead(),
+		health.RabbitMQ(cfg),
+		health.Redis(cfg),
+		health.Elasticsearch(),
+		health.Acleda(cfg),
+	))
+}
+
+// ProvideServer builds the Fiber app with every route and the embedded HTML
+// templates. It reads configuration.AppConfig and the database clients, so
+// both must be initialized first.
+func ProvideServer() *fiber.App {
+	engine := html.NewFileSystem(http.FS(views.Files), ".html")
+	return server.New(engine, server.Handlers{
+		Middlewares:    ProvideMiddlewares(),
+		Acleda:         ProvideAcledaController(),
+		AcledaStaging:  ProvideAcledaStagingController(),
+		Reconciliation: ProvideReconciliationController(),
+		Currencies:     ProvideMerchantCurrencyController(),
+		MasterData:     ProvideMasterDataController(),
+		Credentials:    ProvideAcledaCredentialController(),
+		PaymentAdmin:   ProvidePaymentAdminController(),
+		LogLevel:       ProvideLogLevelController(),
+		Health:         ProvideHealthController(),
+		Worker:         ProvidePaymentAcledaTaskWorker(),
+	})
+}
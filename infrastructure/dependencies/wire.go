@@ -27,3 +27,7 @@ func WireTransactionService() *service.PaymentAcleda {
 func WirePublisher() *publishers.PublisherLog {
 	panic(wire.Build(ProviderSet))
 }
+
+func WireCreateAcledaPaymentLinkService() *services.CreateAcledaPaymentLinkService {
+	panic(wire.Build(ProviderSet))
+}
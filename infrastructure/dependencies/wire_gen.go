@@ -17,8 +17,9 @@ import (
 
 func WireCreatePaymentService() *services.CreatePaymentService {
 	acledaGateway := ProvideAcledaGateway()
-	PaymentAcleda := ProvideTransactionService()
-	createPaymentService := services.NewCreatePaymentService(acledaGateway, PaymentAcleda)
+	paymentAcleda := ProvideTransactionService()
+	logger := ProvideLogger()
+	createPaymentService := services.NewCreatePaymentService(acledaGateway, paymentAcleda, logger)
 	return createPaymentService
 }
 
@@ -28,11 +29,16 @@ func WireAcledaGateway() *acleda.AcledaGateway {
 }
 
 func WireTransactionService() *service.PaymentAcleda {
-	PaymentAcleda := ProvideTransactionService()
-	return PaymentAcleda
+	paymentAcleda := ProvideTransactionService()
+	return paymentAcleda
 }
 
 func WirePublisher() *publishers.PublisherLog {
 	publisherLog := ProvidePublisher()
 	return publisherLog
 }
+
+func WireCreateAcledaPaymentLinkService() *services.CreateAcledaPaymentLinkService {
+	createAcledaPaymentLinkService := ProvideCreateAcledaPaymentLinkService()
+	return createAcledaPaymentLinkService
+}
@@ -11,7 +11,7 @@ import (
 	"payment-airpay/domain/entities"
 	"payment-airpay/infrastructure/configuration"
 	"payment-airpay/infrastructure/gateway"
-	"strconv"
+	"payment-airpay/infrastructure/tracing"
 	"strings"
 	"time"
 
@@ -38,7 +38,20 @@ type XPayTransactionDTO struct {
 	ExpiryTime       int    `json:"expiryTime" binding:"required"`
 }
 
+// ErrRejected marks an answer in which Acleda refused a request. Any other
+// error from a call, such as a timeout, leaves its outcome unknown.
+var ErrRejected = errors.New("rejected by acleda")
+
+// redactedKeys are the request fields that carry Acleda credentials. They are
+// never stored or logged.
+var redactedKeys = map[string]bool{
+	"loginid":   true,
+	"password":  true,
+	"signature": true,
+}
+
 type AcledaGateway struct {
+	cfg        *configuration.Config
 	baseURL    string
 	apiKey     string
 	merchantID string
@@ -87,20 +100,26 @@ type PaymentStatusResponse struct {
 	FailureReason string `json:"failure_reason,omitempty"`
 }
 
-func NewAcledaGateway() *AcledaGateway {
+func NewAcledaGateway(cfg *configuration.Config) *AcledaGateway {
 	return &AcledaGateway{
-		baseURL:    configuration.AppConfig.AcledaAPIURL,
-		apiKey:     configuration.AppConfig.AcledaAPIKey,
-		merchantID: configuration.AppConfig.AcledaMerchantID,
-		login:      configuration.AppConfig.AcledaLogin,
-		password:   configuration.AppConfig.AcledaRemotePassword,
+		cfg:        cfg,
+		baseURL:    cfg.AcledaAPIURL,
+		apiKey:     cfg.AcledaAPIKey,
+		merchantID: cfg.AcledaMerchantID,
+		login:      cfg.AcledaLogin,
+		password:   cfg.AcledaRemotePassword,
 		httpClient: &http.Client{
-			Timeout: time.Duration(configuration.AppConfig.AcledaTimeout) * time.Millisecond,
+			Timeout:   time.Duration(cfg.AcledaTimeout) * time.Millisecond,
+			Transport: tracing.Transport(http.DefaultTransport),
 		},
 	}
 }
 
-func (g *AcledaGateway) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*CreatePaymentResponse, error) {
+func (g *AcledaGateway) CreatePayment(ctx context.Context, req CreatePaymentRequest) (_ *CreatePaymentResponse, err error) {
+	ctx, finish := startCall(ctx, "create_payment")
+	status := 0
+	defer func() { finish(status, "", err) }()
+
 	jsonData, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -119,6 +138,7 @@ func (g *AcledaGateway) CreatePayment(ctx context.Context, req CreatePaymentRequ
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -137,7 +157,11 @@ func (g *AcledaGateway) CreatePayment(ctx context.Context, req CreatePaymentRequ
 	return &response, nil
 }
 
-func (g *AcledaGateway) GetPaymentStatus(ctx context.Context, req PaymentStatusRequest) (*PaymentStatusResponse, error) {
+func (g *AcledaGateway) GetPaymentStatus(ctx context.Context, req PaymentStatusRequest) (_ *PaymentStatusResponse, err error) {
+	ctx, finish := startCall(ctx, "get_payment_status")
+	status := 0
+	defer func() { finish(status, "", err) }()
+
 	url := fmt.Sprintf("%s/payments/%s/status", g.baseURL, req.TransactionID)
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -152,6 +176,7 @@ func (g *AcledaGateway) GetPaymentStatus(ctx context.Context, req PaymentStatusR
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -170,26 +195,22 @@ func (g *AcledaGateway) GetPaymentStatus(ctx context.Context, req PaymentStatusR
 	return &response, nil
 }
 
-func (g *AcledaGateway) OpenSessionV2(ctx context.Context, client *resty.Client, url string, param OpenSessionV2RequestDto) (OpenSessionV2ResponseDTO, error) {
-	var response OpenSessionV2ResponseDTO
+func (g *AcledaGateway) OpenSessionV2(ctx context.Context, client *resty.Client, url string, param OpenSessionV2RequestDto) (response OpenSessionV2ResponseDTO, err error) {
+	ctx, finish := startCall(ctx, "open_session_v2")
+	defer func() {
+		finish(response.RequestAPICallResult.ResponseStatusCode,
+			resultCode(response.Result.Code, response.Result.ErrorDetails), err)
+	}()
+
 	queries, _ := json.Marshal(param)
 
 	resp, err := client.R().
+		SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
 		SetBody(param).
 		Post(url)
 
-	reqHeaders, _ := json.Marshal(resp.Request.Header)
-	respHeaders, _ := json.Marshal(resp.Header())
-
-	response.RequestAPICallResult.RequestURL = url
-	response.RequestAPICallResult.Method = resp.Request.RawRequest.Method
-	response.RequestAPICallResult.RequestLatency = resp.Time().String()
-	response.RequestAPICallResult.RequestBody = string(queries)
-	response.RequestAPICallResult.ResponseBody = string(resp.Body())
-	response.RequestAPICallResult.RequestHeaders = string(reqHeaders)
-	response.RequestAPICallResult.ResponseHeaders = string(respHeaders)
-	response.RequestAPICallResult.ResponseStatusCode = resp.StatusCode()
+	response.RequestAPICallResult = captureAPICall(url, queries, resp)
 
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "excedeed") {
@@ -205,21 +226,128 @@ func (g *AcledaGateway) OpenSessionV2(ctx context.Context, client *resty.Client,
 	}
 
 	if response.Result.ErrorDetails != "SUCCESS" {
-		return response, fmt.Errorf(response.Result.ErrorDetails)
+		return response, fmt.Errorf("%w: %s", ErrRejected, response.Result.ErrorDetails)
 	}
 
 	return response, nil
 
 }
 
+// Refund asks Acleda to reverse all or part of a paid XPay transaction. The
+// same payload is used for voids; only the target URL differs.
+func (g *AcledaGateway) Refund(ctx context.Context, client *resty.Client, url string, param RefundRequestDto) (response RefundResponseDTO, err error) {
+	ctx, finish := startCall(ctx, "refund")
+	defer func() {
+		finish(response.RequestAPICallResult.ResponseStatusCode,
+			resultCode(response.Result.Code, response.Result.ErrorDetails), err)
+	}()
+
+	queries, _ := json.Marshal(param)
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(param).
+		Post(url)
+
+	response.RequestAPICallResult = captureAPICall(url, queries, resp)
+
+	if err != nil {
+		return response, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return response, fmt.Errorf("acleda refund api error: status %d, body: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	if err := json.Unmarshal(resp.Body(), &response); err != nil {
+		return response, err
+	}
+
+	if response.Result.ErrorDetails != "SUCCESS" {
+		return response, fmt.Errorf("%w: %s", ErrRejected, response.Result.ErrorDetails)
+	}
+
+	return response, nil
+}
+
+// captureAPICall snapshots a resty exchange for the api_call_logs index and
+// the ledgers. Credentials in the request body are redacted.
+func captureAPICall(url string, requestBody []byte, resp *resty.Response) gateway.RequestAPICallResult {
+	result := gateway.RequestAPICallResult{
+		RequestURL:  url,
+		RequestBody: redactCredentials(requestBody),
+	}
+	if resp == nil {
+		return result
+	}
+
+	respHeaders, _ := json.Marshal(resp.Header())
+	result.RequestLatency = resp.Time().String()
+	result.ResponseBody = string(resp.Body())
+	result.ResponseHeaders = string(respHeaders)
+	result.ResponseStatusCode = resp.StatusCode()
+
+	if resp.Request != nil {
+		reqHeaders, _ := json.Marshal(resp.Request.Header)
+		result.RequestHeaders = string(reqHeaders)
+		if resp.Request.RawRequest != nil {
+			result.Method = resp.Request.RawRequest.Method
+		} else {
+			result.Method = resp.Request.Method
+		}
+	}
+
+	return result
+}
+
+// redactCredentials masks the credential fields of a JSON request body. A
+// body that is not JSON is dropped rather than stored unredacted.
+func redactCredentials(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return ""
+	}
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, field := range t {
+			if redactedKeys[strings.ToLower(k)] {
+				t[k] = "[REDACTED]"
+				continue
+			}
+			t[k] = redactValue(field)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	}
+	return v
+}
+
 // OpenSession implements Acleda session opening
-func (g *AcledaGateway) OpenSession(ctx context.Context, req OpenSessionRequest) (*OpenSessionResponse, error) {
+func (g *AcledaGateway) OpenSession(ctx context.Context, req OpenSessionRequest) (_ *OpenSessionResponse, err error) {
+	ctx, finish := startCall(ctx, "open_session")
+	status := 0
+	defer func() { finish(status, "", err) }()
+
 	// Create request with credentials
 	sessionReq := OpenSessionRequest{
 		LoginID:    g.login,
 		Password:   g.password,
 		MerchantID: g.merchantID,
-		Signature:  generateSignature(req),
+		Signature:  g.generateSignature(req),
 		TxID:       req.TxID,
 		XPayTransaction: XPayTransaction{
 			TxID:             req.TxID,
@@ -251,6 +379,7 @@ func (g *AcledaGateway) OpenSession(ctx context.Context, req OpenSessionRequest)
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -266,19 +395,20 @@ func (g *AcledaGateway) OpenSession(ctx context.Context, req OpenSessionRequest)
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	fmt.Println(response.Result.XTran.PaymentTokenID)
-	fmt.Println(response.Result.SessionID)
-
 	return &response, nil
 }
 
-func generateSignature(req OpenSessionRequest) string {
+func (g *AcledaGateway) generateSignature(req OpenSessionRequest) string {
 	// Use the secret key from configuration
-	return configuration.AppConfig.AcledaAPIKey
+	return g.apiKey
 }
 
 // CreateStagingPayment creates a payment using Acleda staging API
-func (g *AcledaGateway) CreateStagingPayment(ctx context.Context, req *StagingPaymentRequest) (*StagingPaymentResponse, error) {
+func (g *AcledaGateway) CreateStagingPayment(ctx context.Context, req *StagingPaymentRequest) (_ *StagingPaymentResponse, err error) {
+	ctx, finish := startCall(ctx, "create_staging_payment")
+	status := 0
+	defer func() { finish(status, "", err) }()
+
 	// Create request payload
 	requestData := map[string]interface{}{
 		"amount":               req.Amount,
@@ -305,7 +435,7 @@ func (g *AcledaGateway) CreateStagingPayment(ctx context.Context, req *StagingPa
 	}
 
 	// Use staging URL
-	stgURL := configuration.AppConfig.AcledaSTGURL
+	stgURL := g.cfg.AcledaSTGURL
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, stgURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -313,13 +443,14 @@ func (g *AcledaGateway) CreateStagingPayment(ctx context.Context, req *StagingPa
 
 	// Set headers with basic auth
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.SetBasicAuth(configuration.AppConfig.AcledaUsername, configuration.AppConfig.AcledaPassword)
+	httpReq.SetBasicAuth(g.cfg.AcledaUsername, g.cfg.AcledaPassword)
 
 	resp, err := g.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -330,9 +461,11 @@ func (g *AcledaGateway) CreateStagingPayment(ctx context.Context, req *StagingPa
 		return nil, fmt.Errorf("acleda staging api error: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	// Parse the response
+	// Parse the response, keeping numbers exact
 	var response map[string]interface{}
-	if err := json.Unmarshal(body, &response); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -352,7 +485,7 @@ func (g *AcledaGateway) CreateStagingPayment(ctx context.Context, req *StagingPa
 		PaymentCode:   getString(data, "payment_code", ""),
 		Name:          getString(data, "name", ""),
 		Email:         getString(data, "email", ""),
-		Amount:        getFloat(data, "amount"),
+		Amount:        getNumber(data, "amount"),
 		Currency:      getString(data, "currency", ""),
 		Status:        getString(data, "status", ""),
 	}
@@ -380,31 +513,34 @@ type StagingPaymentRequest struct {
 	TransactionID     string `json:"transaction_id"`
 }
 
-// StagingPaymentResponse represents the response from Acleda staging payment
+// StagingPaymentResponse represents the response from Acleda staging payment.
+// Amount is kept as json.Number, like the amounts of XTranDTO.
 type StagingPaymentResponse struct {
-	TransactionID string  `json:"transaction_id"`
-	PaymentMethod string  `json:"payment_method"`
-	Provider      string  `json:"provider"`
-	Bank          string  `json:"bank"`
-	PaymentLink   string  `json:"payment_link"`
-	PaymentCode   string  `json:"payment_code"`
-	Name          string  `json:"name"`
-	Email         string  `json:"email"`
-	Amount        float64 `json:"amount"`
-	Currency      string  `json:"currency"`
-	Status        string  `json:"status"`
+	TransactionID string      `json:"transaction_id"`
+	PaymentMethod string      `json:"payment_method"`
+	Provider      string      `json:"provider"`
+	Bank          string      `json:"bank"`
+	PaymentLink   string      `json:"payment_link"`
+	PaymentCode   string      `json:"payment_code"`
+	Name          string      `json:"name"`
+	Email         string      `json:"email"`
+	Amount        json.Number `json:"amount"`
+	Currency      string      `json:"currency"`
+	Status        string      `json:"status"`
 }
 
+// XTranDTO keeps amounts as json.Number so they are never rounded through
+// float64; convert them with entities.MoneyFromDecimal.
 type XTranDTO struct {
-	PurchaseAmount float64 `json:"purchaseAmount" binding:"required"`
-	PurchaseDate   int64   `json:"purchaseDate" binding:"required"`
-	Quantity       int     `json:"quantity" binding:"required"`
-	PaymentTokenID string  `json:"paymentTokenid" binding:"required"`
-	ExpiryTime     int     `json:"expiryTime" binding:"required"`
-	ConfirmDate    int64   `json:"confirmDate" binding:"required"`
-	PurchaseType   int     `json:"purchaseType" binding:"required"`
-	SaveToken      int     `json:"savetoken" binding:"required"`
-	FeeAmount      float64 `json:"feeAmount" binding:"required"`
+	PurchaseAmount json.Number `json:"purchaseAmount" binding:"required"`
+	PurchaseDate   int64       `json:"purchaseDate" binding:"required"`
+	Quantity       int         `json:"quantity" binding:"required"`
+	PaymentTokenID string      `json:"paymentTokenid" binding:"required"`
+	ExpiryTime     int         `json:"expiryTime" binding:"required"`
+	ConfirmDate    int64       `json:"confirmDate" binding:"required"`
+	PurchaseType   int         `json:"purchaseType" binding:"required"`
+	SaveToken      int         `json:"savetoken" binding:"required"`
+	FeeAmount      json.Number `json:"feeAmount" binding:"required"`
 }
 
 type OpenSessionV2ResponseDTO struct {
@@ -417,6 +553,41 @@ func (s *OpenSessionV2ResponseDTO) GetAPICall() gateway.RequestAPICallResult {
 	return s.RequestAPICallResult
 }
 
+type RefundRequestDto struct {
+	LoginID           string               `json:"loginId" binding:"required"`
+	Password          string               `json:"password" binding:"required"`
+	MerchantID        string               `json:"merchantID" binding:"required"`
+	Signature         string               `json:"signature" binding:"required"`
+	RefundTransaction RefundTransactionDTO `json:"refundTransaction" binding:"required"`
+}
+
+type RefundTransactionDTO struct {
+	TxID           string `json:"txid" binding:"required"`
+	RefundTxID     string `json:"refundTxid" binding:"required"`
+	SessionID      string `json:"sessionid"`
+	PaymentTokenID string `json:"paymenttokenid"`
+	RefundAmount   string `json:"refundAmount" binding:"required"`
+	Currency       string `json:"currency" binding:"required"`
+	Reason         string `json:"reason"`
+}
+
+type RefundResultDTO struct {
+	Code          int    `json:"code"`
+	ErrorDetails  string `json:"errorDetails"`
+	RefundTxID    string `json:"refundTxid"`
+	BankReference string `json:"bankRefNo"`
+}
+
+type RefundResponseDTO struct {
+	Result RefundResultDTO `json:"result" binding:"required"`
+
+	RequestAPICallResult gateway.RequestAPICallResult `json:"-"`
+}
+
+func (s *RefundResponseDTO) GetAPICall() gateway.RequestAPICallResult {
+	return s.RequestAPICallResult
+}
+
 // Request structures for OpenSession
 type OpenSessionRequest struct {
 	LoginID         string          `json:"loginId"`
@@ -461,22 +632,16 @@ func getString(m map[string]interface{}, key, defaultValue string) string {
 	return defaultValue
 }
 
-func getFloat(m map[string]interface{}, key string) float64 {
-	if val, ok := m[key]; ok {
-		switch v := val.(type) {
-		case float64:
-			return v
-		case float32:
-			return float64(v)
-		case int:
-			return float64(v)
-		case string:
-			if f, err := strconv.ParseFloat(v, 64); err == nil {
-				return f
-			}
-		}
+// getNumber reads an amount sent either as a number, from a map decoded with
+// UseNumber, or as a string.
+func getNumber(m map[string]interface{}, key string) json.Number {
+	switch v := m[key].(type) {
+	case json.Number:
+		return v
+	case string:
+		return json.Number(v)
 	}
-	return 0
+	return ""
 }
 
 // Create implements PaymentGateway interface
@@ -512,6 +677,11 @@ func (g *AcledaGateway) Create(ctx context.Context, payload map[string]interface
 		return entities.Payment{}, fmt.Errorf("failed to create Acleda payment: %w", err)
 	}
 
+	requestAmount, err := entities.MoneyFromDecimal(resp.Amount, resp.Currency)
+	if err != nil {
+		return entities.Payment{}, fmt.Errorf("invalid amount from Acleda: %w", err)
+	}
+
 	// Convert to entities.Payment
 	payment := entities.Payment{
 		BusinessID:       getString(payload, "business_id", ""),
@@ -520,7 +690,7 @@ func (g *AcledaGateway) Create(ctx context.Context, payload map[string]interface
 		Type:             getString(payload, "type", "PAYMENT"),
 		Country:          getString(payload, "country", "KH"),
 		Currency:         resp.Currency,
-		RequestAmount:    parseFloat(resp.Amount),
+		RequestAmount:    requestAmount,
 		CaptureMethod:    getString(payload, "capture_method", "FULL_CAPTURE"),
 		ChannelCode:      "ACLEDA",
 		ChannelProps: map[string]interface{}{
@@ -543,14 +713,3 @@ func (g *AcledaGateway) Create(ctx context.Context, payload map[string]interface
 
 	return payment, nil
 }
-
-func parseFloat(s string) float64 {
-	if s == "" {
-		return 0
-	}
-	// Simple float parsing - in production, use strconv.ParseFloat with error handling
-	if f, err := strconv.ParseFloat(s, 64); err == nil {
-		return f
-	}
-	return 0
-}
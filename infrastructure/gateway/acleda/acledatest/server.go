@@ -0,0 +1,126 @@
+// Package acledatest provides a fake Acleda XPay server for local and
+// integration testing, in the spirit of net/http/httptest.
+package acledatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"payment-airpay/infrastructure/gateway/acleda"
+)
+
+// Server is a fake Acleda endpoint. OpenSessionV2URL accepts the same payload
+// as the real openSessionV2 API and answers with a successful session. The
+// status API under URL reports PENDING for every transaction until
+// SetPaymentStatus says otherwise.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	sessions map[string]acleda.OpenSessionV2RequestDto
+	statuses map[string]string
+	seq      int
+
+	// ErrorDetails, when set, is returned instead of "SUCCESS".
+	ErrorDetails string
+}
+
+func NewServer() *Server {
+	s := &Server{
+		sessions: make(map[string]acleda.OpenSessionV2RequestDto),
+		statuses: make(map[string]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openSessionV2", s.openSessionV2)
+	mux.HandleFunc("GET /payments/{id}/status", s.paymentStatus)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *Server) OpenSessionV2URL() string {
+	return s.URL + "/openSessionV2"
+}
+
+// Sessions returns every session request received, keyed by session ID.
+func (s *Server) Sessions() map[string]acleda.OpenSessionV2RequestDto {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]acleda.OpenSessionV2RequestDto, len(s.sessions))
+	for k, v := range s.sessions {
+		out[k] = v
+	}
+	return out
+}
+
+// SetPaymentStatus sets the payment_status the status API reports for a
+// transaction, e.g. "PAID" or "FAILED".
+func (s *Server) SetPaymentStatus(transactionID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[transactionID] = status
+}
+
+func (s *Server) paymentStatus(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.PathValue("id")
+
+	s.mu.Lock()
+	status, ok := s.statuses[transactionID]
+	s.mu.Unlock()
+	if !ok {
+		status = "PENDING"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(acleda.PaymentStatusResponse{
+		Status:        "success",
+		TransactionID: transactionID,
+		PaymentStatus: status,
+	})
+}
+
+func (s *Server) openSessionV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req acleda.OpenSessionV2RequestDto
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.seq++
+	sessionID := fmt.Sprintf("SID-%d", s.seq)
+	tokenID := fmt.Sprintf("PTID-%d", s.seq)
+	s.sessions[sessionID] = req
+	errorDetails := s.ErrorDetails
+	s.mu.Unlock()
+
+	if errorDetails == "" {
+		errorDetails = "SUCCESS"
+	}
+
+	resp := acleda.OpenSessionV2ResponseDTO{
+		Result: acleda.ResultDTO{
+			Code:         0,
+			ErrorDetails: errorDetails,
+			SessionID:    sessionID,
+			XTran: acleda.XTranDTO{
+				PurchaseAmount: json.Number(req.XPayTransaction.PurchaseAmount),
+				PurchaseDate:   time.Now().UnixMilli(),
+				Quantity:       1,
+				PaymentTokenID: tokenID,
+				ExpiryTime:     req.XPayTransaction.ExpiryTime,
+			},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
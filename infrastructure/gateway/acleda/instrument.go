@@ -0,0 +1,62 @@
+package acleda
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"payment-airpay/infrastructure/metrics"
+	"payment-airpay/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startCall opens a span for an Acleda operation. The returned function ends
+// the span and records the call metrics; call it once with the outcome.
+func startCall(ctx context.Context, operation string) (context.Context, func(httpStatus int, acledaCode string, err error)) {
+	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "acleda."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("acleda.operation", operation)),
+	)
+	return ctx, func(httpStatus int, acledaCode string, err error) {
+		result := callResult(httpStatus, acledaCode, err)
+		span.SetAttributes(attribute.String("acleda.result", result))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, result)
+		}
+		span.End()
+		metrics.ObserveGatewayCall(operation, result, time.Since(start))
+	}
+}
+
+// callResult is "success", "timeout", "acleda_<code>" when Acleda rejected
+// the request, "http_<status>" for an unexpected HTTP status, or "error".
+func callResult(httpStatus int, acledaCode string, err error) string {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout(), err.Error() == "timeout":
+		return "timeout"
+	case acledaCode != "":
+		return "acleda_" + acledaCode
+	case httpStatus != 0 && httpStatus != 200 && httpStatus != 201:
+		return "http_" + strconv.Itoa(httpStatus)
+	default:
+		return "error"
+	}
+}
+
+// resultCode returns Acleda's result code once a response body was parsed.
+func resultCode(code int, errorDetails string) string {
+	if errorDetails == "" || errorDetails == "SUCCESS" {
+		return ""
+	}
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,184 @@
+package acleda
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment-airpay/domain/entities"
+)
+
+// settlementColumns maps the header names seen in Acleda statement exports
+// (CSV downloads and the bank's pipe/semicolon delimited files) to fields.
+var settlementColumns = map[string]string{
+	"invoiceid":        "invoice_id",
+	"invoice_id":       "invoice_id",
+	"invoice":          "invoice_id",
+	"sessionid":        "session_id",
+	"session_id":       "session_id",
+	"txid":             "transaction_id",
+	"transactionid":    "transaction_id",
+	"transaction_id":   "transaction_id",
+	"amount":           "amount",
+	"purchaseamount":   "amount",
+	"purchase_amount":  "amount",
+	"fee":              "fee",
+	"feeamount":        "fee",
+	"fee_amount":       "fee",
+	"currency":         "currency",
+	"purchasecurrency": "currency",
+	"status":           "status",
+	"txstatus":         "status",
+	"settlementdate":   "settled_at",
+	"settlement_date":  "settled_at",
+	"settleddate":      "settled_at",
+	"date":             "settled_at",
+}
+
+var settlementDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+	"20060102",
+}
+
+// ParseSettlementFile reads an Acleda settlement statement. The delimiter is
+// detected from the header line (comma, semicolon, pipe or tab). Every line
+// must carry an amount and at least one of invoice, session or transaction ID.
+func ParseSettlementFile(r io.Reader) ([]entities.SettlementLine, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settlement file: %w", err)
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	header, _, _ := bufio.NewReader(bytes.NewReader(content)).ReadLine()
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = detectDelimiter(string(header))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse settlement file: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, errors.New("settlement file has no data lines")
+	}
+
+	index := make(map[string]int)
+	for i, name := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if field, ok := settlementColumns[key]; ok {
+			index[field] = i
+		}
+	}
+	if _, ok := index["amount"]; !ok {
+		return nil, errors.New("settlement file is missing an amount column")
+	}
+	_, hasInvoice := index["invoice_id"]
+	_, hasSession := index["session_id"]
+	_, hasTx := index["transaction_id"]
+	if !hasInvoice && !hasSession && !hasTx {
+		return nil, errors.New("settlement file needs an invoice, session or transaction ID column")
+	}
+
+	lines := make([]entities.SettlementLine, 0, len(records)-1)
+	for i, record := range records[1:] {
+		lineNo := i + 2
+		get := func(field string) string {
+			if col, ok := index[field]; ok && col < len(record) {
+				return strings.TrimSpace(record[col])
+			}
+			return ""
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		line := entities.SettlementLine{
+			LineNo:        lineNo,
+			InvoiceID:     get("invoice_id"),
+			SessionID:     get("session_id"),
+			TransactionID: get("transaction_id"),
+			Currency:      strings.ToUpper(get("currency")),
+			Status:        strings.ToUpper(get("status")),
+		}
+		if line.InvoiceID == "" && line.SessionID == "" && line.TransactionID == "" {
+			return nil, fmt.Errorf("line %d: no invoice, session or transaction ID", lineNo)
+		}
+
+		if line.Amount, err = parseSettlementAmount(get("amount")); err != nil {
+			return nil, fmt.Errorf("line %d: amount: %w", lineNo, err)
+		}
+		if fee := get("fee"); fee != "" {
+			if line.FeeAmount, err = parseSettlementAmount(fee); err != nil {
+				return nil, fmt.Errorf("line %d: fee: %w", lineNo, err)
+			}
+		}
+		if settled := get("settled_at"); settled != "" {
+			if line.SettledAt, err = parseSettlementDate(settled); err != nil {
+				return nil, fmt.Errorf("line %d: settlement date: %w", lineNo, err)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func detectDelimiter(header string) rune {
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '|', '\t'} {
+		if n := strings.Count(header, string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// parseSettlementAmount validates an amount and returns its exact decimal
+// text with thousands separators removed. Reversals may be signed ("-10.00")
+// or in accounting form ("(10.00)").
+func parseSettlementAmount(s string) (string, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = "-" + strings.TrimSpace(s[1:len(s)-1])
+	}
+	if s == "" {
+		return "", errors.New("empty value")
+	}
+	sign := ""
+	if s[0] == '-' || s[0] == '+' {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = strings.TrimSpace(s[1:])
+	}
+	if _, err := entities.ParseDecimal(s, entities.StorageScale); err != nil {
+		return "", err
+	}
+	return sign + s, nil
+}
+
+func parseSettlementDate(s string) (time.Time, error) {
+	for _, layout := range settlementDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", s)
+}
@@ -0,0 +1,84 @@
+package acleda
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSettlementAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "25.00", want: "25.00"},
+		{in: " 25.00 ", want: "25.00"},
+		{in: "-25.00", want: "-25.00"},
+		{in: "+25.00", want: "25.00"},
+		{in: "(25.00)", want: "-25.00"},
+		{in: "( 25.00 )", want: "-25.00"},
+		{in: "1,234.50", want: "1234.50"},
+		{in: "(1,234.50)", want: "-1234.50"},
+		{in: "40000", want: "40000"},
+		{in: "0.0001", want: "0.0001"},
+
+		{in: "", err: true},
+		{in: "()", err: true},
+		{in: "-", err: true},
+		{in: "--25.00", err: true},
+		{in: "25.00001", err: true},
+		{in: "1e3", err: true},
+		{in: "USD 25.00", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSettlementAmount(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseSettlementAmount(%q) = %q; want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSettlementAmount(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseSettlementFile(t *testing.T) {
+	file := "\xef\xbb\xbfInvoiceID|Amount|Fee|Currency|Status|SettlementDate\n" +
+		"ACL-1|1,234.50|1.25|usd|success|2026-02-24\n" +
+		"ACL-1|(1,234.50)||USD|REVERSED|24/02/2026\n"
+
+	lines, err := ParseSettlementFile(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ParseSettlementFile: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines; want 2", len(lines))
+	}
+
+	paid, reversal := lines[0], lines[1]
+	if paid.LineNo != 2 || paid.InvoiceID != "ACL-1" || paid.Amount != "1234.50" || paid.FeeAmount != "1.25" ||
+		paid.Currency != "USD" || paid.Status != "SUCCESS" || paid.SettledAt.Format("2006-01-02") != "2026-02-24" {
+		t.Errorf("first line = %+v", paid)
+	}
+	if reversal.LineNo != 3 || reversal.Amount != "-1234.50" || reversal.FeeAmount != "" || reversal.Status != "REVERSED" {
+		t.Errorf("second line = %+v", reversal)
+	}
+}
+
+func TestParseSettlementFileRejects(t *testing.T) {
+	tests := map[string]string{
+		"no data lines":    "invoice_id,amount\n",
+		"no amount column": "invoice_id,currency\nACL-1,USD\n",
+		"no ID column":     "amount,currency\n10.00,USD\n",
+		"line without ID":  "invoice_id,amount\n,10.00\n",
+		"bad amount":       "invoice_id,amount\nACL-1,ten\n",
+		"bad date":         "invoice_id,amount,date\nACL-1,10.00,yesterday\n",
+	}
+	for name, file := range tests {
+		if _, err := ParseSettlementFile(strings.NewReader(file)); err == nil {
+			t.Errorf("%s: ParseSettlementFile succeeded; want an error", name)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/database"
+	"payment-airpay/infrastructure/queue"
+)
+
+var ErrNotConfigured = errors.New("not configured")
+
+// Yugabyte pings the database behind database.YugabyteDBClient.
+func Yugabyte() Check {
+	return Check{
+		Name:     "yugabyte",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			if database.YugabyteDBClient == nil {
+				return errors.New("database is not initialized")
+			}
+			sqlDB, err := database.YugabyteDBClient.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// YugabyteRead pings the connection that serves lag-tolerant reads. It is
+// not critical: a replica outage only affects the reads routed to it, and
+// taking the service out of rotation would not fix it.
+func YugabyteRead() Check {
+	return Check{
+		Name: "yugabyte_read",
+		Run: func(ctx context.Context) error {
+			if database.YugabyteReadDBClient == nil {
+				return ErrNotConfigured
+			}
+			sqlDB, err := database.YugabyteReadDBClient.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// RabbitMQ checks that the broker connection and the publishing channel are
+// both open. A dead channel stops event publishing even while the
+// connection is up.
+func RabbitMQ(cfg *configuration.Config) Check {
+	return Check{
+		Name:     "rabbitmq",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			if queue.RabbitConn == nil && cfg.RabbitMQURI == "" {
+				return ErrNotConfigured
+			}
+			if queue.RabbitConn == nil || queue.RabbitConn.IsClosed() {
+				return errors.New("connection is closed")
+			}
+			if queue.RabbitChan == nil || queue.RabbitChan.IsClosed() {
+				return errors.New("channel is closed")
+			}
+			return nil
+		},
+	}
+}
+
+// Redis checks that the Redis server accepts connections.
+func Redis(cfg *configuration.Config) Check {
+	return Check{
+		Name: "redis",
+		Run: func(ctx context.Context) error {
+			if cfg.RedisHost == "" {
+				return ErrNotConfigured
+			}
+			return dial(ctx, net.JoinHostPort(cfg.RedisHost, strconv.Itoa(cfg.RedisPort)))
+		},
+	}
+}
+
+// Elasticsearch pings the log index cluster.
+func Elasticsearch() Check {
+	return Check{
+		Name: "elasticsearch",
+		Run: func(ctx context.Context) error {
+			if database.ElasticsearchClient == nil {
+				return ErrNotConfigured
+			}
+			return database.ElasticsearchClient.Ping(ctx)
+		},
+	}
+}
+
+// Acleda checks that the Acleda host accepts TCP connections. It does not
+// authenticate, so it costs the bank nothing.
+func Acleda(cfg *configuration.Config) Check {
+	return Check{
+		Name: "acleda",
+		Run: func(ctx context.Context) error {
+			if !cfg.HealthCheckAcleda || cfg.ACLEDAOPENSESSIONV2URL == "" {
+				return ErrNotConfigured
+			}
+			u, err := url.Parse(cfg.ACLEDAOPENSESSIONV2URL)
+			if err != nil {
+				return err
+			}
+			port := u.Port()
+			if port == "" {
+				port = "443"
+				if u.Scheme == "http" {
+					port = "80"
+				}
+			}
+			return dial(ctx, net.JoinHostPort(u.Hostname(), port))
+		},
+	}
+}
+
+func dial(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,157 @@
+// Package logger builds the application's structured logger and carries
+// per-request log fields through a context.Context.
+package logger
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New builds a logger writing JSON in production and human-readable lines
+// otherwise. format ("json" or "console") overrides the default. The
+// returned level can be changed while the service is running.
+func New(environment, level, format string) (*zap.Logger, zap.AtomicLevel, error) {
+	atomicLevel := zap.NewAtomicLevel()
+	if level != "" {
+		if err := atomicLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, atomicLevel, err
+		}
+	}
+
+	production := isProduction(environment)
+	var cfg zap.Config
+	if production {
+		cfg = zap.NewProductionConfig()
+	} else {
+		cfg = zap.NewDevelopmentConfig()
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	cfg.Level = atomicLevel
+	cfg.EncoderConfig.TimeKey = "time"
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	switch strings.ToLower(format) {
+	case "json":
+		cfg.Encoding = "json"
+		cfg.EncoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+	case "console":
+		cfg.Encoding = "console"
+	}
+
+	log, err := cfg.Build()
+	if err != nil {
+		return nil, atomicLevel, err
+	}
+	return log, atomicLevel, nil
+}
+
+func isProduction(environment string) bool {
+	switch strings.ToLower(environment) {
+	case "prod", "production":
+		return true
+	}
+	return false
+}
+
+// Component tags a logger with the layer or module it belongs to. A nil log
+// falls back to the global logger.
+func Component(log *zap.Logger, name string) *zap.Logger {
+	if log == nil {
+		log = zap.L()
+	}
+	return log.With(zap.String("component", name))
+}
+
+type requestKey struct{}
+
+// Request holds the log fields of one HTTP request. The merchant and
+// transaction are filled in by handlers as soon as they are known.
+type Request struct {
+	mu            sync.RWMutex
+	id            string
+	merchant      string
+	transactionID string
+}
+
+// WithRequest starts the log fields of a request.
+func WithRequest(ctx context.Context, requestID string) (context.Context, *Request) {
+	req := &Request{id: requestID}
+	return context.WithValue(ctx, requestKey{}, req), req
+}
+
+// RequestFrom returns the request fields in ctx, or nil outside a request.
+// All Request methods are safe to call on nil.
+func RequestFrom(ctx context.Context) *Request {
+	if ctx == nil {
+		return nil
+	}
+	req, _ := ctx.Value(requestKey{}).(*Request)
+	return req
+}
+
+func (r *Request) ID() string {
+	if r == nil {
+		return ""
+	}
+	return r.id
+}
+
+func (r *Request) SetMerchant(merchant string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.merchant = merchant
+	r.mu.Unlock()
+}
+
+func (r *Request) SetTransactionID(transactionID string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.transactionID = transactionID
+	r.mu.Unlock()
+}
+
+func (r *Request) fields() []zap.Field {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fields := []zap.Field{zap.String("request_id", r.id)}
+	if r.merchant != "" {
+		fields = append(fields, zap.String("merchant", r.merchant))
+	}
+	if r.transactionID != "" {
+		fields = append(fields, zap.String("transaction_id", r.transactionID))
+	}
+	return fields
+}
+
+// For returns log with the request ID, merchant and transaction ID of the
+// request in ctx and the ID of its trace, if any.
+func For(ctx context.Context, log *zap.Logger) *zap.Logger {
+	if ctx == nil {
+		return log
+	}
+	var fields []zap.Field
+	if req := RequestFrom(ctx); req != nil {
+		fields = req.fields()
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+	}
+	if len(fields) == 0 {
+		return log
+	}
+	return log.With(fields...)
+}
+
+// SetTransactionID records the transaction a request is working on.
+func SetTransactionID(ctx context.Context, transactionID string) {
+	RequestFrom(ctx).SetTransactionID(transactionID)
+}
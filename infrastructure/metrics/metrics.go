@@ -0,0 +1,174 @@
+// Package metrics holds the Prometheus collectors of the service. They are
+// registered with the default registry and served on /metrics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const namespace = "payment"
+
+// UnmatchedRoute labels requests that did not match any route, so unknown
+// paths cannot blow up the number of series.
+const UnmatchedRoute = "unmatched"
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	gatewayRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "acleda",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of Acleda API calls by operation and result.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"operation", "result"})
+
+	workerQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "queue_depth",
+		Help:      "Jobs waiting in the payment worker queue.",
+	})
+
+	workerJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "job_duration_seconds",
+		Help:      "Time the payment worker spent on a job, by final status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+
+	rabbitPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rabbitmq",
+		Name:      "publish_failures_total",
+		Help:      "Events that could not be published to RabbitMQ.",
+	}, []string{"event"})
+
+	orphanedAcledaSessions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "acleda",
+		Name:      "orphaned_sessions_total",
+		Help:      "Acleda sessions flagged because no payment link could be stored for them.",
+	})
+
+	elasticPending = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "elasticsearch",
+		Name:      "index_pending",
+		Help:      "Documents handed to Elasticsearch that have not been acknowledged yet.",
+	}, []string{"index"})
+
+	elasticFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "elasticsearch",
+		Name:      "index_failures_total",
+		Help:      "Documents Elasticsearch failed to index.",
+	}, []string{"index"})
+)
+
+// ObserveHTTPRequest records one handled request. route is the route
+// pattern (e.g. /api/v1/acleda/payments/:id/status), never the raw path.
+func ObserveHTTPRequest(method, route string, status int, elapsed time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(elapsed.Seconds())
+}
+
+// ObserveGatewayCall records one Acleda API call.
+func ObserveGatewayCall(operation, result string, elapsed time.Duration) {
+	gatewayRequestDuration.WithLabelValues(operation, result).Observe(elapsed.Seconds())
+}
+
+func SetWorkerQueueDepth(depth int) {
+	workerQueueDepth.Set(float64(depth))
+}
+
+func ObserveWorkerJob(status string, elapsed time.Duration) {
+	workerJobDuration.WithLabelValues(status).Observe(elapsed.Seconds())
+}
+
+func IncRabbitPublishFailure(event string) {
+	rabbitPublishFailures.WithLabelValues(event).Inc()
+}
+
+func IncOrphanedAcledaSession() {
+	orphanedAcledaSessions.Inc()
+}
+
+// ElasticIndexStarted marks a document as queued for index. Call the
+// returned function once Elasticsearch has answered.
+func ElasticIndexStarted(index string) func(err error) {
+	pending := elasticPending.WithLabelValues(index)
+	pending.Inc()
+	return func(err error) {
+		pending.Dec()
+		if err != nil {
+			elasticFailures.WithLabelValues(index).Inc()
+		}
+	}
+}
+
+// PaymentLinkCounter counts stored payment links by status.
+type PaymentLinkCounter interface {
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// paymentLinkCollector reads the payment link counts from the database on
+// every scrape, so the numbers are right across restarts and replicas.
+type paymentLinkCollector struct {
+	counter PaymentLinkCounter
+	timeout time.Duration
+	desc    *prometheus.Desc
+}
+
+func (c *paymentLinkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *paymentLinkCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.counter.CountByStatus(ctx)
+	if err != nil {
+		zap.L().Warn("Failed to count payment links for metrics",
+			zap.String("component", "metrics"),
+			zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// RegisterPaymentLinkCounter exposes payment_links{status}. Registering a
+// second time is a no-op.
+func RegisterPaymentLinkCounter(counter PaymentLinkCounter) error {
+	err := prometheus.Register(&paymentLinkCollector{
+		counter: counter,
+		timeout: 5 * time.Second,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "links"),
+			"Acleda payment links by status.",
+			[]string{"status"}, nil,
+		),
+	})
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		return nil
+	}
+	return err
+}
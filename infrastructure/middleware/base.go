@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"payment-airpay/infrastructure/configuration"
 	"payment-airpay/infrastructure/database/repositories"
+	"payment-airpay/infrastructure/logger"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -20,12 +22,14 @@ type Middlewares struct {
 	// user code: `h.HttpResponse.ErrorResponseV2`
 	repo *repositories.MasterDataRepositoryYugabyteDB // Using simpler dependency since Repositories struct is missing
 	DB   *gorm.DB
+	cfg  *configuration.Config
 }
 
-func NewMiddlewares(log *zap.Logger, repo *repositories.MasterDataRepositoryYugabyteDB, db *gorm.DB) *Middlewares {
+func NewMiddlewares(log *zap.Logger, repo *repositories.MasterDataRepositoryYugabyteDB, db *gorm.DB, cfg *configuration.Config) *Middlewares {
 	return &Middlewares{
-		log:  log,
+		log:  logger.Component(log, "http"),
 		repo: repo,
 		DB:   db,
+		cfg:  cfg,
 	}
 }
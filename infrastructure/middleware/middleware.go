@@ -2,23 +2,40 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
 	"strings"
 	"time"
 
 	"payment-airpay/application/dto"
 	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/background"
 	"payment-airpay/infrastructure/database"
 	"payment-airpay/infrastructure/database/models"
+	"payment-airpay/infrastructure/logger"
+	"payment-airpay/infrastructure/metrics"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/mileusna/useragent"
-	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
 )
 
+// HeaderRequestID carries the request ID in and out of the service.
+const HeaderRequestID = "X-Request-ID"
+
 func (h *Middlewares) Incoming() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// before handler
+		requestID := c.Get(HeaderRequestID)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+		ctx, _ := logger.WithRequest(c.UserContext(), requestID)
+		c.SetUserContext(ctx)
+
 		if strings.ToLower(c.Get(fiber.HeaderContentType)) == "text/json" {
 			c.Request().Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
 		}
@@ -30,7 +47,7 @@ func (h *Middlewares) Incoming() fiber.Handler {
 		var incomingRequest dto.IncomingRequest
 		if len(reqBody) > 0 {
 			if err := c.BodyParser(&incomingRequest); err != nil {
-				logrus.Warn("Failed to parse incoming request body: ", err)
+				logger.For(ctx, h.log).Debug("Failed to parse incoming request body", zap.Error(err))
 			}
 		}
 
@@ -39,6 +56,7 @@ func (h *Middlewares) Incoming() fiber.Handler {
 
 		timeNow := time.Now()
 		incoming := entities.Incoming{
+			ID:            requestID,
 			CreatedAt:     timeNow,
 			Path:          c.Path(),
 			Method:        c.Method(),
@@ -77,6 +95,7 @@ func (h *Middlewares) Incoming() fiber.Handler {
 		c.Locals("incoming", &incoming)
 
 		// next to handler
+		self := c.Route()
 		err := c.Next()
 		if err != nil {
 			// Handle error if needed, or Fiber handles it
@@ -87,9 +106,24 @@ func (h *Middlewares) Incoming() fiber.Handler {
 		incoming.Latency = time.Since(timeNow).String()
 		incoming.StatusCode = c.Response().StatusCode()
 
-		if incoming.Save {
+		logger.For(ctx, h.log).Info("Request completed",
+			zap.String("method", incoming.Method),
+			zap.String("path", incoming.Path),
+			zap.Int("status", incoming.StatusCode),
+			zap.Duration("latency", time.Since(timeNow)))
+
+		// c.Route() is still this middleware when no route matched.
+		route := c.Route().Path
+		if c.Route() == self {
+			route = metrics.UnmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(incoming.Method, route, incoming.StatusCode, time.Since(timeNow))
+
+		if incoming.Save && database.ElasticsearchClient != nil {
 			// Save to ElasticSearch
-			go func(inc entities.Incoming) {
+			done := metrics.ElasticIndexStarted("incoming_logs")
+			inc := incoming
+			background.Go(func() {
 				// Convert to Elastic Model
 				elasticModel := models.IncomingElasticModel{
 					CreatedAt:     inc.CreatedAt,
@@ -121,15 +155,14 @@ func (h *Middlewares) Incoming() fiber.Handler {
 					Curency:       inc.Curency,
 				}
 
-				if database.ElasticsearchClient != nil {
-					_, err := database.ElasticsearchClient.Index("incoming_logs").
-						Request(&elasticModel).
-						Do(context.Background())
-					if err != nil {
-						logrus.Error("Failed to index incoming log to Elasticsearch: ", err)
-					}
+				_, err := database.ElasticsearchClient.Index("incoming_logs").
+					Request(&elasticModel).
+					Do(context.Background())
+				done(err)
+				if err != nil {
+					logger.For(ctx, h.log).Warn("Failed to index incoming log to Elasticsearch", zap.Error(err))
 				}
-			}(incoming)
+			})
 		}
 
 		return err
@@ -138,38 +171,66 @@ func (h *Middlewares) Incoming() fiber.Handler {
 
 func (h *Middlewares) Auth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		_, ok := c.Locals("incoming").(*entities.Incoming)
+		incoming, ok := c.Locals("incoming").(*entities.Incoming)
 		if !ok {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Incoming context missing"})
 		}
 
 		// Basic Auth from header
-		auth := c.Get("Authorization")
-		if auth == "" {
+		username, password, ok := parseBasicAuth(c.Get(fiber.HeaderAuthorization))
+		if !ok {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="acleda"`)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Unauthorized"})
 		}
 
-		// Parse Basic Auth manually or use Fiber's basicauth middleware helper if available
-		// Here doing simple check assuming standard Basic Auth format "Basic base64"
-		// For brevity, skipping manual parsing implementation details and assuming utility exists or implementing minimal
-		// ... (Implementation depends on requirement, but user code used c.Request().BasicAuth())
-
-		// Since we are rewriting, and Fiber doesn't have direct c.Request().BasicAuth() like Go http,
-		// we likely need to parse headers.
-		// However, to keep it simple and correct, I should parse it.
-		// NOTE: User's original code used `c.Request().BasicAuth()` which returns username, password, ok.
+		merchant, err := h.repo.GetMerchantByUsername(h.DB.WithContext(c.UserContext()), username)
+		if err != nil || subtle.ConstantTimeCompare([]byte(merchant.Password), []byte(password)) != 1 {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="acleda"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Unauthorized"})
+		}
 
-		// Placeholder for auth logic
-		// username, password, ok := parseBasicAuth(auth)
-		// ...
+		incoming.Merchant = merchant.Code
+		logger.RequestFrom(c.UserContext()).SetMerchant(merchant.Code)
+		c.Locals("merchant", &entities.Merchant{
+			ID:   merchant.ID.String(),
+			Code: merchant.Code,
+			Name: merchant.Name,
+		})
 
-		// For now, I will comment this out or leave it as TODO because I need to implement basic auth parsing
-		// or refer to `user` repo logic.
+		return c.Next()
+	}
+}
 
-		// Given the complexity of auth rewriting without full context, I will implement a placeholder that passes
-		// and add a TODO.
-		// Actually, I should probably ask user or implement standard parsing.
+// AdminAuth guards back-office endpoints with the ADMIN_USERNAME/ADMIN_PASSWORD
+// Basic credentials. The admin username is stored in Locals("admin").
+func (h *Middlewares) AdminAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		username, password, ok := parseBasicAuth(c.Get(fiber.HeaderAuthorization))
+		if !ok || h.cfg == nil || h.cfg.AdminUsername == "" || h.cfg.AdminPassword == "" ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(h.cfg.AdminUsername)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(h.cfg.AdminPassword)) != 1 {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="acleda-admin"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Unauthorized"})
+		}
 
+		c.Locals("admin", username)
 		return c.Next()
 	}
 }
+
+// parseBasicAuth mirrors net/http's Request.BasicAuth for a raw header value.
+func parseBasicAuth(auth string) (username, password string, ok bool) {
+	const prefix = "Basic "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	username, password, ok = strings.Cut(string(decoded), ":")
+	if !ok || username == "" {
+		return "", "", false
+	}
+	return username, password, true
+}
@@ -3,15 +3,20 @@ package publishers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 	"payment-airpay/application/messages"
 	"payment-airpay/application/services"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
 )
 
-type PublisherLog struct{}
+type PublisherLog struct {
+	log *zap.Logger
+}
 
-func NewPublisherLog() *PublisherLog {
-	return &PublisherLog{}
+func NewPublisherLog(log *zap.Logger) *PublisherLog {
+	return &PublisherLog{log: logger.Component(log, "publisher")}
 }
 
 func (p *PublisherLog) Publish(ctx context.Context, message services.Message) error {
@@ -20,10 +25,12 @@ func (p *PublisherLog) Publish(ctx context.Context, message services.Message) er
 		return err
 	}
 	switch message.GetMessageName() {
-	case messages.PaymentCreatedMessageName:
-		log.Printf("Publishing message: %s - %s", message.GetMessageName(), string(payload))
+	case messages.PaymentCreatedMessageName, messages.RefundStatusChangedMessageName:
+		logger.For(ctx, p.log).Info("Publishing message",
+			zap.String("message", message.GetMessageName()),
+			zap.ByteString("payload", payload))
 	default:
-		log.Printf("Publishing unknown message: %T", message)
+		logger.For(ctx, p.log).Warn("Publishing unknown message", zap.String("type", fmt.Sprintf("%T", message)))
 	}
 	return nil
 }
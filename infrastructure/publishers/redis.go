@@ -1,10 +1,10 @@
 package publishers
 
 import (
-	"fmt"
-	"log"
 	"payment-airpay/infrastructure/configuration"
 	"strconv"
+
+	"go.uber.org/zap"
 )
 
 // LogRedisClient simulates a Redis client for demonstration purposes
@@ -18,8 +18,17 @@ func InitializeRedis() {
 
 	redisAddr := configuration.AppConfig.RedisHost + ":" + strconv.Itoa(configuration.AppConfig.RedisPort)
 
-	fmt.Println("=== Load Cache, Pub/Sub Redis ===")
-	log.Printf("Simulated Redis connection established to %s (DB: %d)",
-		redisAddr, configuration.AppConfig.RedisDatabase)
-	fmt.Println("=== Load Cache, Pub/Sub Redis ===")
+	zap.L().Info("Simulated Redis connection established",
+		zap.String("component", "redis"),
+		zap.String("addr", redisAddr),
+		zap.Int("db", configuration.AppConfig.RedisDatabase))
+}
+
+// CloseRedis releases the Redis client.
+func CloseRedis() {
+	if RDS == nil {
+		return
+	}
+	RDS = nil
+	zap.L().Info("Redis connection closed", zap.String("component", "redis"))
 }
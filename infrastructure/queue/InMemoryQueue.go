@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"context"
+	"sync"
+
+	"payment-airpay/application/services"
+)
+
+// InMemoryQueue implements services.EventQueue by keeping events in memory.
+// It is used when no broker is configured and by local test harnesses.
+type InMemoryQueue struct {
+	mu     sync.Mutex
+	events []services.Event
+}
+
+func NewInMemoryQueue() *InMemoryQueue {
+	return &InMemoryQueue{}
+}
+
+func (q *InMemoryQueue) Enqueue(ctx context.Context, event services.Event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, event)
+	return nil
+}
+
+// Events returns a snapshot of every event enqueued so far.
+func (q *InMemoryQueue) Events() []services.Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]services.Event, len(q.events))
+	copy(out, q.events)
+	return out
+}
@@ -10,8 +10,10 @@ import (
 
 	"payment-airpay/application/events"
 	"payment-airpay/application/services"
+	"payment-airpay/infrastructure/metrics"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // RabbitMQQueue implements services.Queue and publishes supported tasks/events to RabbitMQ
@@ -24,19 +26,24 @@ func NewRabbitMQQueue(channel *amqp.Channel) *RabbitMQQueue {
 	return &RabbitMQQueue{ch: channel}
 }
 
-// Enqueue publishes a task to RabbitMQ. This implementation only supports
-// publishing PaymentCreatedEvent. It uses an exchange named after the event
+// SupportedEventNames lists the events RabbitMQQueue will publish. Each one gets
+// its own fanout exchange and queue in InitializeRabbitMQ.
+var SupportedEventNames = []string{
+	events.PaymentCreatedEventName,
+	events.RefundStatusChangedEventName,
+}
+
+// Enqueue publishes a task to RabbitMQ. Only events listed in
+// SupportedEventNames are accepted. It uses an exchange named after the event
 // (fanout) and publishes the JSON payload with persistent delivery mode.
 func (r *RabbitMQQueue) Enqueue(ctx context.Context, event services.Event) error {
 	if r == nil || r.ch == nil {
 		return errors.New("rabbitmq channel is not initialized; call InitializeRabbitMQ first")
 	}
 
-	// Only allow the PaymentCreatedEvent to be published via RabbitMQQueue
-	allowedEventName := (events.PaymentCreatedEvent{}).GetEventName()
 	eventName := event.GetEventName()
-	if eventName != allowedEventName {
-		return fmt.Errorf("unsupported event for RabbitMQQueue: %s (only %s is supported)", eventName, allowedEventName)
+	if !isSupportedEvent(eventName) {
+		return fmt.Errorf("unsupported event for RabbitMQQueue: %s (supported: %s)", eventName, strings.Join(SupportedEventNames, ", "))
 	}
 
 	payload, err := json.Marshal(event)
@@ -53,6 +60,9 @@ func (r *RabbitMQQueue) Enqueue(ctx context.Context, event services.Event) error
 	}
 
 	exchangeName := strings.ReplaceAll(eventName, "-", ".")
+	ctx, span := startPublishSpan(ctx, exchangeName, &pub)
+	defer span.End()
+
 	if err := r.ch.PublishWithContext(ctx,
 		exchangeName, // exchange
 		"",           // routing key (ignored for fanout)
@@ -60,8 +70,20 @@ func (r *RabbitMQQueue) Enqueue(ctx context.Context, event services.Event) error
 		false,        // immediate
 		pub,
 	); err != nil {
+		metrics.IncRabbitPublishFailure(eventName)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "")
 		return fmt.Errorf("failed to publish message to %s: %w", eventName, err)
 	}
 
 	return nil
 }
+
+func isSupportedEvent(name string) bool {
+	for _, supported := range SupportedEventNames {
+		if supported == name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,12 @@
 package queue
 
 import (
-	"fmt"
-	"log"
-	"payment-airpay/application/events"
+	"errors"
+	"net/url"
 	"payment-airpay/infrastructure/configuration"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
 var RabbitConn *amqp.Connection
@@ -14,20 +14,26 @@ var RabbitChan *amqp.Channel
 
 func InitializeRabbitMQ() {
 	var err error
-	fmt.Println("RabbitMQ URI: ", configuration.AppConfig.RabbitMQURI)
+	log := rabbitLog()
+	log.Info("Connecting to RabbitMQ", zap.String("uri", redactURI(configuration.AppConfig.RabbitMQURI)))
 	RabbitConn, err = amqp.Dial(configuration.AppConfig.RabbitMQURI)
 	if err != nil {
-		log.Fatal("Failed to connect to RabbitMQ: ", err)
+		log.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
 	}
 
 	RabbitChan, err = RabbitConn.Channel()
 	if err != nil {
-		log.Fatal("Failed to open a channel: ", err)
+		log.Fatal("Failed to open a channel", zap.Error(err))
 	}
 
+	for _, exchangeName := range SupportedEventNames {
+		declareEventTopology(exchangeName)
+	}
+}
+
+func declareEventTopology(exchangeName string) {
 	// Declare a Exchange to ensure it exists
-	exchangeName := (events.PaymentCreatedEvent{}).GetEventName()
-	err = RabbitChan.ExchangeDeclare(
+	err := RabbitChan.ExchangeDeclare(
 		exchangeName, // name
 		"fanout",     // type
 		true,         // durable
@@ -37,7 +43,7 @@ func InitializeRabbitMQ() {
 		nil,          // arguments
 	)
 	if err != nil {
-		log.Fatal("Failed to declare exchange: ", err)
+		rabbitLog().Fatal("Failed to declare exchange", zap.String("exchange", exchangeName), zap.Error(err))
 	}
 
 	// Declare queue to ensure it exists
@@ -51,7 +57,7 @@ func InitializeRabbitMQ() {
 		nil,       // arguments
 	)
 	if err != nil {
-		log.Fatal("Failed to declare queue: ", err)
+		rabbitLog().Fatal("Failed to declare queue", zap.String("queue", queueName), zap.Error(err))
 	}
 
 	// Bind the queue to the exchange so fanout routes messages to this queue
@@ -62,15 +68,33 @@ func InitializeRabbitMQ() {
 		false,        // no-wait
 		nil,          // args
 	); err != nil {
-		log.Fatal("Failed to bind queue to exchange: ", err)
+		rabbitLog().Fatal("Failed to bind queue to exchange", zap.String("exchange", exchangeName), zap.Error(err))
 	}
 }
 
-func CloseRabbitMQ() {
-	if RabbitConn != nil {
-		RabbitConn.Close()
+func rabbitLog() *zap.Logger {
+	return zap.L().With(zap.String("component", "rabbitmq"))
+}
+
+// redactURI hides the password in an amqp:// URI before it is logged.
+func redactURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "<invalid uri>"
 	}
+	return u.Redacted()
+}
+
+// CloseRabbitMQ closes the channel and then the connection.
+func CloseRabbitMQ() {
 	if RabbitChan != nil {
-		RabbitChan.Close()
+		if err := RabbitChan.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			rabbitLog().Warn("Failed to close channel", zap.Error(err))
+		}
+	}
+	if RabbitConn != nil {
+		if err := RabbitConn.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			rabbitLog().Warn("Failed to close connection", zap.Error(err))
+		}
 	}
 }
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"payment-airpay/infrastructure/tracing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier lets the OpenTelemetry propagator read and write AMQP
+// message headers.
+type headerCarrier amqp.Table
+
+func (h headerCarrier) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startPublishSpan opens a producer span and writes its trace context into
+// the message headers.
+func startPublishSpan(ctx context.Context, exchange string, msg *amqp.Publishing) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+exchange,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "send"),
+			attribute.String("messaging.destination.name", exchange),
+		),
+	)
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Headers))
+	return ctx, span
+}
+
+// StartConsumeSpan continues the trace of a published message. Consumers
+// call it for every delivery and end the span when the message is handled.
+func StartConsumeSpan(ctx context.Context, d amqp.Delivery) (context.Context, trace.Span) {
+	if d.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(d.Headers))
+	}
+	return tracing.Tracer().Start(ctx, fmt.Sprintf("process %s", d.Exchange),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.destination.name", d.Exchange),
+			attribute.String("messaging.message.id", d.MessageId),
+		),
+	)
+}
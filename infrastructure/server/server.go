@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"payment-airpay/infrastructure/controllers"
+	"payment-airpay/infrastructure/middleware"
+	"payment-airpay/infrastructure/views"
+	"payment-airpay/infrastructure/workers"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handlers groups everything the HTTP server routes to. Each field is built by
+// the caller, so the app can be assembled from real or stand-in dependencies.
+type Handlers struct {
+	Middlewares    *middleware.Middlewares
+	Acleda         *controllers.AcledaController
+	AcledaStaging  *controllers.AcledaStagingController
+	Reconciliation *controllers.ReconciliationController
+	Currencies     *controllers.MerchantCurrencyController
+	MasterData     *controllers.MasterDataController
+	Credentials    *controllers.AcledaCredentialController
+	PaymentAdmin   *controllers.PaymentAdminController
+	LogLevel       *controllers.LogLevelController
+	Health         *controllers.HealthController
+	Worker         *workers.Worker
+}
+
+// New builds the Fiber app and registers every route.
+func New(engine fiber.Views, h Handlers) *fiber.App {
+	app := fiber.New(fiber.Config{
+		Views: engine,
+	})
+
+	// Registered before Incoming so scrapes, probes and static assets are not
+	// logged or counted.
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+	app.Get("/healthz", h.Health.Liveness)
+	app.Get("/readyz", h.Health.Readiness)
+	app.Use(views.StaticPrefix, filesystem.New(filesystem.Config{
+		Root:   http.FS(views.Static()),
+		MaxAge: int((24 * time.Hour).Seconds()),
+	}))
+
+	app.Use(h.Middlewares.Tracing())
+	app.Use(h.Middlewares.Incoming())
+
+	// Register routes
+	app.Post("/payment/acleda", h.Worker.PaymentHandler)
+	app.Post("/payment/acleda/async", h.Worker.EnqueueHandler)
+	app.Get("/jobs/status", h.Worker.StatusHandler)
+
+	// Setup Acleda controller routes
+	auth := h.Middlewares.Auth()
+	app.Post("/api/v1/acleda/payment-links", auth, h.Acleda.CreatePaymentLink)
+	app.Get("/payment-page/acleda/:id", h.Acleda.PaymentPage)
+	app.All("/payment-page/acleda/:id/return", h.Acleda.PaymentReturn)
+	app.Get("/api/v1/acleda/payments", auth, h.Acleda.ListPayments)
+	app.Get("/api/v1/acleda/payments/:id/status", auth, h.Acleda.GetPaymentStatus)
+	app.Get("/api/v1/acleda/payments/:id/history", auth, h.Acleda.ListStatusHistory)
+	app.Post("/api/v1/acleda/payments/:id/refunds", auth, h.Acleda.CreateRefund)
+	app.Get("/api/v1/acleda/payments/:id/refunds", auth, h.Acleda.ListRefunds)
+
+	// Setup Acleda Staging controller routes
+	app.Post("/api/v2/payment/acleda", h.AcledaStaging.CreateStagingPayment)
+	app.Get("/api/v2/payment/acleda/:id/status", auth, h.AcledaStaging.GetStagingPaymentStatus)
+
+	// Setup back-office routes
+	admin := app.Group("/api/v1/admin", h.Middlewares.AdminAuth())
+	admin.Post("/reconciliations", h.Reconciliation.UploadSettlement)
+	admin.Get("/reconciliations", h.Reconciliation.ListReports)
+	admin.Get("/reconciliations/unmatched", h.Reconciliation.ListExceptions)
+	admin.Get("/reconciliations/:id", h.Reconciliation.GetReport)
+	admin.Get("/merchants/:code/currencies", h.Currencies.GetCurrencies)
+	admin.Put("/merchants/:code/currencies", h.Currencies.SetCurrencies)
+	admin.Get("/merchants/:code/acleda-credentials", h.Credentials.GetCredentials)
+	admin.Put("/merchants/:code/acleda-credentials", h.Credentials.SetCredentials)
+	admin.Get("/refunds/pending", h.PaymentAdmin.ListPendingRefunds)
+	admin.Post("/refunds/:id/resolve", h.PaymentAdmin.ResolveRefund)
+	admin.Put("/payments/:id/status", h.PaymentAdmin.SetPaymentStatus)
+	admin.Get("/payments/:id/history", h.PaymentAdmin.ListStatusHistory)
+	admin.Get("/master-data/:kind", h.MasterData.List)
+	admin.Post("/master-data/:kind", h.MasterData.Create)
+	admin.Get("/master-data/:kind/:id", h.MasterData.Get)
+	admin.Put("/master-data/:kind/:id", h.MasterData.Update)
+	admin.Delete("/master-data/:kind/:id", h.MasterData.Delete)
+	admin.Get("/log-level", h.LogLevel.GetLevel)
+	admin.Put("/log-level", h.LogLevel.SetLevel)
+
+	return app
+}
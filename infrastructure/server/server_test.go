@@ -0,0 +1,375 @@
+package server_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/configuration"
+	"payment-airpay/infrastructure/crypto"
+	"payment-airpay/infrastructure/database"
+	"payment-airpay/infrastructure/database/clients"
+	"payment-airpay/infrastructure/database/dbtest"
+	"payment-airpay/infrastructure/database/models"
+	"payment-airpay/infrastructure/dependencies"
+	"payment-airpay/infrastructure/gateway/acleda/acledatest"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// merchantReturnURL and merchantErrorURL are where the merchant wants the
+// customer sent after paying.
+const (
+	merchantReturnURL = "https://shop.example/paid"
+	merchantErrorURL  = "https://shop.example/failed"
+)
+
+// testEnv is the whole service built by dependencies.ProvideServer, as
+// main.go builds it, against the test database, a fake Acleda and an
+// in-memory event queue. Each test gets its own merchant.
+type testEnv struct {
+	app      *fiber.App
+	acleda   *acledatest.Server
+	db       clients.YugabyteClient
+	statuses *services.UpdateAcledaPaymentStatusService
+
+	username, password string
+}
+
+// The providers build each dependency once per process, so the app and the
+// fake Acleda behind it are shared by all tests of the package.
+var (
+	appOnce  sync.Once
+	app      *fiber.App
+	fake     *acledatest.Server
+	statuses *services.UpdateAcledaPaymentStatusService
+)
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	db := dbtest.Open(t)
+
+	appOnce.Do(func() {
+		installTestKeyRing(t)
+		signer, err := crypto.NewEphemeralURLSigner()
+		if err != nil {
+			t.Fatal(err)
+		}
+		crypto.SetDefaultURLSigner(signer)
+
+		fake = acledatest.NewServer()
+		configuration.AppConfig = &configuration.Config{
+			LogLevel:               "error",
+			AcledaAPIURL:           fake.URL,
+			ACLEDAOPENSESSIONV2URL: fake.OpenSessionV2URL(),
+			AcledaBaseURL:          "https://pay.example",
+			AcledaPaymentPageURL:   fake.URL + "/paymentPage",
+			AcledaMerchantID:       "ACL-MERCHANT",
+			AcledaLogin:            "login",
+			AcledaRemotePassword:   "remote-password",
+			AcledaSecret:           "secret",
+			AcledaTimeout:          5000,
+			AdminUsername:          "admin",
+			AdminPassword:          "admin-password",
+		}
+		database.YugabyteDBClient = db.GetDB()
+		database.YugabyteReadDBClient = nil
+
+		app = dependencies.ProvideServer()
+		statuses = dependencies.ProvideUpdateAcledaPaymentStatusService()
+	})
+	if app == nil {
+		t.Fatal("the app was not built")
+	}
+	t.Cleanup(func() { fake.ErrorDetails = "" })
+
+	env := &testEnv{
+		app:      app,
+		acleda:   fake,
+		db:       db,
+		statuses: statuses,
+		username: "e2e-" + uuid.NewString(),
+		password: "e2e-password",
+	}
+	env.createMerchant(t)
+	return env
+}
+
+// installTestKeyRing lets merchant passwords be sealed and read. Tests of one
+// package run in one process, so the key ring is shared by all of them.
+func installTestKeyRing(t *testing.T) {
+	t.Helper()
+	if crypto.DefaultKeyRing() != nil {
+		return
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	ring, err := crypto.NewKeyRing("e2e", map[string][]byte{"e2e": key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crypto.SetDefaultKeyRing(ring)
+}
+
+func (e *testEnv) createMerchant(t *testing.T) {
+	t.Helper()
+	active := "ACTIVE"
+	merchant := models.MerchantsDataModel{
+		Code:       "E2E-" + uuid.NewString(),
+		Name:       "E2E Shop",
+		Username:   e.username,
+		Password:   crypto.EncryptedString(e.password),
+		DataStatus: &active,
+	}
+	if err := e.db.GetDB().Create(&merchant).Error; err != nil {
+		t.Fatalf("failed to create merchant: %v", err)
+	}
+}
+
+// do sends a request to the app. A non-nil body is sent as JSON; auth adds
+// the merchant's Basic credentials.
+func (e *testEnv) do(t *testing.T, method, target string, body interface{}, auth bool) *http.Response {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = strings.NewReader(string(raw))
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	if auth {
+		req.SetBasicAuth(e.username, e.password)
+	}
+	resp, err := e.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+func expectStatus(t *testing.T, resp *http.Response, want int) {
+	t.Helper()
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s = %d; want %d: %s", resp.Request.Method, resp.Request.URL, resp.StatusCode, want, body)
+	}
+}
+
+// createLink creates a payment link through the API and returns it.
+func (e *testEnv) createLink(t *testing.T, expiryMinutes int) services.CreateAcledaPaymentLinkOutput {
+	t.Helper()
+	resp := e.do(t, http.MethodPost, "/api/v1/acleda/payment-links", createLinkInput(expiryMinutes), true)
+	expectStatus(t, resp, http.StatusOK)
+
+	var body struct {
+		Data services.CreateAcledaPaymentLinkOutput `json:"data"`
+	}
+	decode(t, resp, &body)
+	if body.Data.TransactionID == "" || body.Data.PaymentURL == "" {
+		t.Fatalf("create returned %+v; want a transaction ID and payment URL", body.Data)
+	}
+	return body.Data
+}
+
+func createLinkInput(expiryMinutes int) services.CreateAcledaPaymentLinkInput {
+	return services.CreateAcledaPaymentLinkInput{
+		Amount:        "10.50",
+		Currency:      "USD",
+		Description:   "Order 42",
+		CustomerName:  "Sok Dara",
+		CustomerEmail: "dara@example.com",
+		CustomerPhone: "+85512345678",
+		ReturnURL:     merchantReturnURL,
+		CallbackURL:   merchantErrorURL,
+		ExpiredTime:   expiryMinutes,
+	}
+}
+
+func (e *testEnv) status(t *testing.T, transactionID string) services.AcledaPaymentStatusOutput {
+	t.Helper()
+	resp := e.do(t, http.MethodGet, "/api/v1/acleda/payments/"+url.PathEscape(transactionID)+"/status", nil, true)
+	expectStatus(t, resp, http.StatusOK)
+
+	var body struct {
+		Success bool                               `json:"success"`
+		Data    services.AcledaPaymentStatusOutput `json:"data"`
+	}
+	decode(t, resp, &body)
+	return body.Data
+}
+
+func (e *testEnv) history(t *testing.T, transactionID string) []entities.PaymentStatusChange {
+	t.Helper()
+	resp := e.do(t, http.MethodGet, "/api/v1/acleda/payments/"+url.PathEscape(transactionID)+"/history", nil, true)
+	expectStatus(t, resp, http.StatusOK)
+
+	var body struct {
+		Data []entities.PaymentStatusChange `json:"data"`
+	}
+	decode(t, resp, &body)
+	return body.Data
+}
+
+// pagePath strips the public base URL from a payment URL.
+func pagePath(t *testing.T, paymentURL string) string {
+	t.Helper()
+	u, err := url.Parse(paymentURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.RequestURI()
+}
+
+func TestPaymentLinkIsPaid(t *testing.T) {
+	env := newTestEnv(t)
+
+	// Creating a link needs the merchant's credentials.
+	resp := env.do(t, http.MethodPost, "/api/v1/acleda/payment-links", createLinkInput(30), false)
+	expectStatus(t, resp, http.StatusUnauthorized)
+
+	link := env.createLink(t, 30)
+
+	if link.Status != entities.PaymentLinkStatusPending || link.Amount != "10.50" || link.Currency != "USD" {
+		t.Fatalf("created link = %+v; want a PENDING link for 10.50 USD", link)
+	}
+	session, ok := env.acleda.Sessions()[link.SessionID]
+	if !ok {
+		t.Fatalf("session %s was not opened with Acleda", link.SessionID)
+	}
+	if session.XPayTransaction.TxID != link.TransactionID || session.XPayTransaction.PurchaseAmount != "10.50" {
+		t.Fatalf("session request = %+v; want transaction %s for 10.50", session.XPayTransaction, link.TransactionID)
+	}
+
+	// The checkout page posts the stored session to Acleda, which sends the
+	// customer back to this service rather than to the merchant.
+	resp = env.do(t, http.MethodGet, pagePath(t, link.PaymentURL), nil, false)
+	expectStatus(t, resp, http.StatusOK)
+	page, _ := io.ReadAll(resp.Body)
+	returnPath := "/payment-page/acleda/" + url.PathEscape(link.TransactionID) + "/return"
+	for _, want := range []string{link.SessionID, link.PaymentTokenID, "10.50", returnPath} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("payment page does not contain %q", want)
+		}
+	}
+
+	// A page URL without the signed token is refused.
+	resp = env.do(t, http.MethodGet, "/payment-page/acleda/"+url.PathEscape(link.TransactionID), nil, false)
+	expectStatus(t, resp, http.StatusForbidden)
+
+	// The status endpoint needs the merchant's credentials.
+	resp = env.do(t, http.MethodGet, "/api/v1/acleda/payments/"+url.PathEscape(link.TransactionID)+"/status", nil, false)
+	expectStatus(t, resp, http.StatusUnauthorized)
+	got := env.status(t, link.TransactionID)
+	if got.Status != entities.PaymentLinkStatusPending || got.TransactionID != link.TransactionID || got.Amount != "10.50" {
+		t.Fatalf("status = %+v; want a PENDING link for 10.50", got)
+	}
+
+	// The customer pays and Acleda sends them back; the link is settled with
+	// what the bank reports before they reach the merchant.
+	env.acleda.SetPaymentStatus(link.TransactionID, "PAID")
+	resp = env.do(t, http.MethodGet, returnPath+"?result=success", nil, false)
+	expectStatus(t, resp, http.StatusSeeOther)
+	if got := resp.Header.Get(fiber.HeaderLocation); got != merchantReturnURL {
+		t.Fatalf("return redirected to %q; want %q", got, merchantReturnURL)
+	}
+
+	if got := env.status(t, link.TransactionID); got.Status != entities.PaymentLinkStatusPaid {
+		t.Fatalf("status after callback = %s; want PAID", got.Status)
+	}
+	history := env.history(t, link.TransactionID)
+	if len(history) == 0 {
+		t.Fatal("history is empty after the payment")
+	}
+	last := history[len(history)-1]
+	if last.ToStatus != entities.PaymentLinkStatusPaid || last.Source != entities.StatusSourceCallback {
+		t.Fatalf("last history entry = %+v; want PAID from callback", last)
+	}
+	if last.Evidence != "" || last.Actor != "" {
+		t.Fatalf("merchant history exposes actor %q and evidence %q", last.Actor, last.Evidence)
+	}
+
+	// A paid link can no longer be paid.
+	resp = env.do(t, http.MethodGet, pagePath(t, link.PaymentURL), nil, false)
+	expectStatus(t, resp, http.StatusGone)
+
+	resp = env.do(t, http.MethodGet, "/api/v1/acleda/payments/ACL-UNKNOWN/status", nil, true)
+	expectStatus(t, resp, http.StatusNotFound)
+}
+
+func TestPaymentLinkFailsAtTheBank(t *testing.T) {
+	env := newTestEnv(t)
+	link := env.createLink(t, 30)
+
+	env.acleda.SetPaymentStatus(link.TransactionID, "DECLINED")
+	resp := env.do(t, http.MethodPost, "/payment-page/acleda/"+url.PathEscape(link.TransactionID)+"/return?result=error", nil, false)
+	expectStatus(t, resp, http.StatusSeeOther)
+	if got := resp.Header.Get(fiber.HeaderLocation); got != merchantErrorURL {
+		t.Fatalf("return redirected to %q; want %q", got, merchantErrorURL)
+	}
+	if got := env.status(t, link.TransactionID); got.Status != entities.PaymentLinkStatusFailed {
+		t.Fatalf("status after declined payment = %s; want FAILED", got.Status)
+	}
+}
+
+func TestPaymentLinkExpires(t *testing.T) {
+	env := newTestEnv(t)
+	link := env.createLink(t, 1)
+
+	// Move the link into the past instead of waiting for it to expire.
+	err := env.db.GetDB().Exec("UPDATE payment_acleda_payment_links SET created_at = created_at - interval '10 minutes' WHERE transaction_id = ?", link.TransactionID).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each pass sweeps a batch of the oldest expired links; links left
+	// behind by earlier runs may come first.
+	ctx := context.Background()
+	for pass := 0; pass < 20; pass++ {
+		if env.status(t, link.TransactionID).Status != entities.PaymentLinkStatusPending {
+			break
+		}
+		out, err := env.statuses.Execute(ctx)
+		if err != nil {
+			t.Fatalf("status pass: %v", err)
+		}
+		if out.Expired == 0 && out.Changed == 0 {
+			break
+		}
+	}
+
+	if got := env.status(t, link.TransactionID); got.Status != entities.PaymentLinkStatusExpired {
+		t.Fatalf("status after sweep = %s; want EXPIRED", got.Status)
+	}
+	history := env.history(t, link.TransactionID)
+	if len(history) == 0 || history[len(history)-1].Source != entities.StatusSourceSweeper {
+		t.Fatalf("history = %+v; want the sweeper's change last", history)
+	}
+
+	resp := env.do(t, http.MethodGet, pagePath(t, link.PaymentURL), nil, false)
+	expectStatus(t, resp, http.StatusGone)
+}
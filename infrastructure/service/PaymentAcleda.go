@@ -3,13 +3,15 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"payment-airpay/domain/entities"
 	"payment-airpay/infrastructure/database/connectors"
 	"payment-airpay/infrastructure/database/repositories"
 	"payment-airpay/infrastructure/gateway/acleda"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
 )
 
 type PaymentAcleda struct {
@@ -17,6 +19,7 @@ type PaymentAcleda struct {
 	paymentRepo    *repositories.PaymentRepositoryYugabyteDB
 	acledaRepo     *repositories.AcledaRepositoryYugabyteDB
 	db             *connectors.YugabyteConnector
+	log            *zap.Logger
 }
 
 type CreatePaymentInput struct {
@@ -64,17 +67,20 @@ func NewPaymentAcleda(
 	paymentRepo *repositories.PaymentRepositoryYugabyteDB,
 	acledaRepo *repositories.AcledaRepositoryYugabyteDB,
 	db *connectors.YugabyteConnector,
+	log *zap.Logger,
 ) *PaymentAcleda {
 	return &PaymentAcleda{
 		masterDataRepo: masterDataRepo,
 		paymentRepo:    paymentRepo,
 		acledaRepo:     acledaRepo,
 		db:             db,
+		log:            logger.Component(log, "payment"),
 	}
 }
 
 func (s *PaymentAcleda) CreatePayment(ctx context.Context, in CreatePaymentInput) (*CreatePaymentOutput, error) {
-	log.Printf("Creating Acleda payment with reference ID: %s", in.ReferenceID)
+	log := logger.For(ctx, s.log).With(zap.String("reference_id", in.ReferenceID))
+	log.Debug("Creating Acleda payment")
 
 	// Validate input
 	if in.Amount == "" {
@@ -114,14 +120,14 @@ func (s *PaymentAcleda) CreatePayment(ctx context.Context, in CreatePaymentInput
 	// Call Acleda gateway
 	gatewayResp, err := s.callAcledaGateway(gatewayReq)
 	if err != nil {
-		log.Printf("Failed to call Acleda gateway: %v", err)
+		log.Error("Failed to call Acleda gateway", zap.Error(err))
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
 	// Save to database
-	err = s.savePaymentToDatabase(gatewayResp)
+	err = s.savePaymentToDatabase(ctx, gatewayResp)
 	if err != nil {
-		log.Printf("Failed to save payment to database: %v", err)
+		log.Warn("Failed to save payment", zap.Error(err))
 		// Continue even if database save fails
 	}
 
@@ -137,23 +143,25 @@ func (s *PaymentAcleda) CreatePayment(ctx context.Context, in CreatePaymentInput
 		CreatedAt:     gatewayResp.CreatedAt,
 	}
 
-	log.Printf("Successfully created Acleda payment with transaction ID: %s", gatewayResp.TransactionID)
+	log.Info("Acleda payment created", zap.String("transaction_id", gatewayResp.TransactionID))
 	return out, nil
 }
 
 // Save implements TransactionService interface
 func (s *PaymentAcleda) Save(ctx context.Context, payment entities.Payment, payload map[string]interface{}) error {
-	log.Printf("Saving Acleda payment to database: %s", payment.PaymentRequestID)
+	log := logger.For(ctx, s.log).With(zap.String("payment_request_id", payment.PaymentRequestID))
+	log.Debug("Saving Acleda payment")
 
 	// Placeholder for actual database save operation
 	// This would save the payment to the database using repositories
 
-	log.Printf("Successfully saved Acleda payment: %s", payment.PaymentRequestID)
+	log.Debug("Acleda payment saved")
 	return nil
 }
 
 func (s *PaymentAcleda) GetPaymentStatus(ctx context.Context, in PaymentStatusInput) (*PaymentStatusOutput, error) {
-	log.Printf("Getting Acleda payment status for transaction ID: %s", in.TransactionID)
+	log := logger.For(ctx, s.log).With(zap.String("transaction_id", in.TransactionID))
+	log.Debug("Getting Acleda payment status")
 
 	// Validate input
 	if in.TransactionID == "" {
@@ -168,14 +176,14 @@ func (s *PaymentAcleda) GetPaymentStatus(ctx context.Context, in PaymentStatusIn
 	// Call Acleda gateway
 	gatewayResp, err := s.callAcledaStatusGateway(gatewayReq)
 	if err != nil {
-		log.Printf("Failed to call Acleda status gateway: %v", err)
+		log.Error("Failed to call Acleda status gateway", zap.Error(err))
 		return nil, fmt.Errorf("failed to get payment status: %w", err)
 	}
 
 	// Update database with latest status
-	err = s.updatePaymentStatusInDatabase(gatewayResp)
+	err = s.updatePaymentStatusInDatabase(ctx, gatewayResp)
 	if err != nil {
-		log.Printf("Failed to update payment status in database: %v", err)
+		log.Warn("Failed to update payment status", zap.Error(err))
 		// Continue even if database update fails
 	}
 
@@ -192,7 +200,7 @@ func (s *PaymentAcleda) GetPaymentStatus(ctx context.Context, in PaymentStatusIn
 		FailureReason: gatewayResp.FailureReason,
 	}
 
-	log.Printf("Successfully retrieved Acleda payment status for transaction ID: %s", gatewayResp.TransactionID)
+	log.Debug("Acleda payment status retrieved", zap.String("payment_status", gatewayResp.PaymentStatus))
 	return out, nil
 }
 
@@ -221,18 +229,18 @@ func (s *PaymentAcleda) callAcledaStatusGateway(req acleda.PaymentStatusRequest)
 		ReferenceID:   fmt.Sprintf("REF-%d", time.Now().Unix()),
 		Amount:        "100.00",
 		Currency:      "USD",
-		PaymentStatus: "pending",
+		PaymentStatus: entities.PaymentLinkStatusPending,
 	}, nil
 }
 
-func (s *PaymentAcleda) savePaymentToDatabase(resp *acleda.CreatePaymentResponse) error {
+func (s *PaymentAcleda) savePaymentToDatabase(ctx context.Context, resp *acleda.CreatePaymentResponse) error {
 	// Placeholder for database save operation
-	log.Printf("Saving payment to database: %s", resp.TransactionID)
+	logger.For(ctx, s.log).Debug("Saving payment", zap.String("transaction_id", resp.TransactionID))
 	return nil
 }
 
-func (s *PaymentAcleda) updatePaymentStatusInDatabase(resp *acleda.PaymentStatusResponse) error {
+func (s *PaymentAcleda) updatePaymentStatusInDatabase(ctx context.Context, resp *acleda.PaymentStatusResponse) error {
 	// Placeholder for database update operation
-	log.Printf("Updating payment status in database: %s", resp.TransactionID)
+	logger.For(ctx, s.log).Debug("Updating payment status", zap.String("transaction_id", resp.TransactionID))
 	return nil
 }
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"payment-airpay/infrastructure/database"
 	"payment-airpay/infrastructure/database/models"
 	pkg "payment-airpay/infrastructure/gateway"
+	"payment-airpay/infrastructure/metrics"
+	"payment-airpay/infrastructure/tracing"
+
+	"go.uber.org/zap"
 )
 
 func formatErrorToString(err error) string {
@@ -51,13 +54,24 @@ func SaveAPICall(
 	}
 
 	if database.ElasticsearchClient != nil {
+		_, span := tracing.Tracer().Start(ctx, "elasticsearch.index api_call_logs")
+		done := metrics.ElasticIndexStarted("api_call_logs")
 		_, err := database.ElasticsearchClient.Index("api_call_logs").
 			Request(&data).
 			Do(context.Background())
+		done(err)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
 		if err != nil {
-			fmt.Printf("Failed to save API call to Elasticsearch: %v\n", err)
+			zap.L().Warn("Failed to save API call to Elasticsearch",
+				zap.String("component", "api-call-log"),
+				zap.String("merchant", merchant),
+				zap.String("transaction_id", transactionId),
+				zap.Error(err))
 		}
 	} else {
-		fmt.Println("ElasticsearchClient is not initialized")
+		zap.L().Debug("Elasticsearch is not initialized; API call not saved", zap.String("component", "api-call-log"))
 	}
 }
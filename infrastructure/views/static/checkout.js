@@ -0,0 +1,62 @@
+// Shows the time left on the payment link and blocks paying once it has
+// expired. The page works without this script; the expiry time is printed.
+(function () {
+  "use strict";
+
+  var countdown = document.getElementById("countdown");
+  var form = document.getElementById("checkout");
+  if (!countdown || !form) {
+    return;
+  }
+
+  var expiresAt = Date.parse(countdown.getAttribute("data-expires-at"));
+  if (isNaN(expiresAt)) {
+    return;
+  }
+  var button = form.querySelector("button[type=submit]");
+
+  function pad(n) {
+    return n < 10 ? "0" + n : String(n);
+  }
+
+  function tick() {
+    var left = Math.floor((expiresAt - Date.now()) / 1000);
+    if (left <= 0) {
+      countdown.textContent = countdown.getAttribute("data-expired");
+      if (button) {
+        button.disabled = true;
+      }
+      return false;
+    }
+    var hours = Math.floor(left / 3600);
+    var minutes = Math.floor((left % 3600) / 60);
+    var seconds = left % 60;
+    countdown.textContent =
+      countdown.getAttribute("data-label") +
+      " " +
+      (hours > 0 ? hours + ":" + pad(minutes) : minutes) +
+      ":" +
+      pad(seconds);
+    return true;
+  }
+
+  countdown.hidden = false;
+  if (tick()) {
+    var timer = setInterval(function () {
+      if (!tick()) {
+        clearInterval(timer);
+      }
+    }, 1000);
+  }
+
+  form.addEventListener("submit", function (event) {
+    if (Date.now() >= expiresAt) {
+      event.preventDefault();
+      return;
+    }
+    // Guard against double submits while the bank page loads.
+    if (button) {
+      button.disabled = true;
+    }
+  });
+})();
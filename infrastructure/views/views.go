@@ -0,0 +1,23 @@
+// Package views embeds the HTML templates and the static assets they use, so
+// the binary serves its pages without files next to it or third-party CDNs.
+package views
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.html static
+var Files embed.FS
+
+// Static returns the assets under static/, served at StaticPrefix.
+func Static() fs.FS {
+	static, err := fs.Sub(Files, "static")
+	if err != nil {
+		panic(err)
+	}
+	return static
+}
+
+// StaticPrefix is the URL path the static assets are served under.
+const StaticPrefix = "/static/checkout"
@@ -2,10 +2,14 @@ package workers
 
 import (
 	"context"
-	"log"
+	"sync"
 	"time"
 
+	"payment-airpay/infrastructure/logger"
+	"payment-airpay/infrastructure/metrics"
+
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 )
 
 type JobResult struct {
@@ -24,23 +28,69 @@ const (
 
 type Worker struct {
 	queue   chan map[string]interface{}
+	mu      sync.RWMutex
 	results map[string]*JobResult
-}
+	log     *zap.Logger
 
-var workerInstance *Worker
+	// stopping guards queue against sends after Stop has closed it.
+	stopMu   sync.RWMutex
+	stopping bool
+	done     chan struct{}
+}
 
-func InitializePaymentAcledaTaskWorker() {
-	workerInstance = &Worker{
-		queue:   make(chan map[string]interface{}, 100),
+// NewPaymentAcledaTaskWorker builds a worker with a job buffer of the given size.
+// Call Start to begin processing.
+func NewPaymentAcledaTaskWorker(size int, log *zap.Logger) *Worker {
+	return &Worker{
+		queue:   make(chan map[string]interface{}, size),
 		results: make(map[string]*JobResult),
+		log:     logger.Component(log, "payment-worker"),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start launches the worker goroutine.
+func (w *Worker) Start() {
+	go w.processQueue()
+}
+
+// Stop refuses new jobs and waits until the queued ones are processed or ctx
+// is done. It must be called after Start.
+func (w *Worker) Stop(ctx context.Context) error {
+	w.stopMu.Lock()
+	if !w.stopping {
+		w.stopping = true
+		close(w.queue)
 	}
+	w.stopMu.Unlock()
 
-	// Start the worker goroutine
-	go workerInstance.processQueue()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.log.Warn("Stopped before the queue was drained", zap.Int("remaining", len(w.queue)))
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) setResult(result *JobResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.results[result.ID] = result
+}
+
+func (w *Worker) getResult(jobID string) (*JobResult, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	result, ok := w.results[jobID]
+	return result, ok
 }
 
 func (w *Worker) processQueue() {
+	defer close(w.done)
 	for payload := range w.queue {
+		metrics.SetWorkerQueueDepth(len(w.queue))
+		start := time.Now()
 		ctx := context.Background()
 		_ = ctx
 
@@ -50,17 +100,18 @@ func (w *Worker) processQueue() {
 		}
 
 		// Placeholder: implement Acleda payment processing here
-		w.results[jobID] = &JobResult{
+		w.setResult(&JobResult{
 			ID:      jobID,
 			Status:  StatusDone,
 			Message: "Acleda payment processed successfully",
 			Data:    payload,
-		}
-		log.Printf("Job %s completed", jobID)
+		})
+		metrics.ObserveWorkerJob(StatusDone, time.Since(start))
+		w.log.Info("Job completed", zap.String("job_id", jobID))
 	}
 }
 
-func PaymentHandler(c *fiber.Ctx) error {
+func (w *Worker) PaymentHandler(c *fiber.Ctx) error {
 	// Placeholder for Acleda payment processing
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "success",
@@ -68,10 +119,7 @@ func PaymentHandler(c *fiber.Ctx) error {
 	})
 }
 
-func EnqueueHandler(c *fiber.Ctx) error {
-	if workerInstance == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "worker not initialized"})
-	}
+func (w *Worker) EnqueueHandler(c *fiber.Ctx) error {
 
 	// Parse payload from request
 	var payload map[string]interface{}
@@ -82,25 +130,29 @@ func EnqueueHandler(c *fiber.Ctx) error {
 	jobID := generateJobID()
 	payload["job_id"] = jobID
 
-	workerInstance.results[jobID] = &JobResult{ID: jobID, Status: StatusQueued, Message: "Job queued"}
+	w.stopMu.RLock()
+	defer w.stopMu.RUnlock()
+	if w.stopping {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "shutting down"})
+	}
+
+	w.setResult(&JobResult{ID: jobID, Status: StatusQueued, Message: "Job queued"})
 	select {
-	case workerInstance.queue <- payload:
+	case w.queue <- payload:
+		metrics.SetWorkerQueueDepth(len(w.queue))
 		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"job_id": jobID, "status": StatusQueued})
 	default:
-		workerInstance.results[jobID] = &JobResult{ID: jobID, Status: StatusError, Error: "queue full"}
+		w.setResult(&JobResult{ID: jobID, Status: StatusError, Error: "queue full"})
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "queue full"})
 	}
 }
 
-func StatusHandler(c *fiber.Ctx) error {
-	if workerInstance == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "worker not initialized"})
-	}
+func (w *Worker) StatusHandler(c *fiber.Ctx) error {
 	jobID := c.Query("id")
 	if jobID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Job ID is required"})
 	}
-	result, ok := workerInstance.results[jobID]
+	result, ok := w.getResult(jobID)
 	if !ok {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Job not found"})
 	}
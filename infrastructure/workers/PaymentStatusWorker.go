@@ -0,0 +1,84 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/application/services"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+// PaymentStatusWorker periodically expires pending payment links past their
+// expiry and polls the bank for the status of the others.
+type PaymentStatusWorker struct {
+	service  *services.UpdateAcledaPaymentStatusService
+	interval time.Duration
+	log      *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPaymentStatusWorker(service *services.UpdateAcledaPaymentStatusService, interval time.Duration, log *zap.Logger) *PaymentStatusWorker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &PaymentStatusWorker{
+		service:  service,
+		interval: interval,
+		log:      logger.Component(log, "payment-status-worker"),
+	}
+}
+
+// Start launches the polling goroutine.
+func (w *PaymentStatusWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			w.run(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop cancels the running pass and waits for it to end. Every link is
+// updated in its own transaction, so an interrupted pass loses nothing.
+// It returns early when ctx is done.
+func (w *PaymentStatusWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *PaymentStatusWorker) run(ctx context.Context) {
+	out, err := w.service.Execute(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.log.Error("Failed to update payment statuses", zap.Error(err))
+		}
+		return
+	}
+	if out.Changed > 0 || out.Expired > 0 {
+		w.log.Info("Updated payment statuses", zap.Int("polled", out.Polled), zap.Int("changed", out.Changed), zap.Int("expired", out.Expired))
+	}
+}
@@ -0,0 +1,84 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/application/services"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+// SessionReconciliationWorker periodically attaches or flags Acleda sessions
+// that have no payment link.
+type SessionReconciliationWorker struct {
+	service  *services.ReconcileAcledaSessionsService
+	interval time.Duration
+	log      *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSessionReconciliationWorker(service *services.ReconcileAcledaSessionsService, interval time.Duration, log *zap.Logger) *SessionReconciliationWorker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &SessionReconciliationWorker{
+		service:  service,
+		interval: interval,
+		log:      logger.Component(log, "session-worker"),
+	}
+}
+
+// Start launches the reconciliation goroutine.
+func (w *SessionReconciliationWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			w.run(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop cancels the running pass and waits for it to end. Every session is
+// reconciled in its own transaction, so an interrupted pass loses nothing.
+// It returns early when ctx is done.
+func (w *SessionReconciliationWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *SessionReconciliationWorker) run(ctx context.Context) {
+	out, err := w.service.Execute(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.log.Error("Failed to reconcile Acleda sessions", zap.Error(err))
+		}
+		return
+	}
+	if out.Attached > 0 || out.Orphaned > 0 {
+		w.log.Info("Reconciled Acleda sessions", zap.Int("attached", out.Attached), zap.Int("orphaned", out.Orphaned))
+	}
+}
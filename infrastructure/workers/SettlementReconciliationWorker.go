@@ -0,0 +1,139 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"payment-airpay/application/services"
+	"payment-airpay/domain/entities"
+	"payment-airpay/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+// SettlementReconciliationWorker polls a directory for Acleda settlement files.
+// Each file is reconciled once and moved to processed/ or failed/.
+type SettlementReconciliationWorker struct {
+	service  *services.ReconcileAcledaSettlementService
+	dir      string
+	interval time.Duration
+	log      *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSettlementReconciliationWorker(service *services.ReconcileAcledaSettlementService, dir string, interval time.Duration, log *zap.Logger) *SettlementReconciliationWorker {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &SettlementReconciliationWorker{
+		service:  service,
+		dir:      dir,
+		interval: interval,
+		log:      logger.Component(log, "settlement-worker"),
+	}
+}
+
+// Start launches the polling goroutine. It is a no-op when no directory is configured.
+func (w *SettlementReconciliationWorker) Start() {
+	if w.dir == "" {
+		return
+	}
+	for _, sub := range []string{"processed", "failed"} {
+		if err := os.MkdirAll(filepath.Join(w.dir, sub), 0o755); err != nil {
+			w.log.Error("Cannot prepare settlement directory", zap.String("dir", filepath.Join(w.dir, sub)), zap.Error(err))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			w.scan(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop lets the file being reconciled finish, then stops polling. It returns
+// early when ctx is done.
+func (w *SettlementReconciliationWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *SettlementReconciliationWorker) scan(ctx context.Context) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		w.log.Error("Cannot read settlement directory", zap.String("dir", w.dir), zap.Error(err))
+		return
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		w.processFile(context.WithoutCancel(ctx), name)
+	}
+}
+
+func (w *SettlementReconciliationWorker) processFile(ctx context.Context, name string) {
+	path := filepath.Join(w.dir, name)
+	file, err := os.Open(path)
+	if err != nil {
+		w.log.Error("Cannot open settlement file", zap.String("file", path), zap.Error(err))
+		return
+	}
+
+	_, err = w.service.Execute(ctx, services.ReconcileAcledaSettlementInput{
+		FileName: name,
+		Source:   services.ReconciliationSourceDirectory,
+		Actor:    "system",
+		Content:  file,
+	})
+	file.Close()
+
+	target := "processed"
+	if err != nil && !errors.Is(err, entities.ErrSettlementAlreadyIngested) {
+		w.log.Error("Failed to reconcile settlement file", zap.String("file", name), zap.Error(err))
+		target = "failed"
+	}
+
+	if err := os.Rename(path, filepath.Join(w.dir, target, name)); err != nil {
+		w.log.Error("Cannot move settlement file", zap.String("file", name), zap.String("target", target), zap.Error(err))
+	}
+}
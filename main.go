@@ -1,105 +1,112 @@
 package main
 
 import (
-	"log"
+	"context"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"payment-airpay/infrastructure/configuration"
-	"payment-airpay/infrastructure/controllers"
+	"payment-airpay/infrastructure/crypto"
 	"payment-airpay/infrastructure/database"
-	"payment-airpay/infrastructure/database/clients"
-	"payment-airpay/infrastructure/database/repositories"
 	"payment-airpay/infrastructure/dependencies"
-	"payment-airpay/infrastructure/middleware"
+	"payment-airpay/infrastructure/metrics"
 	"payment-airpay/infrastructure/publishers"
 	"payment-airpay/infrastructure/queue"
-	"payment-airpay/infrastructure/workers"
+	"payment-airpay/infrastructure/tracing"
 
-	"github.com/go-resty/resty/v2"
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/template/html/v2"
+	"go.uber.org/zap"
 )
 
 func main() {
+	// Initialize configurations. The configured logger does not exist yet, so
+	// a broken configuration is reported by a plain one.
+	if err := configuration.InitializeAppConfig(); err != nil {
+		bootstrap, _ := zap.NewProduction()
+		bootstrap.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	// The logger depends on the configuration and is installed as the zap
+	// global, so everything after this point logs through it.
+	log := dependencies.ProvideLogger().With(zap.String("component", "main"))
+	defer log.Sync()
+
+	log.Info("Acleda Worker is starting...")
+	log.Info("Effective configuration", zap.Any("config", configuration.AppConfig.Redacted()))
+
+	shutdownTracing, err := tracing.Init(context.Background(), configuration.AppConfig)
+	if err != nil {
+		log.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
 	defer func() {
-		queue.CloseRabbitMQ()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Warn("Failed to flush traces", zap.Error(err))
+		}
 	}()
 
-	log.Println("Acleda Worker is starting...")
+	crypto.InitializeKeyRing()
+	crypto.InitializeLegacyCipher()
+	crypto.InitializeURLSigner()
 
-	// Initialize configurations
-	log.Println("Initializing configuration...")
-	configuration.InitializeAppConfig()
-	log.Println("Configuration initialized")
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:])
+		return
+	}
 
-	log.Println("Initializing YugabyteDB...")
+	log.Info("Initializing YugabyteDB...")
 	database.InitializeYugabyteDB()
-	log.Println("YugabyteDB initialized")
+	log.Info("YugabyteDB initialized")
 
 	// Initialize RabbitMQ
-	log.Println("Initializing RabbitMQ...")
+	log.Info("Initializing RabbitMQ...")
 	queue.InitializeRabbitMQ()
-	log.Println("RabbitMQ initialized")
+	log.Info("RabbitMQ initialized")
 
 	// Initialize Redis for publishing
-	log.Println("Initializing publishers...")
+	log.Info("Initializing publishers...")
 	publishers.InitializeRedis()
-	log.Println("Publishers initialized")
+	log.Info("Publishers initialized")
+
+	if err := metrics.RegisterPaymentLinkCounter(dependencies.ProvidePaymentAcledaRepository()); err != nil {
+		log.Warn("Failed to register payment link metrics", zap.Error(err))
+	}
 
 	// Initialize worker
-	log.Println("Initializing worker...")
-	workers.InitializePaymentAcledaTaskWorker()
-	log.Println("Worker initialized")
-
-	// Initialize fiber app with HTML template engine
-	engine := html.New("./infrastructure/views", ".html")
-	app := fiber.New(fiber.Config{
-		Views: engine,
-	})
-
-	// Initialize Yugabyte client wrapper
-	yugabyteClient := clients.NewYugabyteClient(database.YugabyteDBClient)
-
-	// Initialize services
-	acledaService := dependencies.ProvidePaymentAcledaService()
-	stagingService := dependencies.ProvideAcledaStagingService()
-
-	acledaController := controllers.NewAcledaController(
-		dependencies.ProvideAcledaGateway(),
-		acledaService,
-		repositories.NewPaymentAcledaRepositoryYugabyteDB(yugabyteClient),
-		resty.New().SetTimeout(60*time.Second),
-	)
-
-	// Initialize Acleda Staging controller
-	acledaStagingController := controllers.NewAcledaStagingController(
-		stagingService,
-	)
-
-	m := middleware.Middlewares{}
-	app.Use(m.Incoming())
-
-	// Register routes
-	app.Post("/payment/acleda", workers.PaymentHandler)
-	app.Post("/payment/acleda/async", workers.EnqueueHandler)
-	app.Get("/jobs/status", workers.StatusHandler)
-
-	// Setup Acleda controller routes
-	app.Post("/api/v1/acleda/payment-links", acledaController.CreatePaymentLink)
-	app.Get("/payment-page/acleda/:id", acledaController.PaymentPage)
-	app.Get("/api/v1/acleda/payments/:id/status", acledaController.GetPaymentStatus)
-
-	// Setup Acleda Staging controller routes
-	app.Post("/api/v2/payment/acleda", acledaStagingController.CreateStagingPayment)
-	app.Get("/api/v2/payment/acleda/:id/status", acledaStagingController.GetStagingPaymentStatus)
+	log.Info("Initializing worker...")
+	worker := dependencies.ProvidePaymentAcledaTaskWorker()
+	worker.Start()
+	settlement := dependencies.ProvideSettlementReconciliationWorker()
+	settlement.Start()
+	sessions := dependencies.ProvideSessionReconciliationWorker()
+	sessions.Start()
+	statuses := dependencies.ProvidePaymentStatusWorker()
+	statuses.Start()
+	log.Info("Worker initialized")
+
+	app := dependencies.ProvideServer()
 
 	// Start server
 	port := strconv.Itoa(configuration.AppConfig.ApplicationPort)
-	if port == "0" || port == "" {
-		port = "8080" // default port
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Listen(":" + port)
+	}()
+	log.Info("Server started", zap.String("port", port))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-signals:
+		log.Info("Received signal", zap.Stringer("signal", sig))
+	case err := <-serverErr:
+		log.Error("Server stopped", zap.Error(err))
 	}
 
-	log.Printf("Server started on port %s", port)
-	log.Fatal(app.Listen(":" + port))
+	shutdown(log, time.Duration(configuration.AppConfig.ShutdownTimeout)*time.Second, app, worker, settlement, sessions, statuses)
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"payment-airpay/infrastructure/background"
+	"payment-airpay/infrastructure/database"
+	"payment-airpay/infrastructure/publishers"
+	"payment-airpay/infrastructure/queue"
+	"payment-airpay/infrastructure/workers"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdown stops the service in dependency order: first nothing new comes
+// in, then everything already accepted is finished, and only then are the
+// connections it needs closed. Draining shares one deadline; the
+// connections are closed even when it runs out.
+func shutdown(log *zap.Logger, timeout time.Duration, app *fiber.App, worker *workers.Worker, settlement *workers.SettlementReconciliationWorker, sessions *workers.SessionReconciliationWorker, statuses *workers.PaymentStatusWorker) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Info("Shutting down", zap.Duration("timeout", timeout))
+
+	// Stop accepting connections and wait for in-flight requests.
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.Warn("HTTP server did not drain", zap.Error(err))
+	}
+
+	// Finish queued jobs, the settlement file and the session in progress.
+	if err := worker.Stop(ctx); err != nil {
+		log.Warn("Payment worker did not drain", zap.Error(err))
+	}
+	if err := settlement.Stop(ctx); err != nil {
+		log.Warn("Settlement worker did not stop", zap.Error(err))
+	}
+	if err := sessions.Stop(ctx); err != nil {
+		log.Warn("Session reconciliation worker did not stop", zap.Error(err))
+	}
+	if err := statuses.Stop(ctx); err != nil {
+		log.Warn("Payment status worker did not stop", zap.Error(err))
+	}
+
+	// Let pending API call and request log writes complete.
+	if err := background.Wait(ctx); err != nil {
+		log.Warn("Pending log writes were abandoned", zap.Error(err))
+	}
+
+	if err := database.CloseYugabyteDB(); err != nil {
+		log.Warn("Failed to close YugabyteDB", zap.Error(err))
+	}
+	queue.CloseRabbitMQ()
+	publishers.CloseRedis()
+
+	log.Info("Shutdown complete")
+}